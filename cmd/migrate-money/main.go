@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"go-json/constant"
+	"go-json/internal/models"
+	"go-json/utils"
+	"log"
+	"math/big"
+	"os"
+)
+
+// Ubah semua field "balance" dan "amount" yang masih berupa angka float menjadi
+// format Money. Nilai yang bergeser karena float (mis. 997999.9999999) dibulatkan
+// ke satuan terkecil DefaultCurrency. Jalankan sekali dari root project:
+// go run ./cmd/migrate-money
+func main() {
+	files := []string{constant.USER_FILE, constant.TRANSACTION_FILE, constant.JOURNAL_ENTRY_FILE}
+	for _, file := range files {
+		if err := migrateFile(file); err != nil {
+			log.Fatalf("Failed to migrate %s: %v", file, err)
+		}
+	}
+}
+
+func migrateFile(file string) error {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(f)
+	decoder.UseNumber()
+	var data interface{}
+	err = decoder.Decode(&data)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	converted, rounded, err := migrateValue(data)
+	if err != nil {
+		return err
+	}
+	if converted == 0 {
+		log.Printf("%s: nothing to migrate", file)
+		return nil
+	}
+
+	if err := utils.WriteJSONFile(file, data); err != nil {
+		return err
+	}
+	log.Printf("%s: converted %d amounts, %d of them rounded", file, converted, rounded)
+	return nil
+}
+
+func migrateValue(value interface{}) (converted, rounded int, err error) {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			c, r, err := migrateValue(item)
+			if err != nil {
+				return 0, 0, err
+			}
+			converted, rounded = converted+c, rounded+r
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			if number, ok := item.(json.Number); ok && (key == "balance" || key == "amount") {
+				money, exact, err := roundToMinorUnits(number)
+				if err != nil {
+					return 0, 0, err
+				}
+				v[key] = money
+				converted++
+				if !exact {
+					log.Printf("Rounded %s %s to %s", key, number, money)
+					rounded++
+				}
+				continue
+			}
+			c, r, err := migrateValue(item)
+			if err != nil {
+				return 0, 0, err
+			}
+			converted, rounded = converted+c, rounded+r
+		}
+	}
+	return converted, rounded, nil
+}
+
+// roundToMinorUnits membulatkan setengah menjauhi nol
+func roundToMinorUnits(number json.Number) (models.Money, bool, error) {
+	exponent, err := models.CurrencyExponent(models.DefaultCurrency)
+	if err != nil {
+		return models.Money{}, false, err
+	}
+
+	value, ok := new(big.Rat).SetString(number.String())
+	if !ok {
+		return models.Money{}, false, models.ErrInvalidAmount
+	}
+	value.Mul(value, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)))
+	if value.IsInt() {
+		return models.NewMoney(value.Num().Int64(), models.DefaultCurrency), true, nil
+	}
+
+	half := big.NewRat(1, 2)
+	if value.Sign() < 0 {
+		value.Sub(value, half)
+	} else {
+		value.Add(value, half)
+	}
+	units := new(big.Int).Quo(value.Num(), value.Denom())
+	if !units.IsInt64() {
+		return models.Money{}, false, models.ErrAmountOverflow
+	}
+	return models.NewMoney(units.Int64(), models.DefaultCurrency), false, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"go-json/constant"
+	"go-json/internal/models"
+	"go-json/internal/security"
+	"go-json/utils"
+	"log"
+
+	"github.com/joho/godotenv"
+)
+
+// Hash semua password yang masih tersimpan sebagai plaintext di users.json.
+// Jalankan sekali dari root project: go run ./cmd/migrate-passwords
+func main() {
+	_ = godotenv.Load()
+	hasher := security.NewPasswordHasherFromEnv()
+
+	var users []models.User
+	if err := utils.ReadJSONFile(constant.USER_FILE, &users); err != nil {
+		log.Fatalf("Failed to read %s: %v", constant.USER_FILE, err)
+	}
+
+	migrated := 0
+	for i, user := range users {
+		if hasher.IsHashed(user.Password) {
+			continue
+		}
+		hashedPassword, err := hasher.Hash(user.Password)
+		if err != nil {
+			log.Fatalf("Failed to hash password for user %s: %v", user.ID, err)
+		}
+		users[i].Password = hashedPassword
+		migrated++
+	}
+
+	if migrated == 0 {
+		log.Println("No plaintext passwords found")
+		return
+	}
+
+	if err := utils.WriteJSONFile(constant.USER_FILE, users); err != nil {
+		log.Fatalf("Failed to write %s: %v", constant.USER_FILE, err)
+	}
+	log.Printf("Migrated %d plaintext passwords", migrated)
+}
@@ -0,0 +1,106 @@
+package bankrails
+
+import (
+	"errors"
+	"go-json/internal/models"
+	"go-json/utils"
+	"log"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+)
+
+var ErrInvalidInstruction = errors.New("bank instruction needs an ID, a positive amount and a bank account")
+
+type Direction string
+
+const (
+	// Deposit menarik dana dari rekening bank user ke wallet-nya
+	Deposit Direction = "DEPOSIT"
+	// Withdrawal mengirim dana dari wallet user ke rekening bank-nya
+	Withdrawal Direction = "WITHDRAWAL"
+)
+
+// Instruction adalah perintah transfer ke bank. ID sama dengan ID transaksi sehingga
+// instruksi yang dikirim ulang setelah restart dikenali bank sebagai instruksi yang sama.
+type Instruction struct {
+	ID        string
+	Direction Direction
+	Amount    models.Money
+	Account   models.SettlementAccount
+}
+
+type Confirmation struct {
+	InstructionID string
+	Reference     string
+	Succeeded     bool
+	Reason        string
+	ConfirmedAt   time.Time
+}
+
+// BankRail mengirim instruksi ke bank. Hasil akhirnya dikirim ke confirm, bisa sebelum Submit
+// kembali atau lama setelahnya. Error dari Submit berarti instruksi tidak diterima sama sekali.
+type BankRail interface {
+	Submit(instruction Instruction, confirm func(Confirmation)) error
+}
+
+// SimulatorConfig mengatur bank rail simulasi: berapa lama bank menunggu sebelum konfirmasi,
+// berapa bagian instruksi yang ditolak, dan apakah konfirmasi dikirim di background.
+type SimulatorConfig struct {
+	Latency     time.Duration
+	FailureRate float64
+	Async       bool
+}
+
+func SimulatorConfigFromEnv() SimulatorConfig {
+	return SimulatorConfig{
+		Latency:     utils.DurationFromEnv("BANK_RAIL_LATENCY", 2*time.Second),
+		FailureRate: utils.FloatFromEnv("BANK_RAIL_FAILURE_RATE", 0),
+		Async:       os.Getenv("BANK_RAIL_SYNC") != "true",
+	}
+}
+
+// simulator hanya untuk pengembangan lokal. Rekening yang nomornya berakhiran 0000 selalu
+// ditolak, sehingga alur gagal bisa dicoba tanpa mengandalkan FailureRate.
+type simulator struct {
+	config SimulatorConfig
+}
+
+func NewSimulator(config SimulatorConfig) BankRail {
+	return &simulator{config: config}
+}
+
+func (s *simulator) Submit(instruction Instruction, confirm func(Confirmation)) error {
+	if instruction.ID == "" || !instruction.Amount.IsPositive() || instruction.Account.AccountNumber == "" {
+		return ErrInvalidInstruction
+	}
+	if !s.config.Async {
+		confirm(s.settle(instruction))
+		return nil
+	}
+	go func() {
+		time.Sleep(s.config.Latency)
+		confirm(s.settle(instruction))
+	}()
+	return nil
+}
+
+func (s *simulator) settle(instruction Instruction) Confirmation {
+	confirmation := Confirmation{
+		InstructionID: instruction.ID,
+		Reference:     "SIM-" + string(instruction.Direction) + "-" + instruction.ID,
+		Succeeded:     true,
+		ConfirmedAt:   time.Now(),
+	}
+	switch {
+	case strings.HasSuffix(instruction.Account.AccountNumber, "0000"):
+		confirmation.Succeeded = false
+		confirmation.Reason = "account is closed"
+	case rand.Float64() < s.config.FailureRate:
+		confirmation.Succeeded = false
+		confirmation.Reason = "rejected by bank"
+	}
+	log.Printf("Bank rail: %s %s %s succeeded=%t", instruction.Direction, instruction.ID, instruction.Amount, confirmation.Succeeded)
+	return confirmation
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+	"go-json/internal/services"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type AccountController struct {
+	accountService services.AccountService
+}
+
+func NewAccountController(accountService services.AccountService) AccountController {
+	return AccountController{accountService: accountService}
+}
+
+func (c *AccountController) ChangeStatus(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.AccountStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	status, err := c.accountService.ChangeStatus(principal, mux.Vars(r)["id"], request)
+	if err != nil {
+		http.Error(w, err.Error(), accountErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Account status changed",
+		Data:    status,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *AccountController) SetTransferLimits(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.TransferLimitsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limits, err := c.accountService.SetTransferLimits(principal, mux.Vars(r)["id"], request)
+	if err != nil {
+		http.Error(w, err.Error(), accountErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Transfer limits changed",
+		Data:    limits,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *AccountController) ResetTransferLimits(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	limits, err := c.accountService.ResetTransferLimits(principal, mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), accountErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Transfer limits reset to defaults",
+		Data:    limits,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func accountErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors), errors.Is(err, services.ErrUnknownReasonCode), errors.Is(err, models.ErrInvalidTransferLimits):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidStatusTransition), errors.Is(err, services.ErrAccountBalanceNotZero),
+		errors.Is(err, services.ErrAccountHasPendingFunds), errors.Is(err, services.ErrOwnAccountStatusChange):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
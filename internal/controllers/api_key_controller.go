@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/services"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type APIKeyController struct {
+	apiKeyService services.APIKeyService
+}
+
+func NewAPIKeyController(apiKeyService services.APIKeyService) APIKeyController {
+	return APIKeyController{apiKeyService: apiKeyService}
+}
+
+func (c *APIKeyController) CreateKey(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	key, err := c.apiKeyService.CreateKey(principal, request)
+	if err != nil {
+		http.Error(w, err.Error(), apiKeyErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "API key created, store it now because it will not be shown again",
+		Data:    key,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *APIKeyController) KeyList(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	keys, err := c.apiKeyService.ListKeys(principal)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "API keys retrieved",
+		Data:    keys,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *APIKeyController) UpdateLabel(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.UpdateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	key, err := c.apiKeyService.UpdateLabel(principal, mux.Vars(r)["id"], request)
+	if err != nil {
+		http.Error(w, err.Error(), apiKeyErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "API key updated",
+		Data:    key,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *APIKeyController) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	if err := c.apiKeyService.RevokeKey(principal, mux.Vars(r)["id"]); err != nil {
+		http.Error(w, err.Error(), apiKeyErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "API key revoked",
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func apiKeyErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors), errors.Is(err, services.ErrInvalidAPIKeyScope):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrAPIKeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrAPIKeyLimit), errors.Is(err, services.ErrAPIKeyRevoked):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
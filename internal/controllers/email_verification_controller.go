@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/services"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type EmailVerificationController struct {
+	verificationService services.EmailVerificationService
+}
+
+func NewEmailVerificationController(verificationService services.EmailVerificationService) EmailVerificationController {
+	return EmailVerificationController{verificationService: verificationService}
+}
+
+func (c *EmailVerificationController) Verify(w http.ResponseWriter, r *http.Request) {
+	var request request.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := c.verificationService.Verify(request); err != nil {
+		http.Error(w, err.Error(), verificationErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Email verified",
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *EmailVerificationController) Resend(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	err := c.verificationService.Resend(principal.UserID)
+	var tooSoon *services.VerificationResendError
+	if errors.As(err, &tooSoon) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(tooSoon.RetryAfter.Seconds()))))
+	}
+	if err != nil {
+		http.Error(w, err.Error(), verificationErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusAccepted,
+		Message: "Verification email sent",
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func verificationErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors), errors.Is(err, services.ErrInvalidVerificationToken):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrEmailAlreadyVerified):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrVerificationResendLimit):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"errors"
+	"go-json/internal/dtos/response"
+	"go-json/internal/services"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type LockoutController struct {
+	lockoutService services.LockoutService
+}
+
+func NewLockoutController(lockoutService services.LockoutService) LockoutController {
+	return LockoutController{lockoutService: lockoutService}
+}
+
+func (c *LockoutController) LockoutList(w http.ResponseWriter, r *http.Request) {
+	lockouts, err := c.lockoutService.FindAllLockouts()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Login lockouts retrieved",
+		Data:    lockouts,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *LockoutController) UserLockout(w http.ResponseWriter, r *http.Request) {
+	lockout, err := c.lockoutService.UserLockout(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), lockoutErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Login lockout status retrieved",
+		Data:    lockout,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *LockoutController) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	if err := c.lockoutService.UnlockUser(principal, mux.Vars(r)["id"]); err != nil {
+		http.Error(w, err.Error(), lockoutErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "User unlocked",
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *LockoutController) UnlockIP(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	if err := c.lockoutService.UnlockIP(principal, mux.Vars(r)["ip"]); err != nil {
+		http.Error(w, err.Error(), lockoutErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "IP address unlocked",
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func lockoutErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidIP):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+	"go-json/internal/services"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type MerchantController struct {
+	merchantService services.MerchantService
+}
+
+func NewMerchantController(merchantService services.MerchantService) MerchantController {
+	return MerchantController{merchantService: merchantService}
+}
+
+func (c *MerchantController) Onboard(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.MerchantRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	merchant, err := c.merchantService.Onboard(principal, request)
+	if err != nil {
+		http.Error(w, err.Error(), merchantErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "Merchant submitted for review",
+		Data:    merchant,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *MerchantController) OwnMerchantList(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	merchants, err := c.merchantService.FindOwnMerchants(principal)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Merchants retrieved",
+		Data:    merchants,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *MerchantController) Merchant(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	merchant, err := c.merchantService.FindMerchant(principal, mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), merchantErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Merchant retrieved",
+		Data:    merchant,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *MerchantController) UpdateMerchant(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.MerchantRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	merchant, err := c.merchantService.UpdateMerchant(principal, mux.Vars(r)["id"], request)
+	if err != nil {
+		http.Error(w, err.Error(), merchantErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Merchant updated",
+		Data:    merchant,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *MerchantController) MerchantList(w http.ResponseWriter, r *http.Request) {
+	status := models.MerchantStatus(strings.ToUpper(r.URL.Query().Get("status")))
+	merchants, err := c.merchantService.FindAllMerchants(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Merchants retrieved",
+		Data:    merchants,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *MerchantController) ChangeStatus(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.MerchantStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	merchant, err := c.merchantService.ChangeStatus(principal, mux.Vars(r)["id"], request)
+	if err != nil {
+		http.Error(w, err.Error(), merchantErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Merchant status changed",
+		Data:    merchant,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *MerchantController) AddOwner(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.MerchantOwnerRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	merchant, err := c.merchantService.AddOwner(principal, mux.Vars(r)["id"], request)
+	if err != nil {
+		http.Error(w, err.Error(), merchantErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Merchant owner added",
+		Data:    merchant,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *MerchantController) RemoveOwner(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	merchant, err := c.merchantService.RemoveOwner(principal, vars["id"], vars["userId"])
+	if err != nil {
+		http.Error(w, err.Error(), merchantErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Merchant owner removed",
+		Data:    merchant,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func merchantErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors), errors.Is(err, services.ErrUnknownReasonCode):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrMerchantNotFound), errors.Is(err, services.ErrUserNotFound), errors.Is(err, services.ErrMerchantOwnerNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrMerchantExists), errors.Is(err, services.ErrInvalidMerchantTransition),
+		errors.Is(err, services.ErrMerchantOwnerExists), errors.Is(err, services.ErrWalletOwnerRequired):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/services"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type PasswordController struct {
+	passwordService services.PasswordService
+}
+
+func NewPasswordController(passwordService services.PasswordService) PasswordController {
+	return PasswordController{passwordService: passwordService}
+}
+
+func (c *PasswordController) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := c.passwordService.ChangePassword(principal, request, clientIP(r)); err != nil {
+		passwordError(w, err)
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Password changed, other sessions have been signed out",
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *PasswordController) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var request request.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := c.passwordService.RequestReset(request); err != nil {
+		passwordError(w, err)
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusAccepted,
+		Message: "If the email is registered, a password reset token has been sent",
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *PasswordController) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var request request.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := c.passwordService.ResetPassword(request); err != nil {
+		passwordError(w, err)
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Password has been reset, please log in again",
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+// passwordError memakai loginError untuk throttling, karena salah password lama dihitung sebagai gagal login
+func passwordError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrLoginThrottled) {
+		loginError(w, err)
+		return
+	}
+	http.Error(w, err.Error(), passwordErrorStatus(err))
+}
+
+func passwordErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors), errors.Is(err, services.ErrPasswordUnchanged), errors.Is(err, services.ErrInvalidResetToken):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrIncorrectPassword):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	"go-json/internal/security"
+	"net/http"
+)
+
+// requirePrincipal membaca user yang terautentikasi dari context request.
+// Route yang memakainya harus dibungkus middlewares.ProtectedHandler.
+func requirePrincipal(w http.ResponseWriter, r *http.Request) (security.Principal, bool) {
+	principal, ok := security.PrincipalFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	}
+	return principal, ok
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/services"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+type RoleController struct {
+	roleService services.RoleService
+}
+
+func NewRoleController(roleService services.RoleService) RoleController {
+	return RoleController{roleService: roleService}
+}
+
+func (c *RoleController) RoleList(w http.ResponseWriter, r *http.Request) {
+	roles, err := c.roleService.FindAllRoles()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Roles retrieved",
+		Data:    roles,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *RoleController) CreateRole(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.CreateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	role, err := c.roleService.CreateRole(principal, request)
+	if err != nil {
+		http.Error(w, err.Error(), roleErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "Role created",
+		Data:    role,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *RoleController) GrantPermissions(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.RolePermissionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	role, err := c.roleService.GrantPermissions(principal, mux.Vars(r)["id"], request)
+	if err != nil {
+		http.Error(w, err.Error(), roleErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Permissions granted",
+		Data:    role,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *RoleController) RevokePermission(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	role, err := c.roleService.RevokePermission(principal, vars["id"], vars["permission"])
+	if err != nil {
+		http.Error(w, err.Error(), roleErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Permission revoked",
+		Data:    role,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *RoleController) SetTwoFactorRequired(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.RoleTwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	role, err := c.roleService.SetTwoFactorRequired(principal, mux.Vars(r)["id"], request)
+	if err != nil {
+		http.Error(w, err.Error(), roleErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Two-factor policy updated",
+		Data:    role,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *RoleController) AssignRole(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.AssignRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	userRoles, err := c.roleService.AssignRole(principal, mux.Vars(r)["id"], request)
+	if err != nil {
+		http.Error(w, err.Error(), roleErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Role assigned",
+		Data:    userRoles,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *RoleController) UnassignRole(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	userRoles, err := c.roleService.UnassignRole(principal, vars["id"], vars["roleId"])
+	if err != nil {
+		http.Error(w, err.Error(), roleErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Role removed",
+		Data:    userRoles,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func roleErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors), errors.Is(err, services.ErrUnknownPermission):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrRoleNotFound), errors.Is(err, services.ErrUserNotFound), errors.Is(err, services.ErrRoleNotAssigned):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrRoleExists), errors.Is(err, services.ErrRoleAlreadyAssigned), errors.Is(err, services.ErrSelfLockout):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
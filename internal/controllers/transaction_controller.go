@@ -2,11 +2,14 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"go-json/internal/dtos/request"
 	"go-json/internal/dtos/response"
+	"go-json/internal/models"
 	"go-json/internal/services"
 	"net/http"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 )
 
@@ -19,14 +22,18 @@ func NewTransactionController(paymentService services.TransactionService) Transa
 }
 
 func (t *TransactionController) Payment(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
 	var request request.PaymentRequest
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	payment, err := t.paymentService.ProcessPayment(request)
+	payment, err := t.paymentService.ProcessPayment(principal.UserID, request)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), paymentErrorStatus(err))
 		return
 	}
 	apiRes := response.ApiResponse{
@@ -37,13 +44,330 @@ func (t *TransactionController) Payment(w http.ResponseWriter, r *http.Request)
 	response.CommonResponse(w, apiRes)
 }
 
+func (t *TransactionController) PaymentOnBehalf(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.PaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	payment, err := t.paymentService.ProcessPaymentOnBehalf(principal.UserID, request)
+	if err != nil {
+		http.Error(w, err.Error(), paymentErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Payment successful",
+		Data:    payment,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func paymentErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors), errors.Is(err, services.ErrPaymentAmount), errors.Is(err, models.ErrUnsupportedCurrency),
+		errors.Is(err, services.ErrInvalidCustomer), errors.Is(err, services.ErrSameParty):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrInsufficientBalance), errors.Is(err, services.ErrExchangeRateNotFound):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrCustomerMismatch), errors.Is(err, services.ErrAccountNotVerified),
+		errors.Is(err, services.ErrAccountSuspended), errors.Is(err, services.ErrAccountClosed),
+		errors.Is(err, services.ErrMerchantNotActive), errors.Is(err, services.ErrMerchantSuspended):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrCustomerRequired), errors.Is(err, services.ErrInvalidMerchant):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (t *TransactionController) Refund(w http.ResponseWriter, r *http.Request) {
+	transactionID := mux.Vars(r)["id"]
+	if transactionID == "" {
+		http.Error(w, "Transaction ID is required", http.StatusBadRequest)
+		return
+	}
+	var request request.RefundRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	refund, err := t.paymentService.RefundPayment(transactionID, principal.UserID, request)
+	if err != nil {
+		http.Error(w, err.Error(), refundErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Refund successful",
+		Data:    refund,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func refundErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors), errors.Is(err, models.ErrCurrencyMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrTransactionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrRefundNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrRefundExceedsPayment), errors.Is(err, services.ErrInsufficientBalance):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (t *TransactionController) Authorize(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.AuthorizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	authorization, err := t.paymentService.AuthorizePayment(principal.UserID, request)
+	if err != nil {
+		http.Error(w, err.Error(), authorizationErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "Payment authorized",
+		Data:    authorization,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (t *TransactionController) Capture(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.CaptureRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	capture, err := t.paymentService.CaptureAuthorization(mux.Vars(r)["id"], principal.UserID, request)
+	if err != nil {
+		http.Error(w, err.Error(), authorizationErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Authorization captured",
+		Data:    capture,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (t *TransactionController) Void(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.VoidRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	void, err := t.paymentService.VoidAuthorization(mux.Vars(r)["id"], principal.UserID, request)
+	if err != nil {
+		http.Error(w, err.Error(), authorizationErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Authorization voided",
+		Data:    void,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func authorizationErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors), errors.Is(err, services.ErrAuthorizationAmount), errors.Is(err, models.ErrCurrencyMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrAuthorizationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrCaptureNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrAuthorizationClosed), errors.Is(err, services.ErrAuthorizationExpired):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrCaptureExceedsHold), errors.Is(err, services.ErrInsufficientBalance):
+		return http.StatusUnprocessableEntity
+	default:
+		return paymentErrorStatus(err)
+	}
+}
+
+func (t *TransactionController) Deposit(w http.ResponseWriter, r *http.Request) {
+	t.bankTransfer(w, r, t.paymentService.Deposit, "Deposit")
+}
+
+func (t *TransactionController) Withdraw(w http.ResponseWriter, r *http.Request) {
+	t.bankTransfer(w, r, t.paymentService.Withdraw, "Withdrawal")
+}
+
+// bankTransfer menjawab 202 selama bank belum mengonfirmasi, dan 200 atau 422 bila bank rail sudah menjawab
+func (t *TransactionController) bankTransfer(w http.ResponseWriter, r *http.Request, transfer func(string, request.BankTransferRequest) (*response.BankTransferResponse, error), name string) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.BankTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := transfer(principal.UserID, request)
+	if err != nil {
+		http.Error(w, err.Error(), bankTransferErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusAccepted,
+		Message: name + " is waiting for bank confirmation",
+		Data:    result,
+	}
+	switch result.Status {
+	case models.TransactionSucceeded:
+		apiRes.Status = http.StatusOK
+		apiRes.Message = name + " confirmed by bank"
+	case models.TransactionFailed:
+		apiRes.Status = http.StatusUnprocessableEntity
+		apiRes.Message = name + " rejected by bank"
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func bankTransferErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors), errors.Is(err, services.ErrBankTransferAmount), errors.Is(err, models.ErrUnsupportedCurrency):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrInsufficientBalance):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrBankRailUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return paymentErrorStatus(err)
+	}
+}
+
+func (t *TransactionController) Transfer(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	transfer, err := t.paymentService.Transfer(principal.UserID, request)
+	if err != nil {
+		http.Error(w, err.Error(), transferErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Transfer successful",
+		Data:    transfer,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (t *TransactionController) FindRecipient(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	recipient, err := t.paymentService.FindRecipient(principal.UserID, r.URL.Query().Get("q"))
+	if err != nil {
+		http.Error(w, err.Error(), transferErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Recipient found",
+		Data:    recipient,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (t *TransactionController) TransferLimits(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	limits, err := t.paymentService.TransferLimits(principal.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), transferErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Transfer limits retrieved",
+		Data:    limits,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func transferErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors), errors.Is(err, services.ErrTransferAmount), errors.Is(err, models.ErrUnsupportedCurrency),
+		errors.Is(err, services.ErrTransferToSelf):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrRecipientNotFound), errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrRecipientNotActive), errors.Is(err, services.ErrInsufficientBalance),
+		errors.Is(err, services.ErrTransferLimitExceeded), errors.Is(err, services.ErrDailyTransferLimitExceeded),
+		errors.Is(err, services.ErrExchangeRateNotFound):
+		return http.StatusUnprocessableEntity
+	default:
+		return paymentErrorStatus(err)
+	}
+}
+
 func (t *TransactionController) TransactionHistory(w http.ResponseWriter, r *http.Request) {
 	customerID := mux.Vars(r)["id"]
 	if customerID == "" {
 		http.Error(w, "Customer ID is required", http.StatusBadRequest)
 		return
 	}
-	transactions, err := t.paymentService.TransactionHistoryByUserID(customerID)
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	transactions, err := t.paymentService.TransactionHistoryByUserID(principal, customerID)
+	if errors.Is(err, services.ErrHistoryAccessDenied) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
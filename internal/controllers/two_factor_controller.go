@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/services"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type TwoFactorController struct {
+	twoFactorService services.TwoFactorService
+}
+
+func NewTwoFactorController(twoFactorService services.TwoFactorService) TwoFactorController {
+	return TwoFactorController{twoFactorService: twoFactorService}
+}
+
+func (c *TwoFactorController) Enroll(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	enrollment, err := c.twoFactorService.Enroll(principal.UserID)
+	if err != nil {
+		http.Error(w, err.Error(), twoFactorErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Scan the otpauth URI and confirm with a code to enable two-factor authentication",
+		Data:    enrollment,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *TwoFactorController) Confirm(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validator.New().Struct(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := c.twoFactorService.Confirm(principal.UserID, request.Code); err != nil {
+		http.Error(w, err.Error(), twoFactorErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Two-factor authentication enabled",
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *TwoFactorController) Disable(w http.ResponseWriter, r *http.Request) {
+	principal, ok := requirePrincipal(w, r)
+	if !ok {
+		return
+	}
+	var request request.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validator.New().Struct(request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := c.twoFactorService.Disable(principal.UserID, request.Code); err != nil {
+		http.Error(w, err.Error(), twoFactorErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Two-factor authentication disabled",
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func twoFactorErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors), errors.Is(err, services.ErrTwoFactorNotEnrolled):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrInvalidTwoFactorCode), errors.Is(err, services.ErrInvalidChallenge):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrTwoFactorRequired):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrLoginThrottled):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
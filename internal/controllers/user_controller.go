@@ -2,10 +2,17 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"go-json/internal/dtos/request"
 	"go-json/internal/dtos/response"
+	"go-json/internal/security"
 	"go-json/internal/services"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
 )
 
 type UserController struct {
@@ -24,12 +31,12 @@ func (c *UserController) Register(w http.ResponseWriter, r *http.Request) {
 	}
 	user, err := c.userService.CreateUser(request)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), registerErrorStatus(err))
 		return
 	}
 	apiRes := response.ApiResponse{
 		Status:  http.StatusCreated,
-		Message: "Customer registered successfully",
+		Message: "Customer registered successfully, check your email to verify the account",
 		Data:    user,
 	}
 	response.CommonResponse(w, apiRes)
@@ -41,9 +48,32 @@ func (c *UserController) Login(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	token, err := c.userService.Login(request)
+	token, err := c.userService.Login(request, clientIP(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		loginError(w, err)
+		return
+	}
+	message := "Login successful"
+	if token.ChallengeToken != "" {
+		message = "Two-factor authentication required"
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: message,
+		Data:    token,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *UserController) VerifyLogin(w http.ResponseWriter, r *http.Request) {
+	var request request.VerifyLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	token, err := c.userService.VerifyLogin(request, clientIP(r))
+	if err != nil {
+		loginError(w, err)
 		return
 	}
 	apiRes := response.ApiResponse{
@@ -54,10 +84,29 @@ func (c *UserController) Login(w http.ResponseWriter, r *http.Request) {
 	response.CommonResponse(w, apiRes)
 }
 
+func (c *UserController) SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var request request.TwoFactorChallengeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	enrollment, err := c.userService.SetupTwoFactor(request)
+	if err != nil {
+		http.Error(w, err.Error(), twoFactorErrorStatus(err))
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Scan the otpauth URI, then send a code to /auth/login/verify",
+		Data:    enrollment,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
 func (c *UserController) Logout(w http.ResponseWriter, r *http.Request) {
-	token := r.Header.Get("Authorization")
-	if token == "" {
-		http.Error(w, "Authorization header required", http.StatusUnauthorized)
+	token, err := security.BearerToken(r.Header.Get("Authorization"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
 	logoutResponse, err := c.userService.Logout(token)
@@ -73,8 +122,51 @@ func (c *UserController) Logout(w http.ResponseWriter, r *http.Request) {
 	response.CommonResponse(w, apiRes)
 }
 
+func (c *UserController) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	token, err := security.BearerToken(r.Header.Get("Authorization"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	logoutResponse, err := c.userService.LogoutAll(token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: logoutResponse.Message,
+		Data:    logoutResponse,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
+func (c *UserController) Refresh(w http.ResponseWriter, r *http.Request) {
+	var request request.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.RefreshToken == "" {
+		http.Error(w, "Refresh token required", http.StatusBadRequest)
+		return
+	}
+	token, err := c.userService.Refresh(request.RefreshToken)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	apiRes := response.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Token refreshed",
+		Data:    token,
+	}
+	response.CommonResponse(w, apiRes)
+}
+
 func (c *UserController) UserList(w http.ResponseWriter, r *http.Request) {
-	customers, err := c.userService.FindAllUser()
+	includeClosed, _ := strconv.ParseBool(r.URL.Query().Get("include_closed"))
+	customers, err := c.userService.FindAllUser(includeClosed)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -86,3 +178,51 @@ func (c *UserController) UserList(w http.ResponseWriter, r *http.Request) {
 	}
 	response.CommonResponse(w, apiRes)
 }
+
+// loginError menulis response error login; throttling mendapat header Retry-After
+func loginError(w http.ResponseWriter, err error) {
+	var throttled *services.LoginThrottledError
+	if errors.As(err, &throttled) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	}
+	http.Error(w, err.Error(), loginErrorStatus(err))
+}
+
+func registerErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrRoleNotRegistrable):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func loginErrorStatus(err error) int {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors), errors.Is(err, services.ErrTwoFactorNotEnrolled):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrInvalidCredentials), errors.Is(err, services.ErrInvalidChallenge), errors.Is(err, services.ErrInvalidTwoFactorCode):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrAccountSuspended), errors.Is(err, services.ErrAccountClosed):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrLoginThrottled):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// clientIP mengambil host dari r.RemoteAddr, yang sudah diganti middlewares.RealIP bila di belakang proxy
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
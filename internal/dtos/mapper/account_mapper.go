@@ -0,0 +1,16 @@
+package mapper
+
+import (
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+)
+
+func UserModelToAccountStatusResponse(user models.User, previous models.AccountStatus) response.AccountStatusResponse {
+	return response.AccountStatusResponse{
+		UserID:          user.ID,
+		PreviousStatus:  previous,
+		Status:          user.AccountStatus(),
+		StatusReason:    user.StatusReason,
+		StatusChangedAt: user.StatusChangedAt,
+	}
+}
@@ -0,0 +1,25 @@
+package mapper
+
+import (
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+)
+
+func APIKeyModelToResponse(key models.APIKey) response.APIKeyResponse {
+	return response.APIKeyResponse{
+		ID:         key.ID,
+		Label:      key.Label,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+func APIKeyModelToCreatedResponse(key models.APIKey, plaintext string) response.APIKeyCreatedResponse {
+	return response.APIKeyCreatedResponse{
+		APIKeyResponse: APIKeyModelToResponse(key),
+		Key:            plaintext,
+	}
+}
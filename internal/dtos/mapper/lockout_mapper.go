@@ -0,0 +1,29 @@
+package mapper
+
+import (
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+	"math"
+	"time"
+)
+
+func LoginAttemptToLockoutResponse(attempt models.LoginAttempt, userID string, retryAt, now time.Time) response.LockoutResponse {
+	lockout := response.LockoutResponse{
+		Kind:     string(attempt.Kind),
+		Key:      attempt.Key,
+		UserID:   userID,
+		Failures: attempt.Failures,
+		Locked:   attempt.IsLockedAt(now),
+	}
+	if !attempt.LastFailureAt.IsZero() {
+		lastFailureAt := attempt.LastFailureAt
+		lockout.LastFailureAt = &lastFailureAt
+	}
+	if lockout.Locked {
+		lockout.LockedUntil = attempt.LockedUntil
+	}
+	if retryAt.After(now) {
+		lockout.RetryAfterSeconds = int64(math.Ceil(retryAt.Sub(now).Seconds()))
+	}
+	return lockout
+}
@@ -0,0 +1,35 @@
+package mapper
+
+import (
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+)
+
+func MerchantRequestToModel(request request.MerchantRequest) models.Merchant {
+	return models.Merchant{
+		LegalName:    request.LegalName,
+		DisplayName:  request.DisplayName,
+		CategoryCode: request.CategoryCode,
+		SettlementAccount: models.SettlementAccount{
+			BankCode:      request.SettlementAccount.BankCode,
+			AccountNumber: request.SettlementAccount.AccountNumber,
+			AccountHolder: request.SettlementAccount.AccountHolder,
+		},
+	}
+}
+
+func MerchantModelToResponse(merchant models.Merchant) response.MerchantResponse {
+	return response.MerchantResponse{
+		ID:                merchant.ID,
+		LegalName:         merchant.LegalName,
+		DisplayName:       merchant.DisplayName,
+		CategoryCode:      merchant.CategoryCode,
+		SettlementAccount: merchant.SettlementAccount,
+		Status:            merchant.Status,
+		StatusReason:      merchant.StatusReason,
+		OwnerIDs:          merchant.OwnerIDs,
+		CreatedAt:         merchant.CreatedAt,
+		UpdatedAt:         merchant.UpdatedAt,
+	}
+}
@@ -13,6 +13,61 @@ func TransactionModelToPaymentResponse(trx *models.Transaction) response.Payment
 		MerchantID:   trx.MerchantID,
 		ActivityType: string(trx.ActivityType),
 		Details:      trx.Details,
+		Status:       trx.Status,
 		Timestamp:    trx.Timestamp,
+		SourceAmount: trx.SourceAmount,
+		ExchangeRate: trx.ExchangeRate,
+		InitiatedBy:  trx.InitiatedBy,
+	}
+}
+
+func TransactionModelToRefundResponse(trx *models.Transaction, refunded, refundable models.Money) response.RefundResponse {
+	return response.RefundResponse{
+		ID:                    trx.ID,
+		OriginalTransactionID: trx.OriginalTransactionID,
+		CustomerID:            trx.CustomerID,
+		MerchantID:            trx.MerchantID,
+		ActivityType:          string(trx.ActivityType),
+		Timestamp:             trx.Timestamp,
+		Details:               trx.Details,
+		Status:                trx.Status,
+		Amount:                trx.Amount,
+		RefundedAmount:        refunded,
+		RefundableAmount:      refundable,
+		SourceAmount:          trx.SourceAmount,
+		ExchangeRate:          trx.ExchangeRate,
+	}
+}
+
+func TransactionModelToAuthorizationResponse(trx, authorization *models.Transaction, captured, held models.Money, status models.AuthorizationStatus) response.AuthorizationResponse {
+	return response.AuthorizationResponse{
+		ID:               trx.ID,
+		AuthorizationID:  authorization.ID,
+		CustomerID:       trx.CustomerID,
+		MerchantID:       trx.MerchantID,
+		ActivityType:     string(trx.ActivityType),
+		Timestamp:        trx.Timestamp,
+		Details:          trx.Details,
+		Amount:           trx.Amount,
+		AuthorizedAmount: authorization.Amount,
+		CapturedAmount:   captured,
+		HeldAmount:       held,
+		Status:           status,
+		ExpiresAt:        authorization.ExpiresAt,
+	}
+}
+
+func TransactionModelToBankTransferResponse(trx *models.Transaction) response.BankTransferResponse {
+	return response.BankTransferResponse{
+		ID:            trx.ID,
+		CustomerID:    trx.CustomerID,
+		ActivityType:  string(trx.ActivityType),
+		Timestamp:     trx.Timestamp,
+		Details:       trx.Details,
+		Status:        trx.Status,
+		FailureReason: trx.FailureReason,
+		Amount:        trx.Amount,
+		BankAccount:   trx.BankAccount,
+		BankReference: trx.BankReference,
 	}
 }
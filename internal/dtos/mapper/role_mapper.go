@@ -0,0 +1,21 @@
+package mapper
+
+import (
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+)
+
+func RoleModelToResponse(role models.Role) response.RoleResponse {
+	permissions := role.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return response.RoleResponse{
+		ID:               role.ID,
+		Name:             role.Name,
+		IsDefault:        role.IsDefault,
+		Registrable:      role.Registrable,
+		RequireTwoFactor: role.RequireTwoFactor,
+		Permissions:      permissions,
+	}
+}
@@ -0,0 +1,49 @@
+package mapper
+
+import (
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+	"strings"
+)
+
+func TransactionModelToTransferResponse(trx *models.Transaction, recipient models.User) response.TransferResponse {
+	return response.TransferResponse{
+		ID:                trx.ID,
+		SenderID:          trx.CustomerID,
+		RecipientID:       trx.RecipientID,
+		RecipientUsername: recipient.Username,
+		ActivityType:      string(trx.ActivityType),
+		Timestamp:         trx.Timestamp,
+		Details:           trx.Details,
+		Status:            trx.Status,
+		Amount:            trx.Amount,
+		Note:              trx.Note,
+	}
+}
+
+func UserModelToRecipientResponse(user models.User) response.RecipientResponse {
+	return response.RecipientResponse{
+		ID:       user.ID,
+		Username: user.Username,
+		Email:    maskEmail(user.Email),
+	}
+}
+
+func UserModelToTransferLimitsResponse(user models.User, defaults models.TransferLimits) response.TransferLimitsResponse {
+	limits := user.EffectiveTransferLimits(defaults)
+	return response.TransferLimitsResponse{
+		UserID:      user.ID,
+		PerTransfer: limits.PerTransfer,
+		Daily:       limits.Daily,
+		Custom:      user.TransferLimits != nil,
+	}
+}
+
+// maskEmail menyisakan huruf pertama dan domain, misalnya j***@example.com
+func maskEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" {
+		return "***"
+	}
+	return local[:1] + "***@" + domain
+}
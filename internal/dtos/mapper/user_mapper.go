@@ -4,6 +4,7 @@ import (
 	"go-json/internal/dtos/request"
 	"go-json/internal/dtos/response"
 	"go-json/internal/models"
+	"time"
 )
 
 func UserRequestToModel(request request.RegisterRequest) models.User {
@@ -16,11 +17,12 @@ func UserRequestToModel(request request.RegisterRequest) models.User {
 
 func UserModelToResponse(User models.User) response.RegisterResponse {
 	return response.RegisterResponse{
-		ID:       User.ID,
-		Username: User.Username,
-		Email:    User.Email,
-		Balance:  User.Balance,
-		IsActive: User.IsActive,
+		ID:            User.ID,
+		Username:      User.Username,
+		Email:         User.Email,
+		Balance:       User.Balance,
+		Status:        User.AccountStatus(),
+		EmailVerified: User.IsEmailVerified(),
 	}
 }
 
@@ -31,32 +33,69 @@ func LoginRequestToModel(request request.LoginRequest) models.User {
 	}
 }
 
-func UserModelToLoginResponse(token string) response.LoginResponse {
+func UserModelToLoginResponse(token, refreshToken string, expiresIn time.Duration) response.LoginResponse {
 	return response.LoginResponse{
-		Token: token,
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(expiresIn.Seconds()),
+	}
+}
+
+func ToLoginChallengeResponse(challengeToken, purpose string, expiresIn time.Duration) response.LoginResponse {
+	return response.LoginResponse{
+		ChallengeToken:   challengeToken,
+		ChallengePurpose: purpose,
+		ExpiresIn:        int64(expiresIn.Seconds()),
 	}
 }
 
 func ToLogoutResponse(token string) response.LogoutResponse {
 	return response.LogoutResponse{
-		Message: "Successfully logged out",
+		Message: "Logout successful",
 		Token:   token,
 	}
 }
 
-func ToRefreshResponse(token string) response.RefreshResponse {
+func ToLogoutAllResponse(token string) response.LogoutResponse {
+	return response.LogoutResponse{
+		Message: "Logout from all sessions successful",
+		Token:   token,
+	}
+}
+
+func ToRefreshResponse(token, refreshToken string, expiresIn time.Duration) response.RefreshResponse {
 	return response.RefreshResponse{
-		Token: token,
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(expiresIn.Seconds()),
 	}
 }
 
 func UserModelToUserResponse(user models.User, roles []string) response.UserResponse {
 	return response.UserResponse{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		Roles:         roles,
+		Balance:       user.Balance,
+		Held:          user.Held,
+		Status:        user.AccountStatus(),
+		EmailVerified: user.IsEmailVerified(),
+		LastLoginAt:   user.LastLoginAt,
+	}
+}
+
+func UserModelToHistoryUserResponse(user models.User, private bool) response.TransactionHistoryUserResponse {
+	historyUser := response.TransactionHistoryUserResponse{
 		ID:       user.ID,
 		Username: user.Username,
-		Email:    user.Email,
-		Roles:    roles,
-		Balance:  user.Balance,
-		IsActive: user.IsActive,
+		Status:   user.AccountStatus(),
+	}
+	if private {
+		balance := user.Balance
+		historyUser.Email = user.Email
+		historyUser.Balance = &balance
+		historyUser.Held = user.Held
 	}
+	return historyUser
 }
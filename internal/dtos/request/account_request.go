@@ -0,0 +1,15 @@
+package request
+
+import "go-json/internal/models"
+
+type AccountStatusRequest struct {
+	Status     models.AccountStatus     `json:"status" validate:"required,oneof=ACTIVE SUSPENDED CLOSED"`
+	ReasonCode models.AccountReasonCode `json:"reason_code" validate:"required"`
+	Note       string                   `json:"note" validate:"max=255"`
+}
+
+type TransferLimitsRequest struct {
+	PerTransfer models.Money `json:"per_transfer"`
+	Daily       models.Money `json:"daily"`
+	Note        string       `json:"note" validate:"max=255"`
+}
@@ -0,0 +1,10 @@
+package request
+
+type CreateAPIKeyRequest struct {
+	Label  string   `json:"label" validate:"required,max=64"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,required"`
+}
+
+type UpdateAPIKeyRequest struct {
+	Label string `json:"label" validate:"required,max=64"`
+}
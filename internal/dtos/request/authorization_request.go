@@ -0,0 +1,18 @@
+package request
+
+import "go-json/internal/models"
+
+// AuthorizationRequest menahan Amount di wallet customer yang terautentikasi untuk MerchantID
+type AuthorizationRequest struct {
+	MerchantID string       `json:"merchant_id" validate:"required"`
+	Amount     models.Money `json:"amount"`
+}
+
+// Amount kosong berarti capture seluruh sisa otorisasi
+type CaptureRequest struct {
+	Amount models.Money `json:"amount"`
+}
+
+type VoidRequest struct {
+	Reason string `json:"reason" validate:"max=255"`
+}
@@ -0,0 +1,9 @@
+package request
+
+import "go-json/internal/models"
+
+// BankTransferRequest dipakai untuk deposit dari dan withdrawal ke BankAccount milik user yang terautentikasi
+type BankTransferRequest struct {
+	Amount      models.Money             `json:"amount"`
+	BankAccount SettlementAccountRequest `json:"bank_account"`
+}
@@ -3,7 +3,7 @@ package request
 type RegisterRequest struct {
 	Username string   `json:"username" validate:"required"`
 	Email    string   `json:"email" validate:"required,email"`
-	Password string   `json:"password" validate:"required,min=8"`
+	Password string   `json:"password" validate:"required,min=8,password"`
 	Role     []string `json:"role"`
 }
 
@@ -11,3 +11,11 @@ type LoginRequest struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
 }
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
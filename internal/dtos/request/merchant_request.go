@@ -0,0 +1,28 @@
+package request
+
+import "go-json/internal/models"
+
+type SettlementAccountRequest struct {
+	BankCode      string `json:"bank_code" validate:"required,alphanum,max=11"`
+	AccountNumber string `json:"account_number" validate:"required,numeric,min=6,max=20"`
+	AccountHolder string `json:"account_holder" validate:"required,max=100"`
+}
+
+// MerchantRequest dipakai untuk onboarding dan update profil merchant.
+// CategoryCode adalah merchant category code (MCC) empat digit.
+type MerchantRequest struct {
+	LegalName         string                   `json:"legal_name" validate:"required,max=100"`
+	DisplayName       string                   `json:"display_name" validate:"required,max=50"`
+	CategoryCode      string                   `json:"category_code" validate:"required,len=4,numeric"`
+	SettlementAccount SettlementAccountRequest `json:"settlement_account"`
+}
+
+type MerchantStatusRequest struct {
+	Status     models.MerchantStatus    `json:"status" validate:"required,oneof=ACTIVE SUSPENDED"`
+	ReasonCode models.AccountReasonCode `json:"reason_code" validate:"required"`
+	Note       string                   `json:"note" validate:"max=255"`
+}
+
+type MerchantOwnerRequest struct {
+	UserID string `json:"user_id" validate:"required"`
+}
@@ -1,7 +1,12 @@
 package request
 
+import "go-json/internal/models"
+
+// SourceCurrency adalah wallet customer yang didebit; kosong berarti sama dengan mata uang Amount.
+// CustomerID hanya wajib untuk payment atas nama customer lain; selain itu diambil dari token.
 type PaymentRequest struct {
-	CustomerID string  `json:"customer_id" validate:"required"`
-	MerchantID string  `json:"merchant_id" validate:"required"`
-	Amount     float64 `json:"amount" validate:"required"`
+	CustomerID     string          `json:"customer_id,omitempty"`
+	MerchantID     string          `json:"merchant_id" validate:"required"`
+	Amount         models.Money    `json:"amount"`
+	SourceCurrency models.Currency `json:"source_currency,omitempty"`
 }
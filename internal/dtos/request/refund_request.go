@@ -0,0 +1,9 @@
+package request
+
+import "go-json/internal/models"
+
+// Amount kosong berarti refund penuh atas sisa yang belum direfund
+type RefundRequest struct {
+	Amount models.Money `json:"amount"`
+	Reason string       `json:"reason" validate:"max=255"`
+}
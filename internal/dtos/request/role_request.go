@@ -0,0 +1,19 @@
+package request
+
+type CreateRoleRequest struct {
+	Name             string   `json:"name" validate:"required,alphanum,max=32"`
+	Permissions      []string `json:"permissions" validate:"dive,required"`
+	RequireTwoFactor bool     `json:"require_two_factor"`
+}
+
+type RoleTwoFactorRequest struct {
+	Required *bool `json:"required" validate:"required"`
+}
+
+type RolePermissionsRequest struct {
+	Permissions []string `json:"permissions" validate:"required,min=1,dive,required"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
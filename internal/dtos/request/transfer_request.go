@@ -0,0 +1,10 @@
+package request
+
+import "go-json/internal/models"
+
+// Recipient boleh berisi username, email, atau ID user penerima. Pengirimnya selalu user yang terautentikasi.
+type TransferRequest struct {
+	Recipient string       `json:"recipient" validate:"required,max=254"`
+	Amount    models.Money `json:"amount"`
+	Note      string       `json:"note" validate:"max=140"`
+}
@@ -0,0 +1,14 @@
+package response
+
+import (
+	"go-json/internal/models"
+	"time"
+)
+
+type AccountStatusResponse struct {
+	UserID          string               `json:"user_id"`
+	PreviousStatus  models.AccountStatus `json:"previous_status"`
+	Status          models.AccountStatus `json:"status"`
+	StatusReason    string               `json:"status_reason"`
+	StatusChangedAt *time.Time           `json:"status_changed_at"`
+}
@@ -0,0 +1,24 @@
+package response
+
+import (
+	"go-json/internal/models"
+	"time"
+)
+
+// AuthorizationResponse berisi transaksi dari satu tahap (otorisasi, capture, atau void)
+// beserta keadaan otorisasinya setelah tahap itu
+type AuthorizationResponse struct {
+	ID               string                     `json:"id"`
+	AuthorizationID  string                     `json:"authorization_id"`
+	CustomerID       string                     `json:"customer_id"`
+	MerchantID       string                     `json:"merchant_id"`
+	ActivityType     string                     `json:"activity_type"`
+	Timestamp        time.Time                  `json:"timestamp"`
+	Details          string                     `json:"details"`
+	Amount           models.Money               `json:"amount"`
+	AuthorizedAmount models.Money               `json:"authorized_amount"`
+	CapturedAmount   models.Money               `json:"captured_amount"`
+	HeldAmount       models.Money               `json:"held_amount"`
+	Status           models.AuthorizationStatus `json:"status"`
+	ExpiresAt        *time.Time                 `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,20 @@
+package response
+
+import (
+	"go-json/internal/models"
+	"time"
+)
+
+// BankTransferResponse berstatus PENDING sampai bank rail mengonfirmasi transfernya
+type BankTransferResponse struct {
+	ID            string                    `json:"id"`
+	CustomerID    string                    `json:"customer_id"`
+	ActivityType  string                    `json:"activity_type"`
+	Timestamp     time.Time                 `json:"timestamp"`
+	Details       string                    `json:"details"`
+	Status        models.TransactionStatus  `json:"status"`
+	FailureReason models.FailureReason      `json:"failure_reason,omitempty"`
+	Amount        models.Money              `json:"amount"`
+	BankAccount   *models.SettlementAccount `json:"bank_account,omitempty"`
+	BankReference string                    `json:"bank_reference,omitempty"`
+}
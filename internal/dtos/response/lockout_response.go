@@ -0,0 +1,14 @@
+package response
+
+import "time"
+
+type LockoutResponse struct {
+	Kind              string     `json:"kind"`
+	Key               string     `json:"key"`
+	UserID            string     `json:"user_id,omitempty"`
+	Failures          int        `json:"failures"`
+	LastFailureAt     *time.Time `json:"last_failure_at,omitempty"`
+	Locked            bool       `json:"locked"`
+	LockedUntil       *time.Time `json:"locked_until,omitempty"`
+	RetryAfterSeconds int64      `json:"retry_after_seconds"`
+}
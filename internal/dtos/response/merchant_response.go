@@ -0,0 +1,19 @@
+package response
+
+import (
+	"go-json/internal/models"
+	"time"
+)
+
+type MerchantResponse struct {
+	ID                string                   `json:"id"`
+	LegalName         string                   `json:"legal_name"`
+	DisplayName       string                   `json:"display_name"`
+	CategoryCode      string                   `json:"category_code"`
+	SettlementAccount models.SettlementAccount `json:"settlement_account"`
+	Status            models.MerchantStatus    `json:"status"`
+	StatusReason      string                   `json:"status_reason,omitempty"`
+	OwnerIDs          []string                 `json:"owner_ids"`
+	CreatedAt         time.Time                `json:"created_at"`
+	UpdatedAt         time.Time                `json:"updated_at"`
+}
@@ -6,17 +6,32 @@ import (
 )
 
 type PaymentResponse struct {
-	ID           string    `json:"id"`
-	CustomerID   string    `json:"customer_id"`
-	ActivityType string    `json:"activity_type"`
-	Timestamp    time.Time `json:"timestamp"`
-	Details      string    `json:"details"`
-	Amount       float64   `json:"amount"`
-	MerchantID   string    `json:"merchant_id"`
+	ID           string                   `json:"id"`
+	CustomerID   string                   `json:"customer_id"`
+	ActivityType string                   `json:"activity_type"`
+	Timestamp    time.Time                `json:"timestamp"`
+	Details      string                   `json:"details"`
+	Status       models.TransactionStatus `json:"status,omitempty"`
+	Amount       models.Money             `json:"amount"`
+	MerchantID   string                   `json:"merchant_id"`
+	SourceAmount *models.Money            `json:"source_amount,omitempty"`
+	ExchangeRate *models.ExchangeRate     `json:"exchange_rate,omitempty"`
+	InitiatedBy  string                   `json:"initiated_by,omitempty"`
+}
+
+// Sengaja tidak memakai models.User agar password dan kredensial lain tidak pernah ikut terkirim.
+// Email dan saldo hanya diisi untuk pemilik riwayat dan admin.
+type TransactionHistoryUserResponse struct {
+	ID       string               `json:"id"`
+	Username string               `json:"username"`
+	Email    string               `json:"email,omitempty"`
+	Balance  *models.Money        `json:"balance,omitempty"`
+	Held     []models.Money       `json:"held,omitempty"`
+	Status   models.AccountStatus `json:"status"`
 }
 
 type UserTransactionHistoryResponse struct {
-	User         models.User           `json:"user,omitempty"`
-	Transactions []*models.Transaction `json:"transactions,omitempty"`
-	TotalCount   int                   `json:"total_count"`
+	User         TransactionHistoryUserResponse `json:"user"`
+	Transactions []*models.Transaction          `json:"transactions,omitempty"`
+	TotalCount   int                            `json:"total_count"`
 }
@@ -0,0 +1,22 @@
+package response
+
+import (
+	"go-json/internal/models"
+	"time"
+)
+
+type RefundResponse struct {
+	ID                    string                   `json:"id"`
+	OriginalTransactionID string                   `json:"original_transaction_id"`
+	CustomerID            string                   `json:"customer_id"`
+	MerchantID            string                   `json:"merchant_id"`
+	ActivityType          string                   `json:"activity_type"`
+	Timestamp             time.Time                `json:"timestamp"`
+	Details               string                   `json:"details"`
+	Status                models.TransactionStatus `json:"status,omitempty"`
+	Amount                models.Money             `json:"amount"`
+	RefundedAmount        models.Money             `json:"refunded_amount"`
+	RefundableAmount      models.Money             `json:"refundable_amount"`
+	SourceAmount          *models.Money            `json:"source_amount,omitempty"`
+	ExchangeRate          *models.ExchangeRate     `json:"exchange_rate,omitempty"`
+}
@@ -0,0 +1,15 @@
+package response
+
+type RoleResponse struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	IsDefault        bool     `json:"is_default"`
+	Registrable      bool     `json:"registrable"`
+	RequireTwoFactor bool     `json:"require_two_factor"`
+	Permissions      []string `json:"permissions"`
+}
+
+type UserRolesResponse struct {
+	UserID string   `json:"user_id"`
+	Roles  []string `json:"roles"`
+}
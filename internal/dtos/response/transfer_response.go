@@ -0,0 +1,36 @@
+package response
+
+import (
+	"go-json/internal/models"
+	"time"
+)
+
+type TransferResponse struct {
+	ID                string                   `json:"id"`
+	SenderID          string                   `json:"sender_id"`
+	RecipientID       string                   `json:"recipient_id"`
+	RecipientUsername string                   `json:"recipient_username"`
+	ActivityType      string                   `json:"activity_type"`
+	Timestamp         time.Time                `json:"timestamp"`
+	Details           string                   `json:"details"`
+	Status            models.TransactionStatus `json:"status"`
+	Amount            models.Money             `json:"amount"`
+	Note              string                   `json:"note,omitempty"`
+}
+
+// RecipientResponse ditampilkan ke pengirim sebelum transfer agar penerimanya bisa dipastikan; email disamarkan
+type RecipientResponse struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// DailyUsed dan DailyRemaining hanya diisi saat user melihat batasnya sendiri
+type TransferLimitsResponse struct {
+	UserID         string        `json:"user_id"`
+	PerTransfer    models.Money  `json:"per_transfer"`
+	Daily          models.Money  `json:"daily"`
+	Custom         bool          `json:"custom"`
+	DailyUsed      *models.Money `json:"daily_used,omitempty"`
+	DailyRemaining *models.Money `json:"daily_remaining,omitempty"`
+}
@@ -0,0 +1,8 @@
+package response
+
+// TwoFactorEnrollResponse hanya dikirim sekali; recovery code tidak bisa diambil lagi setelahnya
+type TwoFactorEnrollResponse struct {
+	Secret        string   `json:"secret"`
+	OTPAuthURI    string   `json:"otpauth_uri"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
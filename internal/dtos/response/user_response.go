@@ -1,15 +1,28 @@
 package response
 
+import (
+	"go-json/internal/models"
+	"time"
+)
+
 type RegisterResponse struct {
-	ID       string  `json:"id"`
-	Username string  `json:"username"`
-	Email    string  `json:"email"`
-	Balance  float64 `json:"balance"`
-	IsActive bool    `json:"is_active"`
+	ID            string               `json:"id"`
+	Username      string               `json:"username"`
+	Email         string               `json:"email"`
+	Balance       models.Money         `json:"balance"`
+	Status        models.AccountStatus `json:"status"`
+	EmailVerified bool                 `json:"email_verified"`
 }
 
+// LoginResponse berisi token, atau challenge token bila login masih butuh 2FA.
+// ChallengePurpose "2fa_verify" berarti kirim kode TOTP ke /auth/login/verify;
+// "2fa_setup" berarti daftarkan 2FA dulu lewat /auth/login/2fa-setup.
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token            string `json:"token,omitempty"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	ExpiresIn        int64  `json:"expires_in"`
+	ChallengeToken   string `json:"challenge_token,omitempty"`
+	ChallengePurpose string `json:"challenge_purpose,omitempty"`
 }
 
 type LogoutResponse struct {
@@ -18,14 +31,19 @@ type LogoutResponse struct {
 }
 
 type RefreshResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
 }
 
 type UserResponse struct {
-	ID       string   `json:"id"`
-	Username string   `json:"username"`
-	Email    string   `json:"email"`
-	Balance  float64  `json:"balance"`
-	IsActive bool     `json:"is_active"`
-	Roles    []string `json:"role"`
+	ID            string               `json:"id"`
+	Username      string               `json:"username"`
+	Email         string               `json:"email"`
+	Balance       models.Money         `json:"balance"`
+	Held          []models.Money       `json:"held,omitempty"`
+	Status        models.AccountStatus `json:"status"`
+	EmailVerified bool                 `json:"email_verified"`
+	LastLoginAt   *time.Time           `json:"last_login_at,omitempty"`
+	Roles         []string             `json:"role"`
 }
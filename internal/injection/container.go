@@ -0,0 +1,280 @@
+package injection
+
+import (
+	"fmt"
+	"go-json/constant"
+	"go-json/internal/bankrails"
+	"go-json/internal/controllers"
+	"go-json/internal/models"
+	"go-json/internal/notifications"
+	"go-json/internal/repositories"
+	"go-json/internal/security"
+	"go-json/internal/services"
+	"go-json/utils"
+	"log"
+	"os"
+	"time"
+)
+
+// Container memegang satu instance dari setiap repository, sehingga semua
+// service dan controller membaca dan menulis state yang sama.
+type Container struct {
+	TokenService security.TokenService
+	Hasher       security.PasswordHasher
+
+	UserRepository         repositories.UserRepository
+	RoleRepository         repositories.RoleRepository
+	RefreshTokenRepository repositories.RefreshTokenRepository
+	RevokedTokenRepository repositories.RevokedTokenRepository
+	TransactionRepository  repositories.TransactionRepository
+	LedgerRepository       repositories.LedgerRepository
+	IdempotencyRepository  repositories.IdempotencyRepository
+	AuditRepository        repositories.AuditRepository
+	LoginAttemptRepository repositories.LoginAttemptRepository
+	TwoFactorRepository    repositories.TwoFactorRepository
+	OneTimeTokenRepository repositories.OneTimeTokenRepository
+	APIKeyRepository       repositories.APIKeyRepository
+	MerchantRepository     repositories.MerchantRepository
+
+	Notifier notifications.Notifier
+	BankRail bankrails.BankRail
+
+	FXRateProvider      services.FXRateProvider
+	LoginThrottle       services.LoginThrottle
+	TwoFactorService    services.TwoFactorService
+	UserService         services.UserService
+	PasswordService     services.PasswordService
+	VerificationService services.EmailVerificationService
+	LockoutService      services.LockoutService
+	AccountService      services.AccountService
+	APIKeyService       services.APIKeyService
+	MerchantService     services.MerchantService
+	RoleService         services.RoleService
+	LedgerService       services.LedgerService
+	TransactionService  services.TransactionService
+
+	UserController              controllers.UserController
+	TransactionController       controllers.TransactionController
+	RoleController              controllers.RoleController
+	LockoutController           controllers.LockoutController
+	TwoFactorController         controllers.TwoFactorController
+	PasswordController          controllers.PasswordController
+	EmailVerificationController controllers.EmailVerificationController
+	AccountController           controllers.AccountController
+	APIKeyController            controllers.APIKeyController
+	MerchantController          controllers.MerchantController
+
+	IdempotencyTTL      time.Duration
+	LoginThrottleConfig services.LoginThrottleConfig
+	TrustProxyHeaders   bool
+	PasswordResetTTL    time.Duration
+	AuthorizationTTL    time.Duration
+	SandboxBalance      models.Money
+	TransferLimits      models.TransferLimits
+}
+
+func NewContainer() (*Container, error) {
+	c := &Container{}
+	if err := c.initRepositories(); err != nil {
+		return nil, err
+	}
+
+	secret := []byte(os.Getenv("JWT_SECRET"))
+	c.TokenService = security.NewTokenService(secret, security.TokenConfigFromEnv(), c.RevokedTokenRepository)
+	c.Hasher = security.NewPasswordHasherFromEnv()
+	c.IdempotencyTTL = utils.DurationFromEnv("IDEMPOTENCY_TTL", 24*time.Hour)
+	c.LoginThrottleConfig = services.LoginThrottleConfigFromEnv()
+	c.TrustProxyHeaders = os.Getenv("TRUST_PROXY_HEADERS") == "true"
+	c.PasswordResetTTL = utils.DurationFromEnv("PASSWORD_RESET_TTL", 30*time.Minute)
+	c.AuthorizationTTL = utils.DurationFromEnv("AUTHORIZATION_TTL", 7*24*time.Hour)
+	c.Notifier = notifications.NewNotifierFromEnv()
+	c.BankRail = bankrails.NewSimulator(bankrails.SimulatorConfigFromEnv())
+	// Saldo awal user baru hanya untuk sandbox; kosong berarti user mulai dari nol dan mengisi wallet lewat deposit
+	var err error
+	if c.SandboxBalance, err = moneyFromEnv("SANDBOX_STARTING_BALANCE", models.Money{}); err != nil {
+		return nil, err
+	}
+	if c.TransferLimits.PerTransfer, err = moneyFromEnv("TRANSFER_LIMIT_PER_TRANSFER", models.MustParseMoney("10000000", models.DefaultCurrency)); err != nil {
+		return nil, err
+	}
+	if c.TransferLimits.Daily, err = moneyFromEnv("TRANSFER_LIMIT_DAILY", models.MustParseMoney("25000000", models.DefaultCurrency)); err != nil {
+		return nil, err
+	}
+	if err := c.TransferLimits.Validate(); err != nil {
+		return nil, err
+	}
+
+	c.LoginThrottle = services.NewLoginThrottle(c.LoginAttemptRepository, c.AuditRepository, c.LoginThrottleConfig)
+	c.TwoFactorService = services.NewTwoFactorService(c.TwoFactorRepository, c.UserRepository, c.RoleRepository)
+	c.VerificationService = services.NewEmailVerificationService(c.UserRepository, c.OneTimeTokenRepository, c.AuditRepository, c.Notifier, services.EmailVerificationConfigFromEnv())
+	c.LedgerService = services.NewLedgerService(c.LedgerRepository, c.UserRepository)
+	c.UserService = services.NewUserService(c.UserRepository, c.RoleRepository, c.RefreshTokenRepository, c.RevokedTokenRepository, c.TransactionRepository, c.TokenService, c.Hasher, c.LoginThrottle, c.TwoFactorService, c.VerificationService, c.LedgerService, c.SandboxBalance)
+	c.PasswordService = services.NewPasswordService(c.UserRepository, c.RefreshTokenRepository, c.RevokedTokenRepository, c.OneTimeTokenRepository, c.AuditRepository, c.TokenService, c.Hasher, c.LoginThrottle, c.Notifier, c.PasswordResetTTL)
+	c.LockoutService = services.NewLockoutService(c.LoginAttemptRepository, c.UserRepository, c.AuditRepository, c.LoginThrottleConfig)
+	c.AccountService = services.NewAccountService(c.UserRepository, c.RefreshTokenRepository, c.RevokedTokenRepository, c.AuditRepository, c.TransactionRepository, c.LedgerService, c.TokenService, c.TransferLimits)
+	c.APIKeyService = services.NewAPIKeyService(c.APIKeyRepository, c.UserRepository, c.RoleRepository, c.AuditRepository)
+	c.MerchantService = services.NewMerchantService(c.MerchantRepository, c.UserRepository, c.RoleRepository, c.TransactionRepository, c.AuditRepository)
+	c.RoleService = services.NewRoleService(c.RoleRepository, c.UserRepository, c.AuditRepository)
+	fxRates := []models.FXRate{}
+	if err := utils.LoadJSONFile(constant.FX_RATE_FILE, &fxRates); err != nil {
+		return nil, err
+	}
+	fx, err := services.NewFXRateProvider(fxRates)
+	if err != nil {
+		return nil, err
+	}
+	c.FXRateProvider = fx
+	c.TransactionService = services.NewTransactionService(c.UserRepository, c.TransactionRepository, c.RoleRepository, c.MerchantRepository, c.LedgerService, c.FXRateProvider, c.AuditRepository, c.AuthorizationTTL, c.BankRail, c.TransferLimits)
+
+	c.UserController = controllers.NewUserController(c.UserService)
+	c.TransactionController = controllers.NewTransactionController(c.TransactionService)
+	c.RoleController = controllers.NewRoleController(c.RoleService)
+	c.LockoutController = controllers.NewLockoutController(c.LockoutService)
+	c.TwoFactorController = controllers.NewTwoFactorController(c.TwoFactorService)
+	c.PasswordController = controllers.NewPasswordController(c.PasswordService)
+	c.EmailVerificationController = controllers.NewEmailVerificationController(c.VerificationService)
+	c.AccountController = controllers.NewAccountController(c.AccountService)
+	c.APIKeyController = controllers.NewAPIKeyController(c.APIKeyService)
+	c.MerchantController = controllers.NewMerchantController(c.MerchantService)
+
+	if err := c.LedgerService.Reconcile(); err != nil {
+		return nil, err
+	}
+	if err := c.MerchantService.ProvisionLegacyMerchants(); err != nil {
+		return nil, err
+	}
+	if err := c.TransactionService.BackfillStatuses(); err != nil {
+		return nil, err
+	}
+	if err := c.TransactionService.ResumeBankTransfers(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Container) initRepositories() error {
+	users := []models.User{}
+	if err := utils.LoadJSONFile(constant.USER_FILE, &users); err != nil {
+		return err
+	}
+
+	roles := []models.Role{}
+	if err := utils.LoadJSONFile(constant.ROLE_FILE, &roles); err != nil {
+		return err
+	}
+
+	userRoles := []models.UserRole{}
+	if err := utils.LoadJSONFile(constant.USER_ROLE_FILE, &userRoles); err != nil {
+		return err
+	}
+
+	refreshTokens := []models.RefreshToken{}
+	if err := utils.LoadJSONFile(constant.REFRESH_TOKEN_FILE, &refreshTokens); err != nil {
+		return err
+	}
+
+	revokedTokens := []models.RevokedToken{}
+	if err := utils.LoadJSONFile(constant.REVOKED_TOKEN_FILE, &revokedTokens); err != nil {
+		return err
+	}
+
+	transactions := []models.Transaction{}
+	if err := utils.LoadJSONFile(constant.TRANSACTION_FILE, &transactions); err != nil {
+		return err
+	}
+
+	ledgerAccounts := []models.LedgerAccount{}
+	if err := utils.LoadJSONFile(constant.LEDGER_ACCOUNT_FILE, &ledgerAccounts); err != nil {
+		return err
+	}
+
+	journalEntries := []models.JournalEntry{}
+	if err := utils.LoadJSONFile(constant.JOURNAL_ENTRY_FILE, &journalEntries); err != nil {
+		return err
+	}
+
+	idempotencyRecords := []models.IdempotencyRecord{}
+	if err := utils.LoadJSONFile(constant.IDEMPOTENCY_FILE, &idempotencyRecords); err != nil {
+		return err
+	}
+
+	auditEvents := []models.AuditEvent{}
+	if err := utils.LoadJSONFile(constant.AUDIT_FILE, &auditEvents); err != nil {
+		return err
+	}
+
+	loginAttempts := []models.LoginAttempt{}
+	if err := utils.LoadJSONFile(constant.LOGIN_ATTEMPT_FILE, &loginAttempts); err != nil {
+		return err
+	}
+
+	twoFactors := []models.TwoFactor{}
+	if err := utils.LoadJSONFile(constant.TWO_FACTOR_FILE, &twoFactors); err != nil {
+		return err
+	}
+
+	oneTimeTokens := []models.OneTimeToken{}
+	if err := utils.LoadJSONFile(constant.ONE_TIME_TOKEN_FILE, &oneTimeTokens); err != nil {
+		return err
+	}
+
+	apiKeys := []models.APIKey{}
+	if err := utils.LoadJSONFile(constant.API_KEY_FILE, &apiKeys); err != nil {
+		return err
+	}
+
+	merchants := []models.Merchant{}
+	if err := utils.LoadJSONFile(constant.MERCHANT_FILE, &merchants); err != nil {
+		return err
+	}
+
+	c.RoleRepository = repositories.NewRoleRepository(roles, userRoles)
+	c.UserRepository = repositories.NewUserRepository(users, c.RoleRepository)
+	c.RefreshTokenRepository = repositories.NewRefreshTokenRepository(refreshTokens)
+	c.RevokedTokenRepository = repositories.NewRevokedTokenRepository(revokedTokens)
+	c.TransactionRepository = repositories.NewTransactionRepository(transactions)
+	c.LedgerRepository = repositories.NewLedgerRepository(ledgerAccounts, journalEntries)
+	c.IdempotencyRepository = repositories.NewIdempotencyRepository(idempotencyRecords)
+	c.AuditRepository = repositories.NewAuditRepository(auditEvents)
+	c.LoginAttemptRepository = repositories.NewLoginAttemptRepository(loginAttempts)
+	c.TwoFactorRepository = repositories.NewTwoFactorRepository(twoFactors)
+	c.OneTimeTokenRepository = repositories.NewOneTimeTokenRepository(oneTimeTokens)
+	c.APIKeyRepository = repositories.NewAPIKeyRepository(apiKeys)
+	c.MerchantRepository = repositories.NewMerchantRepository(merchants)
+	return nil
+}
+
+// StartBackgroundJobs menjalankan pekerjaan periodik seperti pembersihan token yang sudah dicabut
+func (c *Container) StartBackgroundJobs() {
+	go runEvery(time.Hour, "prune revoked tokens", c.RevokedTokenRepository.PruneExpired)
+	go runEvery(time.Hour, "prune idempotency keys", c.IdempotencyRepository.PruneExpired)
+	go runEvery(time.Hour, "prune one-time tokens", c.OneTimeTokenRepository.PruneExpired)
+	go runEvery(time.Hour, "prune login attempts", func() error {
+		return c.LoginAttemptRepository.PruneBefore(time.Now().Add(-c.LoginThrottleConfig.FailureWindow))
+	})
+	go runEvery(time.Minute, "expire authorizations", c.TransactionService.ExpireAuthorizations)
+}
+
+// moneyFromEnv membaca jumlah DefaultCurrency dari env; kosong berarti fallback
+func moneyFromEnv(key string, fallback models.Money) (models.Money, error) {
+	amount := os.Getenv(key)
+	if amount == "" {
+		return fallback, nil
+	}
+	money, err := models.ParseMoney(amount, models.DefaultCurrency)
+	if err != nil {
+		return models.Money{}, fmt.Errorf("%s: %w", key, err)
+	}
+	return money, nil
+}
+
+func runEvery(interval time.Duration, name string, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := job(); err != nil {
+			log.Printf("Failed to %s: %v", name, err)
+		}
+		<-ticker.C
+	}
+}
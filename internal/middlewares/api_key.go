@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"go-json/internal/security"
+	"net/http"
+)
+
+// APIKeyAuth mengautentikasi request yang membawa API key ("Bearer mbk_...") dan menyimpan
+// principal pemilik key di context. ProtectedHandler memakai principal itu dan tidak
+// memeriksa JWT lagi. Request dengan JWT atau tanpa Authorization diteruskan apa adanya.
+func APIKeyAuth(keys security.APIKeyAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, err := security.BearerToken(r.Header.Get("Authorization"))
+			if err != nil || !security.IsAPIKey(key) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			principal, err := keys.AuthenticateAPIKey(key)
+			if err != nil {
+				bearerChallenge(w, http.StatusUnauthorized, "invalid_token", "The API key is invalid or revoked", err.Error())
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(security.WithPrincipal(r.Context(), *principal)))
+		})
+	}
+}
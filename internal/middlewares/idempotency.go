@@ -0,0 +1,116 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	"go-json/internal/security"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const maxIdempotentBodySize = 1 << 20
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// IdempotentHandler menyimpan response pertama untuk setiap header Idempotency-Key.
+// Request ulang dengan body yang sama mendapat response yang sama persis, sedangkan
+// request ulang dengan body berbeda ditolak dengan 422. Response 5xx tidak disimpan
+// agar request yang sama bisa dicoba lagi.
+func IdempotentHandler(next http.Handler, store repositories.IdempotencyRepository, ttl time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > 255 {
+			http.Error(w, "Idempotency-Key is too long", http.StatusBadRequest)
+			return
+		}
+
+		// Satu byte lebih dari batas dibaca agar body yang terpotong tidak ikut di-hash seolah lengkap
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBodySize+1))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(body) > maxIdempotentBodySize {
+			http.Error(w, "Request body is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Key berlaku per user; IdempotentHandler selalu dipasang di dalam ProtectedHandler
+		principal, _ := security.PrincipalFromContext(r.Context())
+		sum := sha256.Sum256(append([]byte(r.Method+" "+r.URL.Path+"\n"), body...))
+		now := time.Now()
+		record := models.IdempotencyRecord{
+			Key:         key,
+			Scope:       principal.UserID,
+			Fingerprint: hex.EncodeToString(sum[:]),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		}
+
+		existing, reserved := store.Reserve(record)
+		if !reserved {
+			if existing.Fingerprint != record.Fingerprint {
+				http.Error(w, "Idempotency-Key was already used with a different request", http.StatusUnprocessableEntity)
+				return
+			}
+			if existing.StatusCode == 0 {
+				http.Error(w, "A request with this Idempotency-Key is still being processed", http.StatusConflict)
+				return
+			}
+			if existing.ContentType != "" {
+				w.Header().Set("Content-Type", existing.ContentType)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.Header().Set("Content-Length", strconv.Itoa(len(existing.Body)))
+			w.WriteHeader(existing.StatusCode)
+			w.Write([]byte(existing.Body))
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		defer func() {
+			if recorder.status == 0 || recorder.status >= http.StatusInternalServerError {
+				store.Release(record.Scope, record.Key)
+			}
+		}()
+		next.ServeHTTP(recorder, r)
+		if recorder.status == 0 || recorder.status >= http.StatusInternalServerError {
+			return
+		}
+
+		record.StatusCode = recorder.status
+		record.ContentType = recorder.Header().Get("Content-Type")
+		record.Body = recorder.body.String()
+		if err := store.Complete(record); err != nil {
+			log.Printf("Failed to store idempotency record: %v", err)
+		}
+	})
+}
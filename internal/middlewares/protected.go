@@ -1,42 +1,67 @@
 package middlewares
 
 import (
+	"errors"
 	"go-json/internal/security"
 	"net/http"
 	"slices"
 )
 
-func ProtectedHandler(next http.Handler, token security.TokenService, roles []string) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Authorization header required", http.StatusUnauthorized)
-			return
-		}
-
-		tokenString := authHeader[len("Bearer "):]
-		claims, err := token.VerifyToken(tokenString)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusUnauthorized)
-			return
-		}
+const authRealm = "merchant-bank"
 
-		// Pastikan user memiliki salah satu role yang diperbolehkan
-		authorized := false
-		for _, userRole := range claims.Role {
-			if slices.Contains(roles, userRole) {
-				authorized = true
-				break
+// ProtectedHandler memverifikasi access token dan menyimpan security.Principal di context request.
+// Handler berikutnya membaca identitas user lewat security.PrincipalFromContext, bukan dari header.
+// User harus punya minimal satu dari permissions; tanpa permissions cukup terautentikasi.
+// Request yang sudah diautentikasi APIKeyAuth memakai principal dari API key tersebut.
+func ProtectedHandler(next http.Handler, token security.TokenService, roles security.PermissionResolver, permissions ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := security.PrincipalFromContext(r.Context())
+		if !ok {
+			principal, ok = principalFromAccessToken(w, r, token, roles)
+			if !ok {
+				return
 			}
 		}
 
-		if !authorized {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		// Token valid tapi role-nya tidak memberi permission yang dibutuhkan: 403, bukan 401
+		if len(permissions) > 0 && !slices.ContainsFunc(permissions, principal.HasPermission) {
+			bearerChallenge(w, http.StatusForbidden, "insufficient_scope", "The access token does not grant the required permission", "Forbidden")
 			return
 		}
 
-		// Simpan email ke header untuk digunakan di handler berikutnya
-		r.Header.Set("email", claims.Email)
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(security.WithPrincipal(r.Context(), principal)))
 	})
 }
+
+// principalFromAccessToken memverifikasi JWT di header Authorization; bila gagal, response
+// error sudah ditulis dan ok bernilai false
+func principalFromAccessToken(w http.ResponseWriter, r *http.Request, token security.TokenService, roles security.PermissionResolver) (security.Principal, bool) {
+	tokenString, err := security.BearerToken(r.Header.Get("Authorization"))
+	if errors.Is(err, security.ErrMissingBearerToken) {
+		bearerChallenge(w, http.StatusUnauthorized, "", "", "Authorization header required")
+		return security.Principal{}, false
+	}
+	if err != nil {
+		bearerChallenge(w, http.StatusBadRequest, "invalid_request", "Malformed Authorization header", err.Error())
+		return security.Principal{}, false
+	}
+
+	claims, err := token.VerifyToken(tokenString)
+	if err != nil {
+		bearerChallenge(w, http.StatusUnauthorized, "invalid_token", "The access token is invalid or expired", err.Error())
+		return security.Principal{}, false
+	}
+	principal := security.PrincipalFromClaims(claims)
+	principal.Permissions = roles.PermissionsForRoles(principal.Roles)
+	return principal, true
+}
+
+// bearerChallenge menulis header WWW-Authenticate sesuai RFC 6750
+func bearerChallenge(w http.ResponseWriter, status int, code, description, message string) {
+	challenge := `Bearer realm="` + authRealm + `"`
+	if code != "" {
+		challenge += `, error="` + code + `", error_description="` + description + `"`
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, message, status)
+}
@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIP mengganti r.RemoteAddr dengan IP klien dari X-Forwarded-For. Aktifkan hanya bila
+// server berada di belakang reverse proxy tepercaya; tanpa proxy header ini bisa dipalsukan.
+// Yang dipakai adalah entri terakhir, yaitu alamat yang ditambahkan oleh proxy itu sendiri.
+func RealIP(trustProxy bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !trustProxy {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+			ip := strings.TrimSpace(forwarded[len(forwarded)-1])
+			if net.ParseIP(ip) != nil {
+				r.RemoteAddr = net.JoinHostPort(ip, "0")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package models
+
+import "slices"
+
+type AccountStatus string
+
+// Akun baru berstatus PENDING sampai emailnya diverifikasi. SUSPENDED dan CLOSED
+// hanya bisa diset admin; CLOSED bersifat final.
+const (
+	AccountPending   AccountStatus = "PENDING"
+	AccountActive    AccountStatus = "ACTIVE"
+	AccountSuspended AccountStatus = "SUSPENDED"
+	AccountClosed    AccountStatus = "CLOSED"
+)
+
+// accountTransitions berisi perpindahan status yang boleh dilakukan admin.
+// PENDING -> ACTIVE tidak ada di sini karena hanya terjadi lewat verifikasi email.
+var accountTransitions = map[AccountStatus][]AccountStatus{
+	AccountPending:   {AccountSuspended, AccountClosed},
+	AccountActive:    {AccountSuspended, AccountClosed},
+	AccountSuspended: {AccountActive, AccountClosed},
+}
+
+func (s AccountStatus) CanTransitionTo(next AccountStatus) bool {
+	return slices.Contains(accountTransitions[s], next)
+}
+
+// CanLogin bernilai false untuk akun yang dibekukan atau ditutup
+func (s AccountStatus) CanLogin() bool {
+	return s != AccountSuspended && s != AccountClosed
+}
+
+type AccountReasonCode string
+
+const (
+	ReasonSuspectedFraud   AccountReasonCode = "SUSPECTED_FRAUD"
+	ReasonChargebackRisk   AccountReasonCode = "CHARGEBACK_RISK"
+	ReasonComplianceReview AccountReasonCode = "COMPLIANCE_REVIEW"
+	ReasonCustomerRequest  AccountReasonCode = "CUSTOMER_REQUEST"
+	ReasonReviewCleared    AccountReasonCode = "REVIEW_CLEARED"
+	ReasonOther            AccountReasonCode = "OTHER"
+)
+
+var AccountReasonCodes = []AccountReasonCode{
+	ReasonSuspectedFraud,
+	ReasonChargebackRisk,
+	ReasonComplianceReview,
+	ReasonCustomerRequest,
+	ReasonReviewCleared,
+	ReasonOther,
+}
+
+func IsKnownAccountReason(code AccountReasonCode) bool {
+	return slices.Contains(AccountReasonCodes, code)
+}
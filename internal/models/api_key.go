@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// APIKey dipakai backend merchant untuk memanggil API tanpa login. Server hanya menyimpan
+// hash key-nya; Prefix disimpan apa adanya supaya key bisa dicari dan dikenali di daftar.
+type APIKey struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Label      string     `json:"label"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"key_hash"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (k APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
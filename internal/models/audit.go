@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+type AuditOutcome string
+
+const (
+	AuditAllowed AuditOutcome = "ALLOWED"
+	AuditDenied  AuditOutcome = "DENIED"
+)
+
+const (
+	AuditReadTransactionHistory = "transaction_history.read"
+	AuditCreateRole             = "role.create"
+	AuditGrantPermission        = "role.permission.grant"
+	AuditRevokePermission       = "role.permission.revoke"
+	AuditSetRoleTwoFactor       = "role.two_factor.set"
+	AuditAssignRole             = "user.role.assign"
+	AuditUnassignRole           = "user.role.unassign"
+	AuditLoginLockout           = "login.lockout"
+	AuditUnlockAccount          = "login.unlock.account"
+	AuditUnlockIP               = "login.unlock.ip"
+	AuditChangePassword         = "user.password.change"
+	AuditResetPassword          = "user.password.reset"
+	AuditVerifyEmail            = "user.email.verify"
+	AuditChangeAccountStatus    = "user.status.change"
+	AuditChangeTransferLimits   = "user.transfer_limits.change"
+	AuditCreateAPIKey           = "apikey.create"
+	AuditRevokeAPIKey           = "apikey.revoke"
+	AuditOnboardMerchant        = "merchant.onboard"
+	AuditUpdateMerchant         = "merchant.update"
+	AuditChangeMerchantStatus   = "merchant.status.change"
+	AuditAddMerchantOwner       = "merchant.owner.add"
+	AuditRemoveMerchantOwner    = "merchant.owner.remove"
+)
+
+type AuditEvent struct {
+	ID        string       `json:"id"`
+	ActorID   string       `json:"actor_id"`
+	Action    string       `json:"action"`
+	Resource  string       `json:"resource"`
+	Outcome   AuditOutcome `json:"outcome"`
+	Reason    string       `json:"reason,omitempty"`
+	Timestamp time.Time    `json:"timestamp"`
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// FXRate adalah satu baris di fx_rates.json: 1 From = Rate To selama
+// EffectiveFrom <= t < EffectiveTo. EffectiveTo kosong berarti berlaku seterusnya.
+type FXRate struct {
+	From          Currency   `json:"from"`
+	To            Currency   `json:"to"`
+	Rate          string     `json:"rate"`
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"`
+}
+
+func (r FXRate) IsEffectiveAt(at time.Time) bool {
+	return !at.Before(r.EffectiveFrom) && (r.EffectiveTo == nil || at.Before(*r.EffectiveTo))
+}
+
+// ExchangeRate adalah kurs yang dipakai pada sebuah transaksi, disimpan persis
+// seperti tertulis di fx_rates.json agar konversi ulang (mis. saat refund) menghasilkan angka yang sama.
+type ExchangeRate struct {
+	From Currency `json:"from"`
+	To   Currency `json:"to"`
+	Rate string   `json:"rate"`
+}
+
+func (r ExchangeRate) rat() (*big.Rat, error) {
+	if !decimalPattern.MatchString(r.Rate) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, r.Rate)
+	}
+	rate, ok := new(big.Rat).SetString(r.Rate)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, r.Rate)
+	}
+	return rate, nil
+}
+
+func (r ExchangeRate) Validate() error {
+	if _, err := CurrencyExponent(r.From); err != nil {
+		return err
+	}
+	if _, err := CurrencyExponent(r.To); err != nil {
+		return err
+	}
+	_, err := r.rat()
+	return err
+}
+
+// Convert mengubah amount ke mata uang pasangannya (From ke To atau To ke From),
+// dibulatkan setengah menjauhi nol ke satuan terkecil mata uang tujuan.
+func (r ExchangeRate) Convert(amount Money) (Money, error) {
+	rate, err := r.rat()
+	if err != nil {
+		return Money{}, err
+	}
+
+	var target Currency
+	switch amount.Currency() {
+	case r.From:
+		target = r.To
+	case r.To:
+		target = r.From
+		rate.Inv(rate)
+	default:
+		return Money{}, fmt.Errorf("%w: %s is not part of %s/%s", ErrCurrencyMismatch, amount.Currency(), r.From, r.To)
+	}
+
+	sourceExponent, err := CurrencyExponent(amount.Currency())
+	if err != nil {
+		return Money{}, err
+	}
+	targetExponent, err := CurrencyExponent(target)
+	if err != nil {
+		return Money{}, err
+	}
+
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(amount.Units()), rate)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(targetExponent-sourceExponent))), nil))
+	if targetExponent >= sourceExponent {
+		value.Mul(value, scale)
+	} else {
+		value.Quo(value, scale)
+	}
+
+	units, err := roundHalfAwayFromZero(value)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(units, target), nil
+}
+
+func roundHalfAwayFromZero(value *big.Rat) (int64, error) {
+	rounded := new(big.Rat).Set(value)
+	if !rounded.IsInt() {
+		half := big.NewRat(1, 2)
+		if rounded.Sign() < 0 {
+			rounded.Sub(rounded, half)
+		} else {
+			rounded.Add(rounded, half)
+		}
+	}
+	units := new(big.Int).Quo(rounded.Num(), rounded.Denom())
+	if !units.IsInt64() {
+		return 0, ErrAmountOverflow
+	}
+	return units.Int64(), nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
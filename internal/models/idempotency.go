@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IdempotencyRecord menyimpan response pertama untuk sebuah Idempotency-Key.
+// StatusCode 0 berarti request pertama masih diproses.
+type IdempotencyRecord struct {
+	Key         string    `json:"key"`
+	Scope       string    `json:"scope"`
+	Fingerprint string    `json:"fingerprint"`
+	StatusCode  int       `json:"status_code"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
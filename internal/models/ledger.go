@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+type AccountType string
+
+const (
+	WalletAccount         AccountType = "WALLET"
+	OpeningBalanceAccount AccountType = "OPENING_BALANCE"
+	FXConversionAccount   AccountType = "FX_CONVERSION"
+	// HoldAccount menampung dana customer yang ditahan oleh otorisasi sampai di-capture atau dilepas
+	HoldAccount AccountType = "HOLD"
+	// BankSettlementAccount mewakili dana yang masuk dan keluar lewat bank rail; saldonya negatif selama deposit melebihi withdrawal
+	BankSettlementAccount AccountType = "BANK_SETTLEMENT"
+	// SandboxFundingAccount adalah sumber saldo awal sandbox untuk user baru
+	SandboxFundingAccount AccountType = "SANDBOX_FUNDING"
+)
+
+type PostingDirection string
+
+const (
+	Debit  PostingDirection = "DEBIT"
+	Credit PostingDirection = "CREDIT"
+)
+
+// LedgerAccount adalah akun di buku besar. Saldo akun dihitung sebagai
+// total kredit dikurangi total debit dari seluruh posting.
+// Setiap akun hanya memegang satu mata uang; akun lama tanpa currency dianggap DefaultCurrency.
+type LedgerAccount struct {
+	ID        string      `json:"id"`
+	UserID    string      `json:"user_id,omitempty"`
+	Type      AccountType `json:"type"`
+	Currency  Currency    `json:"currency,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+func (a LedgerAccount) AccountCurrency() Currency {
+	if a.Currency == "" {
+		return DefaultCurrency
+	}
+	return a.Currency
+}
+
+type Posting struct {
+	AccountID string           `json:"account_id"`
+	Direction PostingDirection `json:"direction"`
+	Amount    Money            `json:"amount"`
+}
+
+// JournalEntry tidak pernah diubah setelah dicatat; koreksi dilakukan dengan entry baru.
+// Reference yang tidak kosong hanya boleh dipakai satu entry, sehingga dana untuk
+// satu transfer bank tidak dipindahkan dua kali.
+type JournalEntry struct {
+	ID          string    `json:"id"`
+	Reference   string    `json:"reference,omitempty"`
+	Description string    `json:"description"`
+	Timestamp   time.Time `json:"timestamp"`
+	Postings    []Posting `json:"postings"`
+}
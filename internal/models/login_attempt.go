@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type LoginAttemptKind string
+
+const (
+	AccountLoginAttempt LoginAttemptKind = "ACCOUNT"
+	IPLoginAttempt      LoginAttemptKind = "IP"
+)
+
+// LoginAttempt menghitung login gagal berturut-turut untuk satu username atau satu IP
+type LoginAttempt struct {
+	Kind          LoginAttemptKind `json:"kind"`
+	Key           string           `json:"key"`
+	Failures      int              `json:"failures"`
+	LastFailureAt time.Time        `json:"last_failure_at"`
+	LockedUntil   *time.Time       `json:"locked_until,omitempty"`
+}
+
+func (a LoginAttempt) IsLockedAt(at time.Time) bool {
+	return a.LockedUntil != nil && at.Before(*a.LockedUntil)
+}
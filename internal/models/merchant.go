@@ -0,0 +1,52 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+type MerchantStatus string
+
+// Merchant baru berstatus PENDING sampai disetujui admin; hanya merchant ACTIVE yang bisa menerima pembayaran
+const (
+	MerchantPending   MerchantStatus = "PENDING"
+	MerchantActive    MerchantStatus = "ACTIVE"
+	MerchantSuspended MerchantStatus = "SUSPENDED"
+)
+
+var merchantTransitions = map[MerchantStatus][]MerchantStatus{
+	MerchantPending:   {MerchantActive, MerchantSuspended},
+	MerchantActive:    {MerchantSuspended},
+	MerchantSuspended: {MerchantActive},
+}
+
+func (s MerchantStatus) CanTransitionTo(next MerchantStatus) bool {
+	return slices.Contains(merchantTransitions[s], next)
+}
+
+// SettlementAccount adalah rekening bank tujuan pencairan dana merchant
+type SettlementAccount struct {
+	BankCode      string `json:"bank_code"`
+	AccountNumber string `json:"account_number"`
+	AccountHolder string `json:"account_holder"`
+}
+
+// Merchant adalah profil usaha penerima pembayaran. ID sama dengan ID user yang wallet-nya
+// menerima pembayaran, sehingga PaymentRequest.MerchantID dan ledger tetap memakai ID yang sama.
+// OwnerIDs berisi user yang boleh mengelola merchant ini, termasuk user pemilik wallet.
+type Merchant struct {
+	ID                string            `json:"id"`
+	LegalName         string            `json:"legal_name"`
+	DisplayName       string            `json:"display_name"`
+	CategoryCode      string            `json:"category_code"`
+	SettlementAccount SettlementAccount `json:"settlement_account"`
+	Status            MerchantStatus    `json:"status"`
+	StatusReason      string            `json:"status_reason,omitempty"`
+	OwnerIDs          []string          `json:"owner_ids"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
+
+func (m Merchant) IsOwnedBy(userID string) bool {
+	return userID != "" && slices.Contains(m.OwnerIDs, userID)
+}
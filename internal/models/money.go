@@ -0,0 +1,220 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Currency string
+
+const DefaultCurrency Currency = "IDR"
+
+// Jumlah digit di belakang koma untuk setiap mata uang (ISO 4217)
+var currencyExponents = map[Currency]int{
+	"IDR": 2,
+	"USD": 2,
+	"EUR": 2,
+	"SGD": 2,
+	"JPY": 0,
+}
+
+// Eksponen dibatasi dua digit agar input seperti "1e999999999" tidak menghabiskan memori
+var decimalPattern = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]{1,2})?$`)
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+	ErrAmountTooPrecise    = errors.New("amount has more decimal places than the currency allows")
+	ErrAmountOverflow      = errors.New("amount is out of range")
+	ErrInvalidAmount       = errors.New("invalid amount")
+)
+
+// Money menyimpan jumlah uang sebagai bilangan bulat dalam satuan terkecil
+// mata uang (mis. sen), sehingga penjumlahan tidak pernah bergeser seperti float64.
+// Zero value berarti 0 dalam DefaultCurrency.
+type Money struct {
+	units    int64
+	currency Currency
+}
+
+func CurrencyExponent(currency Currency) (int, error) {
+	exponent, ok := currencyExponents[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+	return exponent, nil
+}
+
+func NewMoney(units int64, currency Currency) Money {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	return Money{units: units, currency: currency}
+}
+
+// ParseMoney membaca jumlah desimal seperti "10.50" atau "1e6" secara eksak
+func ParseMoney(amount string, currency Currency) (Money, error) {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	exponent, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	amount = strings.TrimSpace(amount)
+	if !decimalPattern.MatchString(amount) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	value, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	value.Mul(value, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)))
+	if !value.IsInt() {
+		return Money{}, fmt.Errorf("%w: %s %s", ErrAmountTooPrecise, amount, currency)
+	}
+	if !value.Num().IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s %s", ErrAmountOverflow, amount, currency)
+	}
+	return Money{units: value.Num().Int64(), currency: currency}, nil
+}
+
+func MustParseMoney(amount string, currency Currency) Money {
+	money, err := ParseMoney(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return money
+}
+
+func (m Money) Units() int64 {
+	return m.units
+}
+
+func (m Money) Currency() Currency {
+	if m.currency == "" {
+		return DefaultCurrency
+	}
+	return m.currency
+}
+
+func (m Money) IsZero() bool     { return m.units == 0 }
+func (m Money) IsPositive() bool { return m.units > 0 }
+func (m Money) IsNegative() bool { return m.units < 0 }
+
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency() != other.Currency() {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency(), other.Currency())
+	}
+	sum := m.units + other.units
+	if (other.units > 0 && sum < m.units) || (other.units < 0 && sum > m.units) {
+		return Money{}, ErrAmountOverflow
+	}
+	return Money{units: sum, currency: m.Currency()}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if other.units == math.MinInt64 {
+		return Money{}, ErrAmountOverflow
+	}
+	return m.Add(Money{units: -other.units, currency: other.currency})
+}
+
+// Cmp mengembalikan -1, 0 atau 1 seperti big.Int.Cmp
+func (m Money) Cmp(other Money) (int, error) {
+	if m.Currency() != other.Currency() {
+		return 0, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency(), other.Currency())
+	}
+	switch {
+	case m.units < other.units:
+		return -1, nil
+	case m.units > other.units:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Decimal memformat jumlah tanpa kode mata uang, mis. "1050.00"
+func (m Money) Decimal() string {
+	exponent, err := CurrencyExponent(m.Currency())
+	if err != nil || exponent == 0 {
+		return strconv.FormatInt(m.units, 10)
+	}
+
+	sign := ""
+	abs := new(big.Int).SetInt64(m.units)
+	if m.units < 0 {
+		sign = "-"
+		abs.Neg(abs)
+	}
+	digits := abs.String()
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + string(m.Currency())
+}
+
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency Currency        `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	amount, err := json.Marshal(m.Decimal())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(moneyJSON{Amount: amount, Currency: m.Currency()})
+}
+
+// UnmarshalJSON menerima {"amount":"10.50","currency":"IDR"} dan juga angka atau
+// string biasa dari file lama, yang dianggap dalam DefaultCurrency.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+
+	amount := data
+	currency := DefaultCurrency
+	if data[0] == '{' {
+		var raw moneyJSON
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		if len(raw.Amount) == 0 {
+			return fmt.Errorf("%w: missing amount", ErrInvalidAmount)
+		}
+		if raw.Currency != "" {
+			currency = Currency(strings.ToUpper(string(raw.Currency)))
+		}
+		amount = bytes.TrimSpace(raw.Amount)
+	}
+
+	literal := string(amount)
+	if len(amount) > 0 && amount[0] == '"' {
+		if err := json.Unmarshal(amount, &literal); err != nil {
+			return err
+		}
+	}
+
+	money, err := ParseMoney(literal, currency)
+	if err != nil {
+		return err
+	}
+	*m = money
+	return nil
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type OneTimeTokenPurpose string
+
+const (
+	PasswordResetToken     OneTimeTokenPurpose = "PASSWORD_RESET"
+	EmailVerificationToken OneTimeTokenPurpose = "EMAIL_VERIFICATION"
+)
+
+// OneTimeToken adalah token sekali pakai yang dikirim lewat notifier. Server hanya
+// menyimpan hash-nya; UsedAt terisi begitu token dipakai.
+type OneTimeToken struct {
+	UserID    string              `json:"user_id"`
+	Purpose   OneTimeTokenPurpose `json:"purpose"`
+	TokenHash string              `json:"token_hash"`
+	CreatedAt time.Time           `json:"created_at"`
+	ExpiresAt time.Time           `json:"expires_at"`
+	UsedAt    *time.Time          `json:"used_at,omitempty"`
+}
+
+func (t OneTimeToken) IsUsableAt(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
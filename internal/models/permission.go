@@ -0,0 +1,75 @@
+package models
+
+import "slices"
+
+const (
+	PermissionPaymentCreate         = "payment:create"
+	PermissionPaymentCreateOnBehalf = "payment:create:on_behalf"
+	PermissionPaymentCapture        = "payment:capture"
+	PermissionRefundCreate          = "refund:create"
+	PermissionWalletDeposit         = "wallet:deposit"
+	PermissionWalletWithdraw        = "wallet:withdraw"
+	PermissionTransferCreate        = "transfer:create"
+	PermissionHistoryReadOwn        = "history:read:own"
+	PermissionHistoryReadMerchant   = "history:read:merchant"
+	PermissionHistoryReadAny        = "history:read:any"
+	PermissionUserList              = "user:list"
+	PermissionSessionLogout         = "session:logout"
+	PermissionRoleManage            = "role:manage"
+	PermissionLockoutManage         = "lockout:manage"
+	PermissionTwoFactorManage       = "2fa:manage"
+	PermissionPasswordChange        = "password:change"
+	PermissionAccountManage         = "account:manage"
+	PermissionAPIKeyManage          = "apikey:manage"
+	PermissionMerchantManage        = "merchant:manage"
+	PermissionMerchantReview        = "merchant:review"
+)
+
+// Permissions berisi semua permission yang dikenal; role hanya boleh memakai nama dari daftar ini
+var Permissions = []string{
+	PermissionPaymentCreate,
+	PermissionPaymentCreateOnBehalf,
+	PermissionPaymentCapture,
+	PermissionRefundCreate,
+	PermissionWalletDeposit,
+	PermissionWalletWithdraw,
+	PermissionTransferCreate,
+	PermissionHistoryReadOwn,
+	PermissionHistoryReadMerchant,
+	PermissionHistoryReadAny,
+	PermissionUserList,
+	PermissionSessionLogout,
+	PermissionRoleManage,
+	PermissionLockoutManage,
+	PermissionTwoFactorManage,
+	PermissionPasswordChange,
+	PermissionAccountManage,
+	PermissionAPIKeyManage,
+	PermissionMerchantManage,
+	PermissionMerchantReview,
+}
+
+func IsKnownPermission(permission string) bool {
+	return slices.Contains(Permissions, permission)
+}
+
+// APIKeyScopes berisi permission yang boleh diberikan ke API key. Permission untuk mengelola
+// akun, sesi, dan API key itu sendiri, serta permission admin, sengaja tidak ada di sini.
+var APIKeyScopes = []string{
+	PermissionPaymentCreate,
+	PermissionPaymentCapture,
+	PermissionRefundCreate,
+	PermissionHistoryReadOwn,
+	PermissionHistoryReadMerchant,
+}
+
+func IsAPIKeyScope(permission string) bool {
+	return slices.Contains(APIKeyScopes, permission)
+}
+
+// DefaultRolePermissions dipakai untuk role bawaan dari roles.json lama yang belum punya field permissions
+var DefaultRolePermissions = map[string][]string{
+	"customer": {PermissionPaymentCreate, PermissionWalletDeposit, PermissionWalletWithdraw, PermissionTransferCreate, PermissionHistoryReadOwn, PermissionSessionLogout, PermissionTwoFactorManage, PermissionPasswordChange},
+	"merchant": {PermissionPaymentCapture, PermissionRefundCreate, PermissionWalletDeposit, PermissionWalletWithdraw, PermissionHistoryReadOwn, PermissionHistoryReadMerchant, PermissionUserList, PermissionSessionLogout, PermissionTwoFactorManage, PermissionPasswordChange, PermissionAPIKeyManage, PermissionMerchantManage},
+	"admin":    {PermissionPaymentCreateOnBehalf, PermissionHistoryReadAny, PermissionUserList, PermissionRoleManage, PermissionLockoutManage, PermissionAccountManage, PermissionSessionLogout, PermissionTwoFactorManage, PermissionPasswordChange, PermissionMerchantReview},
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RevokedToken dengan JTI kosong mencabut semua token milik UserID
+// yang diterbitkan sebelum detik RevokedAt (logout dari semua sesi). Bila ExceptSessionID
+// diisi, token dari sesi tersebut tidak ikut dicabut (misalnya saat ganti password).
+// RevokedToken dengan SessionID mencabut semua access token dari sesi itu saja.
+type RevokedToken struct {
+	JTI             string    `json:"jti,omitempty"`
+	UserID          string    `json:"user_id"`
+	SessionID       string    `json:"session_id,omitempty"`
+	ExceptSessionID string    `json:"except_session_id,omitempty"`
+	RevokedAt       time.Time `json:"revoked_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
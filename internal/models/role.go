@@ -1,9 +1,20 @@
 package models
 
+import "slices"
+
+// Registrable menandai role yang boleh dipilih sendiri saat registrasi.
+// RequireTwoFactor mewajibkan semua pemegang role ini login dengan TOTP.
 type Role struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	IsDefault bool   `json:"is_default"`
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	IsDefault        bool     `json:"is_default"`
+	Registrable      bool     `json:"registrable"`
+	RequireTwoFactor bool     `json:"require_two_factor"`
+	Permissions      []string `json:"permissions"`
+}
+
+func (r Role) HasPermission(permission string) bool {
+	return slices.Contains(r.Permissions, permission)
 }
 
 type UserRole struct {
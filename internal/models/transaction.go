@@ -10,14 +10,61 @@ const (
 	LogoutActivity  ActivityType = "LOGOUT"
 	FailedLogin     ActivityType = "FAILED_LOGIN"
 	FailedPayment   ActivityType = "FAILED_PAYMENT"
+	RefundActivity  ActivityType = "REFUND"
+	FailedRefund    ActivityType = "FAILED_REFUND"
+
+	AuthorizationActivity ActivityType = "AUTHORIZATION"
+	FailedAuthorization   ActivityType = "FAILED_AUTHORIZATION"
+	CaptureActivity       ActivityType = "CAPTURE"
+	FailedCapture         ActivityType = "FAILED_CAPTURE"
+	VoidActivity          ActivityType = "VOID"
+	ExpiryActivity        ActivityType = "AUTHORIZATION_EXPIRED"
+
+	DepositActivity    ActivityType = "DEPOSIT"
+	FailedDeposit      ActivityType = "FAILED_DEPOSIT"
+	WithdrawalActivity ActivityType = "WITHDRAWAL"
+	FailedWithdrawal   ActivityType = "FAILED_WITHDRAWAL"
+
+	TransferActivity ActivityType = "TRANSFER"
+	FailedTransfer   ActivityType = "FAILED_TRANSFER"
+)
+
+// AuthorizationStatus tidak disimpan; dihitung dari transaksi CAPTURE, VOID, dan
+// AUTHORIZATION_EXPIRED yang OriginalTransactionID-nya menunjuk ke otorisasi
+type AuthorizationStatus string
+
+const (
+	AuthorizationOpen     AuthorizationStatus = "OPEN"
+	AuthorizationCaptured AuthorizationStatus = "CAPTURED"
+	AuthorizationVoided   AuthorizationStatus = "VOIDED"
+	AuthorizationExpired  AuthorizationStatus = "EXPIRED"
 )
 
 type Transaction struct {
-	ID           string       `json:"id"`
-	CustomerID   string       `json:"customer_id"`
-	ActivityType ActivityType `json:"activity_type"`
-	Timestamp    time.Time    `json:"timestamp"`
-	Details      string       `json:"details"`
-	Amount       float64      `json:"amount,omitempty"`
-	MerchantID   string       `json:"merchant_id,omitempty"`
+	ID                    string        `json:"id"`
+	CustomerID            string        `json:"customer_id"`
+	ActivityType          ActivityType  `json:"activity_type"`
+	Timestamp             time.Time     `json:"timestamp"`
+	Details               string        `json:"details"`
+	Amount                Money         `json:"amount"`
+	MerchantID            string        `json:"merchant_id,omitempty"`
+	JournalEntryID        string        `json:"journal_entry_id,omitempty"`
+	OriginalTransactionID string        `json:"original_transaction_id,omitempty"`
+	SourceAmount          *Money        `json:"source_amount,omitempty"`
+	ExchangeRate          *ExchangeRate `json:"exchange_rate,omitempty"`
+	InitiatedBy           string        `json:"initiated_by,omitempty"`
+	ExpiresAt             *time.Time    `json:"expires_at,omitempty"`
+	// BankAccount dan BankReference hanya diisi untuk deposit dan withdrawal lewat bank rail
+	BankAccount   *SettlementAccount `json:"bank_account,omitempty"`
+	BankReference string             `json:"bank_reference,omitempty"`
+	// RecipientID dan Note hanya diisi untuk transfer antar-user; pengirimnya adalah CustomerID.
+	// LimitAmount adalah nilai transfer dalam DefaultCurrency dengan kurs saat transfer dibuat,
+	// sehingga batas harian tidak perlu menghitung ulang kurs transfer lama.
+	RecipientID string `json:"recipient_id,omitempty"`
+	Note        string `json:"note,omitempty"`
+	LimitAmount *Money `json:"limit_amount,omitempty"`
+
+	Status        TransactionStatus         `json:"status,omitempty"`
+	FailureReason FailureReason             `json:"failure_reason,omitempty"`
+	StatusHistory []TransactionStatusChange `json:"status_history,omitempty"`
 }
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+var ErrInvalidTransactionTransition = errors.New("transaction status transition is not allowed")
+
+type TransactionStatus string
+
+// Setiap transaksi dimulai dari PENDING. FAILED dan REVERSED bersifat final; REVERSED berarti
+// dana yang ditahan dikembalikan tanpa ada yang berpindah ke merchant.
+const (
+	TransactionPending           TransactionStatus = "PENDING"
+	TransactionSucceeded         TransactionStatus = "SUCCEEDED"
+	TransactionFailed            TransactionStatus = "FAILED"
+	TransactionReversed          TransactionStatus = "REVERSED"
+	TransactionRefunded          TransactionStatus = "REFUNDED"
+	TransactionPartiallyRefunded TransactionStatus = "PARTIALLY_REFUNDED"
+)
+
+var transactionTransitions = map[TransactionStatus][]TransactionStatus{
+	TransactionPending:           {TransactionSucceeded, TransactionFailed, TransactionReversed},
+	TransactionSucceeded:         {TransactionPartiallyRefunded, TransactionRefunded},
+	TransactionPartiallyRefunded: {TransactionPartiallyRefunded, TransactionRefunded},
+}
+
+func (s TransactionStatus) CanTransitionTo(next TransactionStatus) bool {
+	return slices.Contains(transactionTransitions[s], next)
+}
+
+// FailureReason adalah kode alasan transaksi FAILED yang stabil untuk dibaca mesin;
+// Details tetap berisi penjelasan untuk manusia dan bisa berubah
+type FailureReason string
+
+const (
+	FailureInvalidAmount             FailureReason = "INVALID_AMOUNT"
+	FailureInvalidCustomer           FailureReason = "INVALID_CUSTOMER"
+	FailureCustomerMismatch          FailureReason = "CUSTOMER_MISMATCH"
+	FailureCustomerSuspended         FailureReason = "CUSTOMER_SUSPENDED"
+	FailureCustomerClosed            FailureReason = "CUSTOMER_CLOSED"
+	FailureCustomerNotVerified       FailureReason = "CUSTOMER_NOT_VERIFIED"
+	FailureInvalidMerchant           FailureReason = "INVALID_MERCHANT"
+	FailureMerchantNotActive         FailureReason = "MERCHANT_NOT_ACTIVE"
+	FailureMerchantSuspended         FailureReason = "MERCHANT_SUSPENDED"
+	FailureMerchantAccountSuspended  FailureReason = "MERCHANT_ACCOUNT_SUSPENDED"
+	FailureMerchantAccountClosed     FailureReason = "MERCHANT_ACCOUNT_CLOSED"
+	FailureSameParty                 FailureReason = "SAME_PARTY"
+	FailureInvalidRecipient          FailureReason = "INVALID_RECIPIENT"
+	FailureRecipientNotActive        FailureReason = "RECIPIENT_NOT_ACTIVE"
+	FailureTransferLimitExceeded     FailureReason = "TRANSFER_LIMIT_EXCEEDED"
+	FailureDailyLimitExceeded        FailureReason = "DAILY_TRANSFER_LIMIT_EXCEEDED"
+	FailureUnsupportedCurrency       FailureReason = "UNSUPPORTED_CURRENCY"
+	FailureCurrencyMismatch          FailureReason = "CURRENCY_MISMATCH"
+	FailureExchangeRateUnavailable   FailureReason = "EXCHANGE_RATE_UNAVAILABLE"
+	FailureInsufficientBalance       FailureReason = "INSUFFICIENT_BALANCE"
+	FailureInsufficientMerchantFunds FailureReason = "INSUFFICIENT_MERCHANT_BALANCE"
+	FailureExceedsRefundable         FailureReason = "EXCEEDS_REFUNDABLE"
+	FailureAmountTooSmall            FailureReason = "AMOUNT_TOO_SMALL"
+	FailureExceedsHeld               FailureReason = "EXCEEDS_HELD"
+	FailureAuthorizationClosed       FailureReason = "AUTHORIZATION_CLOSED"
+	FailureAuthorizationExpired      FailureReason = "AUTHORIZATION_EXPIRED"
+	FailureLedgerError               FailureReason = "LEDGER_ERROR"
+	FailureBankRejected              FailureReason = "BANK_REJECTED"
+	FailureBankUnavailable           FailureReason = "BANK_UNAVAILABLE"
+	FailureInvalidCredentials        FailureReason = "INVALID_CREDENTIALS"
+	FailureInvalidTwoFactorCode      FailureReason = "INVALID_TWO_FACTOR_CODE"
+	FailureLoginThrottled            FailureReason = "LOGIN_THROTTLED"
+	FailureUnknown                   FailureReason = "UNKNOWN"
+)
+
+type TransactionStatusChange struct {
+	Status    TransactionStatus `json:"status"`
+	Reason    FailureReason     `json:"reason,omitempty"`
+	ChangedAt time.Time         `json:"changed_at"`
+}
+
+// Begin menandai transaksi baru sebagai PENDING; dipanggil sekali saat transaksi mulai diproses
+func (t *Transaction) Begin(at time.Time) {
+	t.Status = TransactionPending
+	t.StatusHistory = []TransactionStatusChange{{Status: TransactionPending, ChangedAt: at}}
+}
+
+// TransitionTo memindahkan transaksi ke status berikutnya dan mencatatnya di StatusHistory
+func (t *Transaction) TransitionTo(status TransactionStatus, at time.Time) error {
+	if !t.Status.CanTransitionTo(status) {
+		return ErrInvalidTransactionTransition
+	}
+	t.Status = status
+	t.StatusHistory = append(t.StatusHistory, TransactionStatusChange{Status: status, ChangedAt: at})
+	return nil
+}
+
+// Fail memindahkan transaksi ke FAILED dengan kode alasannya
+func (t *Transaction) Fail(reason FailureReason, at time.Time) error {
+	if !t.Status.CanTransitionTo(TransactionFailed) {
+		return ErrInvalidTransactionTransition
+	}
+	t.Status = TransactionFailed
+	t.FailureReason = reason
+	t.StatusHistory = append(t.StatusHistory, TransactionStatusChange{Status: TransactionFailed, Reason: reason, ChangedAt: at})
+	return nil
+}
@@ -0,0 +1,25 @@
+package models
+
+import "errors"
+
+var ErrInvalidTransferLimits = errors.New("transfer limits must be positive IDR amounts and the per-transfer limit cannot exceed the daily limit")
+
+// TransferLimits membatasi transfer antar-user, dalam DefaultCurrency. Transfer dalam mata uang
+// lain dihitung dengan kurs saat transfer. Daily berlaku untuk 24 jam terakhir, bukan hari kalender.
+type TransferLimits struct {
+	PerTransfer Money `json:"per_transfer"`
+	Daily       Money `json:"daily"`
+}
+
+func (l TransferLimits) Validate() error {
+	if l.PerTransfer.Currency() != DefaultCurrency || l.Daily.Currency() != DefaultCurrency {
+		return ErrInvalidTransferLimits
+	}
+	if !l.PerTransfer.IsPositive() || !l.Daily.IsPositive() {
+		return ErrInvalidTransferLimits
+	}
+	if cmp, err := l.PerTransfer.Cmp(l.Daily); err != nil || cmp > 0 {
+		return ErrInvalidTransferLimits
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// TwoFactor menyimpan secret TOTP milik user. Enabled baru bernilai true setelah user
+// mengonfirmasi kode pertama; recovery code hanya disimpan dalam bentuk hash.
+type TwoFactor struct {
+	UserID             string     `json:"user_id"`
+	Secret             string     `json:"secret"`
+	Enabled            bool       `json:"enabled"`
+	RecoveryCodeHashes []string   `json:"recovery_code_hashes"`
+	LastUsedStep       int64      `json:"last_used_step"`
+	CreatedAt          time.Time  `json:"created_at"`
+	EnabledAt          *time.Time `json:"enabled_at,omitempty"`
+}
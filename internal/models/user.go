@@ -1,10 +1,55 @@
 package models
 
+import "time"
+
 type User struct {
-	ID       string  `json:"id"`
-	Username string  `json:"username" validate:"required,min=5,alphanum,username_check"`
-	Email    string  `json:"email" validate:"required,email"`
-	Password string  `json:"password" validate:"required,min=8,password_check"`
-	Balance  float64 `json:"balance"`
-	IsActive bool    `json:"is_active"`
+	ID              string        `json:"id"`
+	Username        string        `json:"username" validate:"required,min=5,alphanum,username_check"`
+	Email           string        `json:"email" validate:"required,email"`
+	Password        string        `json:"password" validate:"required,min=8,password_check"`
+	Balance         Money         `json:"balance"`
+	Wallets         []Money       `json:"wallets,omitempty"`
+	Held            []Money       `json:"held,omitempty"`
+	Status          AccountStatus `json:"status,omitempty"`
+	StatusReason    string        `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time    `json:"status_changed_at,omitempty"`
+	EmailVerifiedAt *time.Time    `json:"email_verified_at,omitempty"`
+	LastLoginAt     *time.Time    `json:"last_login_at,omitempty"`
+	// TransferLimits diisi admin untuk user tertentu; nil berarti memakai batas bawaan
+	TransferLimits *TransferLimits `json:"transfer_limits,omitempty"`
+}
+
+// AccountStatus mengembalikan status akun. User dari users.json lama yang belum punya
+// status dianggap ACTIVE, karena mereka terdaftar sebelum ada verifikasi email.
+func (u User) AccountStatus() AccountStatus {
+	if u.Status == "" {
+		return AccountActive
+	}
+	return u.Status
+}
+
+// EffectiveTransferLimits mengembalikan batas transfer khusus user bila ada, selain itu defaults
+func (u User) EffectiveTransferLimits(defaults TransferLimits) TransferLimits {
+	if u.TransferLimits != nil {
+		return *u.TransferLimits
+	}
+	return defaults
+}
+
+func (u User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// BalanceIn mengembalikan saldo wallet dalam mata uang tertentu.
+// Balance selalu berisi saldo DefaultCurrency, Wallets berisi saldo setiap wallet.
+func (u User) BalanceIn(currency Currency) Money {
+	for _, wallet := range u.Wallets {
+		if wallet.Currency() == currency {
+			return wallet
+		}
+	}
+	if currency == u.Balance.Currency() {
+		return u.Balance
+	}
+	return NewMoney(0, currency)
 }
@@ -0,0 +1,72 @@
+package notifications
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+type Notification struct {
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// Notifier mengirim pesan ke user, misalnya token reset password. Implementasi di sini
+// hanya untuk pengembangan lokal; produksi bisa memasang pengirim email atau SMS.
+type Notifier interface {
+	Send(notification Notification) error
+}
+
+// NewNotifierFromEnv menulis notifikasi ke file NOTIFIER_FILE bila diisi, selain itu ke log
+func NewNotifierFromEnv() Notifier {
+	if path := os.Getenv("NOTIFIER_FILE"); path != "" {
+		return NewFileNotifier(path)
+	}
+	return NewLogNotifier()
+}
+
+type logNotifier struct{}
+
+func NewLogNotifier() Notifier {
+	return logNotifier{}
+}
+
+func (logNotifier) Send(notification Notification) error {
+	log.Printf("Notification to %s: %s\n%s", notification.To, notification.Subject, notification.Body)
+	return nil
+}
+
+// fileNotifier menambahkan setiap notifikasi sebagai satu baris JSON ke path
+type fileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileNotifier(path string) Notifier {
+	return &fileNotifier{path: path}
+}
+
+func (n *fileNotifier) Send(notification Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if notification.SentAt.IsZero() {
+		notification.SentAt = time.Now()
+	}
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
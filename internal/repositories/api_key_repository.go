@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"errors"
+	"go-json/constant"
+	"go-json/internal/models"
+	"go-json/utils"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+type APIKeyRepository interface {
+	Create(key models.APIKey) (*models.APIKey, error)
+	FindByID(id string) (*models.APIKey, error)
+	FindByPrefix(prefix string) (*models.APIKey, error)
+	FindByUserID(userID string) ([]models.APIKey, error)
+	Update(key models.APIKey) error
+}
+
+type apiKeyRepository struct {
+	keys []models.APIKey
+	mu   sync.RWMutex
+}
+
+func NewAPIKeyRepository(keys []models.APIKey) APIKeyRepository {
+	return &apiKeyRepository{
+		keys: keys,
+		mu:   sync.RWMutex{},
+	}
+}
+
+func (r *apiKeyRepository) Create(key models.APIKey) (*models.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key.ID = strconv.Itoa(len(r.keys) + 1)
+	r.keys = append(r.keys, key)
+	if err := utils.WriteJSONFile(constant.API_KEY_FILE, r.keys); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) FindByID(id string) (*models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.ID == id {
+			keyCopy := key
+			keyCopy.Scopes = slices.Clone(key.Scopes)
+			return &keyCopy, nil
+		}
+	}
+	return nil, errors.New("api key not found")
+}
+
+func (r *apiKeyRepository) FindByPrefix(prefix string) (*models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.Prefix == prefix {
+			keyCopy := key
+			keyCopy.Scopes = slices.Clone(key.Scopes)
+			return &keyCopy, nil
+		}
+	}
+	return nil, errors.New("api key not found")
+}
+
+func (r *apiKeyRepository) FindByUserID(userID string) ([]models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := []models.APIKey{}
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			keyCopy := key
+			keyCopy.Scopes = slices.Clone(key.Scopes)
+			keys = append(keys, keyCopy)
+		}
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Update(key models.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.keys {
+		if existing.ID == key.ID {
+			r.keys[i] = key
+			return utils.WriteJSONFile(constant.API_KEY_FILE, r.keys)
+		}
+	}
+	return errors.New("api key not found")
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"go-json/constant"
+	"go-json/internal/models"
+	"go-json/utils"
+	"strconv"
+	"sync"
+)
+
+type AuditRepository interface {
+	Record(event models.AuditEvent) (*models.AuditEvent, error)
+	FindAll() ([]models.AuditEvent, error)
+}
+
+type auditRepository struct {
+	events []models.AuditEvent
+	mu     sync.RWMutex
+}
+
+func NewAuditRepository(events []models.AuditEvent) AuditRepository {
+	return &auditRepository{
+		events: events,
+		mu:     sync.RWMutex{},
+	}
+}
+
+func (a *auditRepository) Record(event models.AuditEvent) (*models.AuditEvent, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	event.ID = strconv.Itoa(len(a.events) + 1)
+	a.events = append(a.events, event)
+	if err := utils.WriteJSONFile(constant.AUDIT_FILE, a.events); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (a *auditRepository) FindAll() ([]models.AuditEvent, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return append([]models.AuditEvent{}, a.events...), nil
+}
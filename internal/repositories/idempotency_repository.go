@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"go-json/constant"
+	"go-json/internal/models"
+	"go-json/utils"
+	"sync"
+	"time"
+)
+
+type IdempotencyRepository interface {
+	Reserve(record models.IdempotencyRecord) (*models.IdempotencyRecord, bool)
+	Complete(record models.IdempotencyRecord) error
+	Release(scope, key string)
+	PruneExpired() error
+}
+
+type idempotencyRepository struct {
+	records []models.IdempotencyRecord
+	pending map[string]models.IdempotencyRecord
+	mu      sync.Mutex
+}
+
+func NewIdempotencyRepository(records []models.IdempotencyRecord) IdempotencyRepository {
+	return &idempotencyRepository{
+		records: records,
+		pending: map[string]models.IdempotencyRecord{},
+		mu:      sync.Mutex{},
+	}
+}
+
+func pendingKey(scope, key string) string {
+	return scope + "\x00" + key
+}
+
+// Reserve mengembalikan record yang sudah ada (selesai atau sedang diproses) dan false,
+// atau menandai key sebagai sedang diproses dan mengembalikan true.
+func (r *idempotencyRepository) Reserve(record models.IdempotencyRecord) (*models.IdempotencyRecord, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, existing := range r.records {
+		if existing.Scope == record.Scope && existing.Key == record.Key && now.Before(existing.ExpiresAt) {
+			existingCopy := existing
+			return &existingCopy, false
+		}
+	}
+
+	if existing, ok := r.pending[pendingKey(record.Scope, record.Key)]; ok {
+		return &existing, false
+	}
+
+	r.pending[pendingKey(record.Scope, record.Key)] = record
+	return nil, true
+}
+
+func (r *idempotencyRepository) Complete(record models.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.pending, pendingKey(record.Scope, record.Key))
+
+	now := time.Now()
+	records := []models.IdempotencyRecord{}
+	for _, existing := range r.records {
+		if now.Before(existing.ExpiresAt) && !(existing.Scope == record.Scope && existing.Key == record.Key) {
+			records = append(records, existing)
+		}
+	}
+	r.records = append(records, record)
+	return utils.WriteJSONFile(constant.IDEMPOTENCY_FILE, r.records)
+}
+
+func (r *idempotencyRepository) Release(scope, key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, pendingKey(scope, key))
+}
+
+func (r *idempotencyRepository) PruneExpired() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	records := []models.IdempotencyRecord{}
+	for _, existing := range r.records {
+		if now.Before(existing.ExpiresAt) {
+			records = append(records, existing)
+		}
+	}
+	if len(records) == len(r.records) {
+		return nil
+	}
+	r.records = records
+	return utils.WriteJSONFile(constant.IDEMPOTENCY_FILE, r.records)
+}
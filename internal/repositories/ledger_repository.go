@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"errors"
+	"go-json/constant"
+	"go-json/internal/models"
+	"go-json/utils"
+	"strconv"
+	"sync"
+)
+
+type LedgerRepository interface {
+	CreateAccount(account models.LedgerAccount) (*models.LedgerAccount, error)
+	FindAccountByID(id string) (*models.LedgerAccount, error)
+	FindAccountByUserID(userID string, accountType models.AccountType, currency models.Currency) (*models.LedgerAccount, error)
+	FindAccountsByUserID(userID string, accountType models.AccountType) ([]models.LedgerAccount, error)
+	FindSystemAccount(accountType models.AccountType, currency models.Currency) (*models.LedgerAccount, error)
+	CreateJournalEntry(entry models.JournalEntry) (*models.JournalEntry, error)
+	FindJournalEntryByReference(reference string) (*models.JournalEntry, error)
+	FindAllJournalEntries() ([]models.JournalEntry, error)
+	AccountBalance(accountID string) (models.Money, error)
+}
+
+type ledgerRepository struct {
+	accounts []models.LedgerAccount
+	entries  []models.JournalEntry
+	mu       sync.RWMutex
+}
+
+func NewLedgerRepository(accounts []models.LedgerAccount, entries []models.JournalEntry) LedgerRepository {
+	return &ledgerRepository{
+		accounts: accounts,
+		entries:  entries,
+		mu:       sync.RWMutex{},
+	}
+}
+
+func (r *ledgerRepository) CreateAccount(account models.LedgerAccount) (*models.LedgerAccount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.accounts {
+		if existing.UserID == account.UserID && existing.Type == account.Type && existing.AccountCurrency() == account.AccountCurrency() {
+			return nil, errors.New("ledger account already exists")
+		}
+	}
+
+	account.ID = strconv.Itoa(len(r.accounts) + 1)
+	r.accounts = append(r.accounts, account)
+	if err := utils.WriteJSONFile(constant.LEDGER_ACCOUNT_FILE, r.accounts); err != nil {
+		r.accounts = r.accounts[:len(r.accounts)-1]
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *ledgerRepository) FindAccountByID(id string) (*models.LedgerAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, account := range r.accounts {
+		if account.ID == id {
+			accountCopy := account
+			return &accountCopy, nil
+		}
+	}
+	return nil, errors.New("ledger account not found")
+}
+
+func (r *ledgerRepository) FindAccountByUserID(userID string, accountType models.AccountType, currency models.Currency) (*models.LedgerAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, account := range r.accounts {
+		if account.UserID == userID && account.Type == accountType && account.AccountCurrency() == currency {
+			accountCopy := account
+			return &accountCopy, nil
+		}
+	}
+	return nil, errors.New("ledger account not found")
+}
+
+func (r *ledgerRepository) FindAccountsByUserID(userID string, accountType models.AccountType) ([]models.LedgerAccount, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	accounts := []models.LedgerAccount{}
+	for _, account := range r.accounts {
+		if account.UserID == userID && account.Type == accountType {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+func (r *ledgerRepository) FindSystemAccount(accountType models.AccountType, currency models.Currency) (*models.LedgerAccount, error) {
+	return r.FindAccountByUserID("", accountType, currency)
+}
+
+func (r *ledgerRepository) CreateJournalEntry(entry models.JournalEntry) (*models.JournalEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry.Reference != "" {
+		for _, existing := range r.entries {
+			if existing.Reference == entry.Reference {
+				return nil, errors.New("journal entry reference already exists")
+			}
+		}
+	}
+
+	entry.ID = strconv.Itoa(len(r.entries) + 1)
+	r.entries = append(r.entries, entry)
+	if err := utils.WriteJSONFile(constant.JOURNAL_ENTRY_FILE, r.entries); err != nil {
+		r.entries = r.entries[:len(r.entries)-1]
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *ledgerRepository) FindJournalEntryByReference(reference string) (*models.JournalEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if reference != "" {
+		for _, entry := range r.entries {
+			if entry.Reference == reference {
+				entryCopy := entry
+				return &entryCopy, nil
+			}
+		}
+	}
+	return nil, errors.New("journal entry not found")
+}
+
+func (r *ledgerRepository) FindAllJournalEntries() ([]models.JournalEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]models.JournalEntry(nil), r.entries...), nil
+}
+
+func (r *ledgerRepository) AccountBalance(accountID string) (models.Money, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	currency := models.DefaultCurrency
+	for _, account := range r.accounts {
+		if account.ID == accountID {
+			currency = account.AccountCurrency()
+		}
+	}
+
+	balance := models.NewMoney(0, currency)
+	for _, entry := range r.entries {
+		for _, posting := range entry.Postings {
+			if posting.AccountID != accountID {
+				continue
+			}
+
+			var err error
+			if posting.Direction == models.Credit {
+				balance, err = balance.Add(posting.Amount)
+			} else {
+				balance, err = balance.Sub(posting.Amount)
+			}
+			if err != nil {
+				return models.Money{}, err
+			}
+		}
+	}
+	return balance, nil
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"errors"
+	"go-json/constant"
+	"go-json/internal/models"
+	"go-json/utils"
+	"sync"
+	"time"
+)
+
+type LoginAttemptRepository interface {
+	Find(kind models.LoginAttemptKind, key string) (*models.LoginAttempt, error)
+	FindAll() ([]models.LoginAttempt, error)
+	Save(attempt models.LoginAttempt) error
+	Delete(kind models.LoginAttemptKind, key string) error
+	PruneBefore(cutoff time.Time) error
+}
+
+type loginAttemptRepository struct {
+	attempts []models.LoginAttempt
+	mu       sync.RWMutex
+}
+
+func NewLoginAttemptRepository(attempts []models.LoginAttempt) LoginAttemptRepository {
+	return &loginAttemptRepository{
+		attempts: attempts,
+		mu:       sync.RWMutex{},
+	}
+}
+
+func (r *loginAttemptRepository) Find(kind models.LoginAttemptKind, key string) (*models.LoginAttempt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, attempt := range r.attempts {
+		if attempt.Kind == kind && attempt.Key == key {
+			attemptCopy := attempt
+			return &attemptCopy, nil
+		}
+	}
+	return nil, errors.New("login attempt not found")
+}
+
+func (r *loginAttemptRepository) FindAll() ([]models.LoginAttempt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]models.LoginAttempt{}, r.attempts...), nil
+}
+
+func (r *loginAttemptRepository) Save(attempt models.LoginAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.attempts {
+		if existing.Kind == attempt.Kind && existing.Key == attempt.Key {
+			r.attempts[i] = attempt
+			return utils.WriteJSONFile(constant.LOGIN_ATTEMPT_FILE, r.attempts)
+		}
+	}
+	r.attempts = append(r.attempts, attempt)
+	return utils.WriteJSONFile(constant.LOGIN_ATTEMPT_FILE, r.attempts)
+}
+
+func (r *loginAttemptRepository) Delete(kind models.LoginAttemptKind, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	attempts := []models.LoginAttempt{}
+	for _, existing := range r.attempts {
+		if existing.Kind != kind || existing.Key != key {
+			attempts = append(attempts, existing)
+		}
+	}
+	if len(attempts) == len(r.attempts) {
+		return nil
+	}
+	r.attempts = attempts
+	return utils.WriteJSONFile(constant.LOGIN_ATTEMPT_FILE, r.attempts)
+}
+
+// PruneBefore menghapus catatan yang gagal terakhir sebelum cutoff dan tidak sedang terkunci
+func (r *loginAttemptRepository) PruneBefore(cutoff time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	attempts := []models.LoginAttempt{}
+	for _, existing := range r.attempts {
+		if existing.LastFailureAt.After(cutoff) || existing.IsLockedAt(now) {
+			attempts = append(attempts, existing)
+		}
+	}
+	if len(attempts) == len(r.attempts) {
+		return nil
+	}
+	r.attempts = attempts
+	return utils.WriteJSONFile(constant.LOGIN_ATTEMPT_FILE, r.attempts)
+}
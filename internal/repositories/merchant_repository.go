@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"errors"
+	"go-json/constant"
+	"go-json/internal/models"
+	"go-json/utils"
+	"slices"
+	"sync"
+)
+
+type MerchantRepository interface {
+	Create(merchant models.Merchant) (*models.Merchant, error)
+	FindByID(id string) (*models.Merchant, error)
+	FindByOwnerID(userID string) ([]models.Merchant, error)
+	FindAll() ([]models.Merchant, error)
+	Update(merchant models.Merchant) error
+}
+
+type merchantRepository struct {
+	merchants []models.Merchant
+	mu        sync.RWMutex
+}
+
+func NewMerchantRepository(merchants []models.Merchant) MerchantRepository {
+	return &merchantRepository{
+		merchants: merchants,
+		mu:        sync.RWMutex{},
+	}
+}
+
+// Create memakai ID dari merchant.ID, yaitu ID user pemilik wallet; satu user hanya bisa punya satu merchant
+func (r *merchantRepository) Create(merchant models.Merchant) (*models.Merchant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.merchants {
+		if existing.ID == merchant.ID {
+			return nil, errors.New("merchant already exists")
+		}
+	}
+	r.merchants = append(r.merchants, merchant)
+	if err := utils.WriteJSONFile(constant.MERCHANT_FILE, r.merchants); err != nil {
+		return nil, err
+	}
+	return copyMerchant(merchant), nil
+}
+
+func (r *merchantRepository) FindByID(id string) (*models.Merchant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, merchant := range r.merchants {
+		if merchant.ID == id {
+			return copyMerchant(merchant), nil
+		}
+	}
+	return nil, errors.New("merchant not found")
+}
+
+func (r *merchantRepository) FindByOwnerID(userID string) ([]models.Merchant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	merchants := []models.Merchant{}
+	for _, merchant := range r.merchants {
+		if merchant.IsOwnedBy(userID) {
+			merchants = append(merchants, *copyMerchant(merchant))
+		}
+	}
+	return merchants, nil
+}
+
+func (r *merchantRepository) FindAll() ([]models.Merchant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	merchants := []models.Merchant{}
+	for _, merchant := range r.merchants {
+		merchants = append(merchants, *copyMerchant(merchant))
+	}
+	return merchants, nil
+}
+
+func (r *merchantRepository) Update(merchant models.Merchant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.merchants {
+		if existing.ID == merchant.ID {
+			r.merchants[i] = merchant
+			return utils.WriteJSONFile(constant.MERCHANT_FILE, r.merchants)
+		}
+	}
+	return errors.New("merchant not found")
+}
+
+func copyMerchant(merchant models.Merchant) *models.Merchant {
+	merchant.OwnerIDs = slices.Clone(merchant.OwnerIDs)
+	return &merchant
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"errors"
+	"go-json/constant"
+	"go-json/internal/models"
+	"go-json/utils"
+	"sync"
+	"time"
+)
+
+type OneTimeTokenRepository interface {
+	Create(token models.OneTimeToken) error
+	Consume(purpose models.OneTimeTokenPurpose, tokenHash string) (*models.OneTimeToken, error)
+	FindLatestByUserID(userID string, purpose models.OneTimeTokenPurpose) (*models.OneTimeToken, error)
+	DeleteByUserID(userID string, purpose models.OneTimeTokenPurpose) error
+	PruneExpired() error
+}
+
+type oneTimeTokenRepository struct {
+	tokens []models.OneTimeToken
+	mu     sync.RWMutex
+}
+
+func NewOneTimeTokenRepository(tokens []models.OneTimeToken) OneTimeTokenRepository {
+	return &oneTimeTokenRepository{
+		tokens: tokens,
+		mu:     sync.RWMutex{},
+	}
+}
+
+func (r *oneTimeTokenRepository) Create(token models.OneTimeToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens = append(r.tokens, token)
+	return utils.WriteJSONFile(constant.ONE_TIME_TOKEN_FILE, r.tokens)
+}
+
+// Consume menandai token sebagai terpakai. Pencarian dan penandaan terjadi di bawah
+// lock yang sama, sehingga dua request dengan token yang sama tidak bisa sama-sama berhasil.
+func (r *oneTimeTokenRepository) Consume(purpose models.OneTimeTokenPurpose, tokenHash string) (*models.OneTimeToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for i, token := range r.tokens {
+		if token.Purpose != purpose || token.TokenHash != tokenHash {
+			continue
+		}
+		if !token.IsUsableAt(now) {
+			return nil, errors.New("token has expired or was already used")
+		}
+		r.tokens[i].UsedAt = &now
+		if err := utils.WriteJSONFile(constant.ONE_TIME_TOKEN_FILE, r.tokens); err != nil {
+			return nil, err
+		}
+		consumed := r.tokens[i]
+		return &consumed, nil
+	}
+	return nil, errors.New("token not found")
+}
+
+func (r *oneTimeTokenRepository) FindLatestByUserID(userID string, purpose models.OneTimeTokenPurpose) (*models.OneTimeToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var latest *models.OneTimeToken
+	for _, token := range r.tokens {
+		if token.UserID == userID && token.Purpose == purpose && (latest == nil || token.CreatedAt.After(latest.CreatedAt)) {
+			tokenCopy := token
+			latest = &tokenCopy
+		}
+	}
+	if latest == nil {
+		return nil, errors.New("token not found")
+	}
+	return latest, nil
+}
+
+// DeleteByUserID membuang token user untuk purpose tertentu, misalnya link reset lama
+// saat user meminta link baru.
+func (r *oneTimeTokenRepository) DeleteByUserID(userID string, purpose models.OneTimeTokenPurpose) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tokens := []models.OneTimeToken{}
+	for _, token := range r.tokens {
+		if token.UserID != userID || token.Purpose != purpose {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) == len(r.tokens) {
+		return nil
+	}
+	r.tokens = tokens
+	return utils.WriteJSONFile(constant.ONE_TIME_TOKEN_FILE, r.tokens)
+}
+
+// PruneExpired membuang token yang sudah kedaluwarsa atau sudah dipakai
+func (r *oneTimeTokenRepository) PruneExpired() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	tokens := []models.OneTimeToken{}
+	for _, token := range r.tokens {
+		if token.IsUsableAt(now) {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) == len(r.tokens) {
+		return nil
+	}
+	r.tokens = tokens
+	return utils.WriteJSONFile(constant.ONE_TIME_TOKEN_FILE, r.tokens)
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"errors"
+	"go-json/constant"
+	"go-json/internal/models"
+	"go-json/utils"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(token models.RefreshToken) (*models.RefreshToken, error)
+	FindByTokenHash(tokenHash string) (*models.RefreshToken, error)
+	Consume(tokenHash string) (*models.RefreshToken, error)
+	RevokeFamily(familyID string) error
+	RevokeByUserID(userID string) error
+	RevokeOtherFamilies(userID, keepFamilyID string) error
+}
+
+type refreshTokenRepository struct {
+	tokens []models.RefreshToken
+	mu     sync.RWMutex
+}
+
+func NewRefreshTokenRepository(tokens []models.RefreshToken) RefreshTokenRepository {
+	return &refreshTokenRepository{
+		tokens: tokens,
+		mu:     sync.RWMutex{},
+	}
+}
+
+func (r *refreshTokenRepository) CreateRefreshToken(token models.RefreshToken) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = strconv.Itoa(len(r.tokens) + 1)
+	if token.FamilyID == "" {
+		token.FamilyID = token.ID
+	}
+	r.tokens = append(r.tokens, token)
+	if err := utils.WriteJSONFile(constant.REFRESH_TOKEN_FILE, r.tokens); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) FindByTokenHash(tokenHash string) (*models.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			tokenCopy := token
+			return &tokenCopy, nil
+		}
+	}
+	return nil, errors.New("refresh token not found")
+}
+
+// Consume menandai refresh token sebagai terpakai. Pencarian, pengecekan, dan penandaan terjadi
+// di bawah lock yang sama, sehingga satu token hanya bisa ditukar sekali. Token yang sudah terpakai
+// dianggap dicuri: seluruh family-nya dicabut, lalu token itu dikembalikan bersama ErrRefreshTokenReused
+// agar pemanggil bisa mencabut access token dari sesi yang sama.
+func (r *refreshTokenRepository) Consume(tokenHash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for i, token := range r.tokens {
+		if token.TokenHash != tokenHash {
+			continue
+		}
+		if token.RevokedAt != nil {
+			return nil, models.ErrRefreshTokenRevoked
+		}
+		if token.UsedAt != nil {
+			r.revokeFamilyLocked(token.FamilyID, now)
+			if err := utils.WriteJSONFile(constant.REFRESH_TOKEN_FILE, r.tokens); err != nil {
+				return nil, err
+			}
+			reused := r.tokens[i]
+			return &reused, models.ErrRefreshTokenReused
+		}
+		if now.After(token.ExpiresAt) {
+			return nil, models.ErrRefreshTokenExpired
+		}
+		r.tokens[i].UsedAt = &now
+		if err := utils.WriteJSONFile(constant.REFRESH_TOKEN_FILE, r.tokens); err != nil {
+			return nil, err
+		}
+		consumed := r.tokens[i]
+		return &consumed, nil
+	}
+	return nil, models.ErrRefreshTokenNotFound
+}
+
+func (r *refreshTokenRepository) RevokeFamily(familyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.revokeFamilyLocked(familyID, time.Now())
+	return utils.WriteJSONFile(constant.REFRESH_TOKEN_FILE, r.tokens)
+}
+
+// revokeFamilyLocked harus dipanggil saat r.mu sudah di-lock
+func (r *refreshTokenRepository) revokeFamilyLocked(familyID string, now time.Time) {
+	for i, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			r.tokens[i].RevokedAt = &now
+		}
+	}
+}
+
+func (r *refreshTokenRepository) RevokeByUserID(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for i, token := range r.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			r.tokens[i].RevokedAt = &now
+		}
+	}
+
+	return utils.WriteJSONFile(constant.REFRESH_TOKEN_FILE, r.tokens)
+}
+
+// RevokeOtherFamilies mencabut semua sesi user kecuali family keepFamilyID
+func (r *refreshTokenRepository) RevokeOtherFamilies(userID, keepFamilyID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for i, token := range r.tokens {
+		if token.UserID == userID && token.FamilyID != keepFamilyID && token.RevokedAt == nil {
+			r.tokens[i].RevokedAt = &now
+		}
+	}
+
+	return utils.WriteJSONFile(constant.REFRESH_TOKEN_FILE, r.tokens)
+}
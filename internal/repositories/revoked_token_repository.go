@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"go-json/constant"
+	"go-json/internal/models"
+	"go-json/utils"
+	"sync"
+	"time"
+)
+
+type RevokedTokenRepository interface {
+	Revoke(token models.RevokedToken) error
+	IsRevoked(jti, userID, sessionID string, issuedAt time.Time) bool
+	PruneExpired() error
+}
+
+type revokedTokenRepository struct {
+	tokens []models.RevokedToken
+	mu     sync.RWMutex
+}
+
+func NewRevokedTokenRepository(tokens []models.RevokedToken) RevokedTokenRepository {
+	return &revokedTokenRepository{
+		tokens: tokens,
+		mu:     sync.RWMutex{},
+	}
+}
+
+func (r *revokedTokenRepository) Revoke(token models.RevokedToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens = append(activeRevocations(r.tokens, time.Now()), token)
+	return utils.WriteJSONFile(constant.REVOKED_TOKEN_FILE, r.tokens)
+}
+
+func (r *revokedTokenRepository) IsRevoked(jti, userID, sessionID string, issuedAt time.Time) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	for _, token := range r.tokens {
+		if now.After(token.ExpiresAt) {
+			continue
+		}
+		if token.JTI != "" && token.JTI == jti {
+			return true
+		}
+		if token.JTI == "" && token.SessionID != "" {
+			if token.SessionID == sessionID {
+				return true
+			}
+			continue
+		}
+		// iat pada JWT hanya sampai detik, sehingga token yang terbit di detik yang sama dengan
+		// pencabutan (misalnya login ulang tepat setelah logout-all) dianggap terbit sesudahnya
+		if token.JTI == "" && token.UserID == userID && issuedAt.Before(token.RevokedAt.Truncate(time.Second)) &&
+			(token.ExceptSessionID == "" || token.ExceptSessionID != sessionID) {
+			return true
+		}
+	}
+	return false
+}
+
+// PruneExpired membuang entri yang token-nya sudah kedaluwarsa dengan sendirinya
+func (r *revokedTokenRepository) PruneExpired() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	active := activeRevocations(r.tokens, time.Now())
+	if len(active) == len(r.tokens) {
+		return nil
+	}
+	r.tokens = active
+	return utils.WriteJSONFile(constant.REVOKED_TOKEN_FILE, r.tokens)
+}
+
+func activeRevocations(tokens []models.RevokedToken, now time.Time) []models.RevokedToken {
+	active := []models.RevokedToken{}
+	for _, token := range tokens {
+		if now.Before(token.ExpiresAt) {
+			active = append(active, token)
+		}
+	}
+	return active
+}
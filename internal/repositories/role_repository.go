@@ -3,14 +3,24 @@ package repositories
 import (
 	"errors"
 	"fmt"
+	"go-json/constant"
 	"go-json/internal/models"
+	"go-json/utils"
+	"slices"
+	"strconv"
 	"sync"
 )
 
 type RoleRepository interface {
+	FindAll() ([]models.Role, error)
 	FindByRoleName(role string) (*models.Role, error)
 	FindByRoleID(roleID string) (*models.Role, error)
 	FindRoleByUserID(userID string) (*[]models.UserRole, error)
+	AssignRoles(userID string, roleIDs []string) error
+	RemoveRole(userID, roleID string) error
+	CreateRole(role models.Role) (*models.Role, error)
+	UpdateRole(role models.Role) error
+	PermissionsForRoles(roleNames []string) []string
 }
 
 type roleRepository struct {
@@ -20,6 +30,11 @@ type roleRepository struct {
 }
 
 func NewRoleRepository(roles []models.Role, userRole []models.UserRole) RoleRepository {
+	for i := range roles {
+		if roles[i].Permissions == nil {
+			roles[i].Permissions = slices.Clone(models.DefaultRolePermissions[roles[i].Name])
+		}
+	}
 	return &roleRepository{
 		roles:     roles,
 		userRoles: userRole,
@@ -27,6 +42,18 @@ func NewRoleRepository(roles []models.Role, userRole []models.UserRole) RoleRepo
 	}
 }
 
+func (r *roleRepository) FindAll() ([]models.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roles := make([]models.Role, len(r.roles))
+	for i, role := range r.roles {
+		role.Permissions = slices.Clone(role.Permissions)
+		roles[i] = role
+	}
+	return roles, nil
+}
+
 func (r *roleRepository) FindByRoleName(role string) (*models.Role, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -34,6 +61,7 @@ func (r *roleRepository) FindByRoleName(role string) (*models.Role, error) {
 	for _, r := range r.roles {
 		if r.Name == role {
 			roleCopy := r
+			roleCopy.Permissions = slices.Clone(r.Permissions)
 			return &roleCopy, nil
 		}
 	}
@@ -47,6 +75,7 @@ func (r *roleRepository) FindByRoleID(roleID string) (*models.Role, error) {
 	for _, r := range r.roles {
 		if r.ID == roleID {
 			roleCopy := r
+			roleCopy.Permissions = slices.Clone(r.Permissions)
 			return &roleCopy, nil
 		}
 	}
@@ -71,3 +100,85 @@ func (r *roleRepository) FindRoleByUserID(userID string) (*[]models.UserRole, er
 
 	return &userRoles, nil
 }
+
+func (r *roleRepository) AssignRoles(userID string, roleIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, roleID := range roleIDs {
+		r.userRoles = append(r.userRoles, models.UserRole{
+			ID:     strconv.Itoa(len(r.userRoles) + 1),
+			UserID: userID,
+			RoleID: roleID,
+		})
+	}
+
+	return utils.WriteJSONFile(constant.USER_ROLE_FILE, r.userRoles)
+}
+
+func (r *roleRepository) RemoveRole(userID, roleID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	userRoles := []models.UserRole{}
+	for _, ur := range r.userRoles {
+		if ur.UserID != userID || ur.RoleID != roleID {
+			userRoles = append(userRoles, ur)
+		}
+	}
+	if len(userRoles) == len(r.userRoles) {
+		return errors.New("user does not have this role")
+	}
+	r.userRoles = userRoles
+	return utils.WriteJSONFile(constant.USER_ROLE_FILE, r.userRoles)
+}
+
+func (r *roleRepository) CreateRole(role models.Role) (*models.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.roles {
+		if existing.Name == role.Name {
+			return nil, errors.New("role already exists")
+		}
+	}
+	role.ID = strconv.Itoa(len(r.roles) + 1)
+	r.roles = append(r.roles, role)
+	if err := utils.WriteJSONFile(constant.ROLE_FILE, r.roles); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) UpdateRole(role models.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.roles {
+		if existing.ID == role.ID {
+			r.roles[i] = role
+			return utils.WriteJSONFile(constant.ROLE_FILE, r.roles)
+		}
+	}
+	return errors.New("role not found")
+}
+
+// PermissionsForRoles menggabungkan permission dari semua role dengan nama yang diberikan.
+// Nama role yang tidak dikenal diabaikan.
+func (r *roleRepository) PermissionsForRoles(roleNames []string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	permissions := []string{}
+	for _, role := range r.roles {
+		if !slices.Contains(roleNames, role.Name) {
+			continue
+		}
+		for _, permission := range role.Permissions {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"errors"
 	"go-json/constant"
 	"go-json/internal/models"
 	"go-json/utils"
@@ -11,6 +12,8 @@ import (
 type TransactionRepository interface {
 	CreateTransaction(transaction models.Transaction) (*models.Transaction, error)
 	FindAllTransaction() ([]models.Transaction, error)
+	FindByID(id string) (*models.Transaction, error)
+	UpdateTransaction(transaction models.Transaction) error
 }
 
 type transactionRepository struct {
@@ -41,5 +44,29 @@ func (t *transactionRepository) CreateTransaction(transaction models.Transaction
 func (t *transactionRepository) FindAllTransaction() ([]models.Transaction, error) {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
-	return t.transactions, nil
+	return append([]models.Transaction(nil), t.transactions...), nil
+}
+
+func (t *transactionRepository) FindByID(id string) (*models.Transaction, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, transaction := range t.transactions {
+		if transaction.ID == id {
+			transactionCopy := transaction
+			return &transactionCopy, nil
+		}
+	}
+	return nil, errors.New("transaction not found")
+}
+
+func (t *transactionRepository) UpdateTransaction(transaction models.Transaction) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := range t.transactions {
+		if t.transactions[i].ID == transaction.ID {
+			t.transactions[i] = transaction
+			return utils.WriteJSONFile(constant.TRANSACTION_FILE, t.transactions)
+		}
+	}
+	return errors.New("transaction not found")
 }
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"errors"
+	"go-json/constant"
+	"go-json/internal/models"
+	"go-json/utils"
+	"slices"
+	"sync"
+)
+
+type TwoFactorRepository interface {
+	FindByUserID(userID string) (*models.TwoFactor, error)
+	Save(twoFactor models.TwoFactor) error
+	Delete(userID string) error
+}
+
+type twoFactorRepository struct {
+	twoFactors []models.TwoFactor
+	mu         sync.RWMutex
+}
+
+func NewTwoFactorRepository(twoFactors []models.TwoFactor) TwoFactorRepository {
+	return &twoFactorRepository{
+		twoFactors: twoFactors,
+		mu:         sync.RWMutex{},
+	}
+}
+
+func (r *twoFactorRepository) FindByUserID(userID string) (*models.TwoFactor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, twoFactor := range r.twoFactors {
+		if twoFactor.UserID == userID {
+			twoFactorCopy := twoFactor
+			twoFactorCopy.RecoveryCodeHashes = slices.Clone(twoFactor.RecoveryCodeHashes)
+			return &twoFactorCopy, nil
+		}
+	}
+	return nil, errors.New("two-factor authentication not set up")
+}
+
+func (r *twoFactorRepository) Save(twoFactor models.TwoFactor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.twoFactors {
+		if existing.UserID == twoFactor.UserID {
+			r.twoFactors[i] = twoFactor
+			return utils.WriteJSONFile(constant.TWO_FACTOR_FILE, r.twoFactors)
+		}
+	}
+	r.twoFactors = append(r.twoFactors, twoFactor)
+	return utils.WriteJSONFile(constant.TWO_FACTOR_FILE, r.twoFactors)
+}
+
+func (r *twoFactorRepository) Delete(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	twoFactors := []models.TwoFactor{}
+	for _, existing := range r.twoFactors {
+		if existing.UserID != userID {
+			twoFactors = append(twoFactors, existing)
+		}
+	}
+	if len(twoFactors) == len(r.twoFactors) {
+		return nil
+	}
+	r.twoFactors = twoFactors
+	return utils.WriteJSONFile(constant.TWO_FACTOR_FILE, r.twoFactors)
+}
@@ -7,6 +7,7 @@ import (
 	"go-json/utils"
 	"strconv"
 	"sync"
+	"time"
 )
 
 type UserRepository interface {
@@ -15,30 +16,32 @@ type UserRepository interface {
 	FindByID(id string) (*models.User, error)
 	CreateUser(User models.User, roleID []string) (*models.User, error)
 	UpdateUser(User models.User) error
+	// Update* berikut hanya mengubah field miliknya di bawah lock, sehingga tidak menimpa
+	// perubahan lain sejak user dibaca, misalnya status dari admin atau saldo dari ledger.
+	UpdateLastLogin(id string, at time.Time) error
+	UpdatePassword(id, hashedPassword string) error
+	UpdateStatus(id string, status models.AccountStatus, reason string, at time.Time) error
+	MarkEmailVerified(id string, at time.Time) error
+	UpdateTransferLimits(id string, limits *models.TransferLimits) error
+	UpdateBalances(id string, balance models.Money, wallets, held []models.Money) error
 	FindAll() ([]models.User, error)
 }
 
 type userRepository struct {
-	Users     []models.User
-	roles     []models.Role
-	userRoles []models.UserRole
-	mu        sync.RWMutex
+	Users    []models.User
+	roleRepo RoleRepository
+	mu       sync.RWMutex
 }
 
-func NewUserRepository(users []models.User, roles []models.Role, userRoles []models.UserRole) UserRepository {
+func NewUserRepository(users []models.User, roleRepo RoleRepository) UserRepository {
 	return &userRepository{
-		Users:     users,
-		roles:     roles,
-		userRoles: userRoles,
-		mu:        sync.RWMutex{},
+		Users:    users,
+		roleRepo: roleRepo,
+		mu:       sync.RWMutex{},
 	}
 }
 
 func (r *userRepository) FindByUsername(username string) (*models.User, error) {
-	err := utils.ReadJSONFile(constant.USER_FILE, &r.Users)
-	if err != nil {
-		return nil, err
-	}
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
@@ -93,36 +96,25 @@ func (r *userRepository) CreateUser(User models.User, roleIDs []string) (*models
 	}
 	newID := strconv.Itoa(len(r.Users) + 1)
 	User.ID = newID
-	User.Balance = 1000000.0
-	User.IsActive = false
-
-	var userRoles []models.UserRole
-	roleFound := false
+	User.Status = models.AccountPending
 
+	var validRoleIDs []string
 	for _, roleID := range roleIDs {
-		for _, role := range r.roles {
-			if role.ID == roleID {
-				roleFound = true
-				userRoles = append(userRoles, models.UserRole{
-					ID:     strconv.Itoa(len(r.userRoles) + 1),
-					UserID: User.ID,
-					RoleID: roleID,
-				})
-			}
+		if _, err := r.roleRepo.FindByRoleID(roleID); err == nil {
+			validRoleIDs = append(validRoleIDs, roleID)
 		}
 	}
 
-	if !roleFound {
+	if len(validRoleIDs) == 0 {
 		return nil, errors.New("invalid role ID")
 	}
 	r.Users = append(r.Users, User)
-	r.userRoles = append(r.userRoles, userRoles...)
 
 	if err := utils.WriteJSONFile(constant.USER_FILE, r.Users); err != nil {
 		return nil, err
 	}
 
-	if err := utils.WriteJSONFile(constant.USER_ROLE_FILE, r.userRoles); err != nil {
+	if err := r.roleRepo.AssignRoles(User.ID, validRoleIDs); err != nil {
 		return nil, err
 	}
 
@@ -153,6 +145,63 @@ func (r *userRepository) UpdateUser(User models.User) error {
 	return nil
 }
 
+func (r *userRepository) UpdateLastLogin(id string, at time.Time) error {
+	return r.update(id, func(user *models.User) {
+		user.LastLoginAt = &at
+	})
+}
+
+func (r *userRepository) UpdatePassword(id, hashedPassword string) error {
+	return r.update(id, func(user *models.User) {
+		user.Password = hashedPassword
+	})
+}
+
+func (r *userRepository) UpdateStatus(id string, status models.AccountStatus, reason string, at time.Time) error {
+	return r.update(id, func(user *models.User) {
+		user.Status = status
+		user.StatusReason = reason
+		user.StatusChangedAt = &at
+	})
+}
+
+// MarkEmailVerified mengaktifkan akun hanya bila statusnya masih PENDING saat ditulis
+func (r *userRepository) MarkEmailVerified(id string, at time.Time) error {
+	return r.update(id, func(user *models.User) {
+		user.EmailVerifiedAt = &at
+		if user.AccountStatus() == models.AccountPending {
+			user.Status = models.AccountActive
+		}
+	})
+}
+
+func (r *userRepository) UpdateTransferLimits(id string, limits *models.TransferLimits) error {
+	return r.update(id, func(user *models.User) {
+		user.TransferLimits = limits
+	})
+}
+
+func (r *userRepository) UpdateBalances(id string, balance models.Money, wallets, held []models.Money) error {
+	return r.update(id, func(user *models.User) {
+		user.Balance = balance
+		user.Wallets = wallets
+		user.Held = held
+	})
+}
+
+func (r *userRepository) update(id string, apply func(user *models.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.Users {
+		if r.Users[i].ID == id {
+			apply(&r.Users[i])
+			return utils.WriteJSONFile(constant.USER_FILE, r.Users)
+		}
+	}
+	return errors.New("user not found")
+}
+
 func (r *userRepository) FindAll() ([]models.User, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
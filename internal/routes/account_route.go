@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"go-json/internal/controllers"
+	"go-json/internal/middlewares"
+	"go-json/internal/models"
+	"go-json/internal/security"
+	"net/http"
+)
+
+func AccountRoutes(api controllers.AccountController, token security.TokenService, roles security.PermissionResolver) {
+	admin := R.PathPrefix("/admin").Subrouter()
+	admin.Handle("/users/{id}/status", middlewares.ProtectedHandler(http.HandlerFunc(api.ChangeStatus), token, roles, models.PermissionAccountManage)).Methods("PUT")
+	admin.Handle("/users/{id}/transfer-limits", middlewares.ProtectedHandler(http.HandlerFunc(api.SetTransferLimits), token, roles, models.PermissionAccountManage)).Methods("PUT")
+	admin.Handle("/users/{id}/transfer-limits", middlewares.ProtectedHandler(http.HandlerFunc(api.ResetTransferLimits), token, roles, models.PermissionAccountManage)).Methods("DELETE")
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"go-json/internal/controllers"
+	"go-json/internal/middlewares"
+	"go-json/internal/models"
+	"go-json/internal/security"
+	"net/http"
+)
+
+func APIKeyRoutes(api controllers.APIKeyController, token security.TokenService, roles security.PermissionResolver) {
+	apiKeys := R.PathPrefix("/auth/api-keys").Subrouter()
+	manage := func(handler http.HandlerFunc) http.Handler {
+		return middlewares.ProtectedHandler(handler, token, roles, models.PermissionAPIKeyManage)
+	}
+	apiKeys.Handle("", manage(api.KeyList)).Methods("GET")
+	apiKeys.Handle("", manage(api.CreateKey)).Methods("POST")
+	apiKeys.Handle("/{id}", manage(api.UpdateLabel)).Methods("PATCH")
+	apiKeys.Handle("/{id}", manage(api.RevokeKey)).Methods("DELETE")
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"go-json/internal/controllers"
+	"go-json/internal/middlewares"
+	"go-json/internal/security"
+	"net/http"
+)
+
+// Resend cukup terautentikasi; user yang belum terverifikasi tetap bisa login
+func EmailVerificationRoutes(api controllers.EmailVerificationController, token security.TokenService, roles security.PermissionResolver) {
+	email := R.PathPrefix("/auth/email").Subrouter()
+	email.HandleFunc("/verify", api.Verify).Methods("POST")
+	email.Handle("/resend", middlewares.ProtectedHandler(http.HandlerFunc(api.Resend), token, roles)).Methods("POST")
+}
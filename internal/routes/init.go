@@ -1,48 +1,32 @@
 package routes
 
 import (
-	"go-json/constant"
 	"go-json/internal/injection"
-	"go-json/internal/models"
-	"go-json/internal/security"
-	"go-json/utils"
-	"os"
+	"go-json/internal/middlewares"
 
 	"github.com/gorilla/mux"
 )
 
 var R = mux.NewRouter()
 
-func InitRoute() {
-	secret := []byte(os.Getenv("JWT_SECRET"))
-	token := security.NewTokenService(secret)
-	var customers []models.User
-	err := utils.ReadJSONFile(constant.USER_FILE, &customers)
+func InitRoute() error {
+	container, err := injection.NewContainer()
 	if err != nil {
-		customers = []models.User{}
+		return err
 	}
-
-	var roles []models.Role
-	err = utils.ReadJSONFile(constant.ROLE_FILE, &roles)
-	if err != nil {
-		roles = []models.Role{}
-	}
-
-	var userRoles []models.UserRole
-	err = utils.ReadJSONFile(constant.USER_ROLE_FILE, &userRoles)
-	if err != nil {
-		userRoles = []models.UserRole{}
-	}
-
-	var transactions []models.Transaction
-	err = utils.ReadJSONFile(constant.TRANSACTION_FILE, &transactions)
-	if err != nil {
-		transactions = []models.Transaction{}
-	}
-
-	customerApi := injection.InitUserAPI(customers, roles, userRoles, token)
-	UserRoutes(customerApi, token)
-
-	transactionApi := injection.InitTransactionAPI(transactions, customers, roles, userRoles)
-	TransactionRoutes(transactionApi, token)
+	container.StartBackgroundJobs()
+	R.Use(middlewares.RealIP(container.TrustProxyHeaders))
+	R.Use(middlewares.APIKeyAuth(container.APIKeyService))
+
+	UserRoutes(container.UserController, container.TokenService, container.RoleRepository)
+	TransactionRoutes(container.TransactionController, container.TokenService, container.RoleRepository, container.IdempotencyRepository, container.IdempotencyTTL)
+	RoleRoutes(container.RoleController, container.TokenService, container.RoleRepository)
+	LockoutRoutes(container.LockoutController, container.TokenService, container.RoleRepository)
+	TwoFactorRoutes(container.TwoFactorController, container.TokenService, container.RoleRepository)
+	PasswordRoutes(container.PasswordController, container.TokenService, container.RoleRepository)
+	EmailVerificationRoutes(container.EmailVerificationController, container.TokenService, container.RoleRepository)
+	AccountRoutes(container.AccountController, container.TokenService, container.RoleRepository)
+	APIKeyRoutes(container.APIKeyController, container.TokenService, container.RoleRepository)
+	MerchantRoutes(container.MerchantController, container.TokenService, container.RoleRepository)
+	return nil
 }
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"go-json/internal/controllers"
+	"go-json/internal/middlewares"
+	"go-json/internal/models"
+	"go-json/internal/security"
+	"net/http"
+)
+
+func LockoutRoutes(api controllers.LockoutController, token security.TokenService, roles security.PermissionResolver) {
+	admin := R.PathPrefix("/admin").Subrouter()
+	manage := func(handler http.HandlerFunc) http.Handler {
+		return middlewares.ProtectedHandler(handler, token, roles, models.PermissionLockoutManage)
+	}
+	admin.Handle("/lockouts", manage(api.LockoutList)).Methods("GET")
+	admin.Handle("/lockouts/ip/{ip}", manage(api.UnlockIP)).Methods("DELETE")
+	admin.Handle("/users/{id}/lockout", manage(api.UserLockout)).Methods("GET")
+	admin.Handle("/users/{id}/lockout", manage(api.UnlockUser)).Methods("DELETE")
+}
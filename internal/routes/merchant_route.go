@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"go-json/internal/controllers"
+	"go-json/internal/middlewares"
+	"go-json/internal/models"
+	"go-json/internal/security"
+	"net/http"
+)
+
+func MerchantRoutes(api controllers.MerchantController, token security.TokenService, roles security.PermissionResolver) {
+	merchants := R.PathPrefix("/merchants").Subrouter()
+	manage := func(handler http.HandlerFunc) http.Handler {
+		return middlewares.ProtectedHandler(handler, token, roles, models.PermissionMerchantManage)
+	}
+	merchants.Handle("", manage(api.OwnMerchantList)).Methods("GET")
+	merchants.Handle("", manage(api.Onboard)).Methods("POST")
+	merchants.Handle("/{id}", middlewares.ProtectedHandler(http.HandlerFunc(api.Merchant), token, roles, models.PermissionMerchantManage, models.PermissionMerchantReview)).Methods("GET")
+	merchants.Handle("/{id}", manage(api.UpdateMerchant)).Methods("PUT")
+
+	admin := R.PathPrefix("/admin/merchants").Subrouter()
+	review := func(handler http.HandlerFunc) http.Handler {
+		return middlewares.ProtectedHandler(handler, token, roles, models.PermissionMerchantReview)
+	}
+	admin.Handle("", review(api.MerchantList)).Methods("GET")
+	admin.Handle("/{id}/status", review(api.ChangeStatus)).Methods("PUT")
+	admin.Handle("/{id}/owners", review(api.AddOwner)).Methods("POST")
+	admin.Handle("/{id}/owners/{userId}", review(api.RemoveOwner)).Methods("DELETE")
+}
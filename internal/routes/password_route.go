@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"go-json/internal/controllers"
+	"go-json/internal/middlewares"
+	"go-json/internal/models"
+	"go-json/internal/security"
+	"net/http"
+)
+
+func PasswordRoutes(api controllers.PasswordController, token security.TokenService, roles security.PermissionResolver) {
+	password := R.PathPrefix("/auth/password").Subrouter()
+	password.Handle("/change", middlewares.ProtectedHandler(http.HandlerFunc(api.ChangePassword), token, roles, models.PermissionPasswordChange)).Methods("POST")
+	password.HandleFunc("/forgot", api.ForgotPassword).Methods("POST")
+	password.HandleFunc("/reset", api.ResetPassword).Methods("POST")
+}
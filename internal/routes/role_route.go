@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"go-json/internal/controllers"
+	"go-json/internal/middlewares"
+	"go-json/internal/models"
+	"go-json/internal/security"
+	"net/http"
+)
+
+func RoleRoutes(api controllers.RoleController, token security.TokenService, roles security.PermissionResolver) {
+	admin := R.PathPrefix("/admin").Subrouter()
+	manage := func(handler http.HandlerFunc) http.Handler {
+		return middlewares.ProtectedHandler(handler, token, roles, models.PermissionRoleManage)
+	}
+	admin.Handle("/roles", manage(api.RoleList)).Methods("GET")
+	admin.Handle("/roles", manage(api.CreateRole)).Methods("POST")
+	admin.Handle("/roles/{id}/permissions", manage(api.GrantPermissions)).Methods("POST")
+	admin.Handle("/roles/{id}/permissions/{permission}", manage(api.RevokePermission)).Methods("DELETE")
+	admin.Handle("/roles/{id}/two-factor", manage(api.SetTwoFactorRequired)).Methods("PUT")
+	admin.Handle("/users/{id}/roles", manage(api.AssignRole)).Methods("POST")
+	admin.Handle("/users/{id}/roles/{roleId}", manage(api.UnassignRole)).Methods("DELETE")
+}
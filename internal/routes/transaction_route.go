@@ -3,12 +3,35 @@ package routes
 import (
 	"go-json/internal/controllers"
 	"go-json/internal/middlewares"
+	"go-json/internal/models"
+	"go-json/internal/repositories"
 	"go-json/internal/security"
 	"net/http"
+	"time"
 )
 
-func TransactionRoutes(api controllers.TransactionController, token security.TokenService) {
+func TransactionRoutes(api controllers.TransactionController, token security.TokenService, roles security.PermissionResolver, idempotency repositories.IdempotencyRepository, idempotencyTTL time.Duration) {
 	transaction := R.PathPrefix("/trx").Subrouter()
-	transaction.Handle("/create", middlewares.ProtectedHandler(http.HandlerFunc(api.Payment), token, []string{"customer"})).Methods("POST")
-	transaction.Handle("/history/{id}", middlewares.ProtectedHandler(http.HandlerFunc(api.TransactionHistory), token, []string{"customer", "merchant"})).Methods("GET")
+	payment := middlewares.IdempotentHandler(http.HandlerFunc(api.Payment), idempotency, idempotencyTTL)
+	transaction.Handle("/create", middlewares.ProtectedHandler(payment, token, roles, models.PermissionPaymentCreate)).Methods("POST")
+	onBehalf := middlewares.IdempotentHandler(http.HandlerFunc(api.PaymentOnBehalf), idempotency, idempotencyTTL)
+	transaction.Handle("/create-on-behalf", middlewares.ProtectedHandler(onBehalf, token, roles, models.PermissionPaymentCreateOnBehalf)).Methods("POST")
+	refund := middlewares.IdempotentHandler(http.HandlerFunc(api.Refund), idempotency, idempotencyTTL)
+	transaction.Handle("/{id}/refund", middlewares.ProtectedHandler(refund, token, roles, models.PermissionRefundCreate)).Methods("POST")
+	authorize := middlewares.IdempotentHandler(http.HandlerFunc(api.Authorize), idempotency, idempotencyTTL)
+	transaction.Handle("/authorize", middlewares.ProtectedHandler(authorize, token, roles, models.PermissionPaymentCreate)).Methods("POST")
+	capture := middlewares.IdempotentHandler(http.HandlerFunc(api.Capture), idempotency, idempotencyTTL)
+	transaction.Handle("/authorizations/{id}/capture", middlewares.ProtectedHandler(capture, token, roles, models.PermissionPaymentCapture)).Methods("POST")
+	void := middlewares.IdempotentHandler(http.HandlerFunc(api.Void), idempotency, idempotencyTTL)
+	transaction.Handle("/authorizations/{id}/void", middlewares.ProtectedHandler(void, token, roles, models.PermissionPaymentCapture)).Methods("POST")
+	deposit := middlewares.IdempotentHandler(http.HandlerFunc(api.Deposit), idempotency, idempotencyTTL)
+	transaction.Handle("/deposit", middlewares.ProtectedHandler(deposit, token, roles, models.PermissionWalletDeposit)).Methods("POST")
+	withdraw := middlewares.IdempotentHandler(http.HandlerFunc(api.Withdraw), idempotency, idempotencyTTL)
+	transaction.Handle("/withdraw", middlewares.ProtectedHandler(withdraw, token, roles, models.PermissionWalletWithdraw)).Methods("POST")
+	transfer := middlewares.IdempotentHandler(http.HandlerFunc(api.Transfer), idempotency, idempotencyTTL)
+	transaction.Handle("/transfer", middlewares.ProtectedHandler(transfer, token, roles, models.PermissionTransferCreate)).Methods("POST")
+	transaction.Handle("/transfer/recipient", middlewares.ProtectedHandler(http.HandlerFunc(api.FindRecipient), token, roles, models.PermissionTransferCreate)).Methods("GET")
+	transaction.Handle("/transfer/limits", middlewares.ProtectedHandler(http.HandlerFunc(api.TransferLimits), token, roles, models.PermissionTransferCreate)).Methods("GET")
+	history := http.HandlerFunc(api.TransactionHistory)
+	transaction.Handle("/history/{id}", middlewares.ProtectedHandler(history, token, roles, models.PermissionHistoryReadOwn, models.PermissionHistoryReadMerchant, models.PermissionHistoryReadAny)).Methods("GET")
 }
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"go-json/internal/controllers"
+	"go-json/internal/middlewares"
+	"go-json/internal/models"
+	"go-json/internal/security"
+	"net/http"
+)
+
+func TwoFactorRoutes(api controllers.TwoFactorController, token security.TokenService, roles security.PermissionResolver) {
+	twoFactor := R.PathPrefix("/auth/2fa").Subrouter()
+	manage := func(handler http.HandlerFunc) http.Handler {
+		return middlewares.ProtectedHandler(handler, token, roles, models.PermissionTwoFactorManage)
+	}
+	twoFactor.Handle("/enroll", manage(api.Enroll)).Methods("POST")
+	twoFactor.Handle("/confirm", manage(api.Confirm)).Methods("POST")
+	twoFactor.Handle("/disable", manage(api.Disable)).Methods("POST")
+}
@@ -3,16 +3,20 @@ package routes
 import (
 	"go-json/internal/controllers"
 	"go-json/internal/middlewares"
+	"go-json/internal/models"
 	"go-json/internal/security"
 	"net/http"
 )
 
-func UserRoutes(api controllers.UserController, token security.TokenService) {
+func UserRoutes(api controllers.UserController, token security.TokenService, roles security.PermissionResolver) {
 	auth := R.PathPrefix("/auth").Subrouter()
 	auth.HandleFunc("/register", api.Register).Methods("POST")
 	auth.HandleFunc("/login", api.Login).Methods("POST")
-	auth.Handle("/logout", middlewares.ProtectedHandler(http.HandlerFunc(api.Logout), token, []string{"customer", "merchant"})).Methods("POST")
-	// auth.Handle("/refresh", middlewares.ProtectedHandler(http.HandlerFunc(api.Refresh), token, []string{"customer", "merchant"})).Methods("POST")
+	auth.HandleFunc("/login/verify", api.VerifyLogin).Methods("POST")
+	auth.HandleFunc("/login/2fa-setup", api.SetupTwoFactor).Methods("POST")
+	auth.Handle("/logout", middlewares.ProtectedHandler(http.HandlerFunc(api.Logout), token, roles, models.PermissionSessionLogout)).Methods("POST")
+	auth.Handle("/logout-all", middlewares.ProtectedHandler(http.HandlerFunc(api.LogoutAll), token, roles, models.PermissionSessionLogout)).Methods("POST")
+	auth.HandleFunc("/refresh", api.Refresh).Methods("POST")
 	user := R.PathPrefix("/user").Subrouter()
-	user.Handle("/users", middlewares.ProtectedHandler(http.HandlerFunc(api.UserList), token, []string{"merchant"})).Methods("GET")
+	user.Handle("/users", middlewares.ProtectedHandler(http.HandlerFunc(api.UserList), token, roles, models.PermissionUserList)).Methods("GET")
 }
@@ -0,0 +1,52 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix menandai API key, sehingga middleware bisa membedakannya dari JWT
+const APIKeyPrefix = "mbk_"
+
+// Panjang "mbk_" ditambah 12 karakter hex ID key
+const apiKeyPrefixLength = len(APIKeyPrefix) + 12
+
+// APIKeyAuthenticator memetakan API key ke principal pemiliknya
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(key string) (*Principal, error)
+}
+
+// GenerateAPIKey membuat key berformat mbk_<id>_<secret>. Bagian mbk_<id> dikembalikan
+// sebagai prefix untuk dicari di repository.
+func GenerateAPIKey() (key string, prefix string, err error) {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	prefix = APIKeyPrefix + hex.EncodeToString(id)
+	return prefix + "_" + secret, prefix, nil
+}
+
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// APIKeyPrefixOf mengambil prefix dari key; ok bernilai false bila formatnya salah
+func APIKeyPrefixOf(key string) (prefix string, ok bool) {
+	if !IsAPIKey(key) || len(key) <= apiKeyPrefixLength+1 || key[apiKeyPrefixLength] != '_' {
+		return "", false
+	}
+	return key[:apiKeyPrefixLength], true
+}
+
+// Seperti refresh token, API key hanya disimpan dalam bentuk hash
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,24 @@
+package security
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrMissingBearerToken   = errors.New("authorization header required")
+	ErrMalformedBearerToken = errors.New("authorization header must be \"Bearer <token>\"")
+)
+
+// BearerToken mengambil token dari header Authorization dengan format "Bearer <token>".
+// Nama skema tidak case-sensitive (RFC 7235), tapi token tidak boleh kosong atau mengandung spasi.
+func BearerToken(header string) (string, error) {
+	if header == "" {
+		return "", ErrMissingBearerToken
+	}
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" || strings.ContainsAny(token, " \t") {
+		return "", ErrMalformedBearerToken
+	}
+	return token, nil
+}
@@ -1,41 +1,111 @@
 package security
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"go-json/utils"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type TokenService interface {
-	GenerateToken(username, email string, role ...string) (string, error)
+	GenerateToken(subject TokenSubject) (string, error)
 	IsTokenExpired(tokenString string) bool
 	VerifyToken(tokenString string) (*Claims, error)
 	IsTokenValid(tokenString string) bool
+	GenerateRefreshToken() (string, error)
+	HashRefreshToken(refreshToken string) string
+	AccessTokenTTL() time.Duration
+	RefreshTokenTTL() time.Duration
+	GenerateChallengeToken(userID, purpose string) (string, error)
+	VerifyChallengeToken(tokenString string) (*ChallengeClaims, error)
+	ChallengeTokenTTL() time.Duration
+}
+
+// Purpose challenge token: memverifikasi kode 2FA, atau mendaftarkan 2FA yang diwajibkan role
+const (
+	ChallengeTwoFactorVerify = "2fa_verify"
+	ChallengeTwoFactorSetup  = "2fa_setup"
+)
+
+type TokenConfig struct {
+	AccessTTL    time.Duration
+	RefreshTTL   time.Duration
+	ChallengeTTL time.Duration
+}
+
+// TokenConfigFromEnv membaca ACCESS_TOKEN_TTL, REFRESH_TOKEN_TTL dan TWO_FACTOR_CHALLENGE_TTL (format time.ParseDuration)
+func TokenConfigFromEnv() TokenConfig {
+	return TokenConfig{
+		AccessTTL:    utils.DurationFromEnv("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTTL:   utils.DurationFromEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		ChallengeTTL: utils.DurationFromEnv("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute),
+	}
+}
+
+// RevocationChecker dipakai VerifyToken untuk menolak token yang sudah di-logout
+type RevocationChecker interface {
+	IsRevoked(jti, userID, sessionID string, issuedAt time.Time) bool
 }
 
 type tokenService struct {
-	jwtSecret []byte
+	jwtSecret   []byte
+	config      TokenConfig
+	revocations RevocationChecker
+}
+
+func NewTokenService(secret []byte, config TokenConfig, revocations RevocationChecker) TokenService {
+	return &tokenService{jwtSecret: secret, config: config, revocations: revocations}
 }
 
-func NewTokenService(secret []byte) TokenService {
-	return &tokenService{jwtSecret: secret}
+type TokenSubject struct {
+	UserID    string
+	Username  string
+	Email     string
+	SessionID string
+	Roles     []string
+}
+
+// ChallengeClaims hanya membuktikan bahwa password sudah benar; token ini tidak bisa dipakai
+// sebagai access token karena ditandatangani dengan key yang berbeda.
+type ChallengeClaims struct {
+	UserID  string `json:"user_id"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
 }
 
 type Claims struct {
-	Email string   `json:"email"`
-	Role  []string `json:"role"`
+	UserID    string   `json:"user_id"`
+	Email     string   `json:"email"`
+	Role      []string `json:"role"`
+	SessionID string   `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func (t *tokenService) GenerateToken(username string, email string, role ...string) (string, error) {
+func (t *tokenService) GenerateToken(subject TokenSubject) (string, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 	claims := &Claims{
-		Email: email,
-		Role:  role,
+		UserID:    subject.UserID,
+		Email:     subject.Email,
+		Role:      subject.Roles,
+		SessionID: subject.SessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 30)),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.config.AccessTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    "go-json",
-			Subject:   username,
+			Subject:   subject.Username,
 		},
 	}
 
@@ -72,5 +142,88 @@ func (t *tokenService) VerifyToken(tokenString string) (*Claims, error) {
 		return nil, err
 	}
 
-	return token.Claims.(*Claims), nil
+	claims := token.Claims.(*Claims)
+	if t.revocations != nil {
+		var issuedAt time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+		if t.revocations.IsRevoked(claims.ID, claims.UserID, claims.SessionID, issuedAt) {
+			return nil, errors.New("token has been revoked")
+		}
+	}
+
+	return claims, nil
+}
+
+func (t *tokenService) GenerateRefreshToken() (string, error) {
+	return randomToken(32)
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Refresh token hanya disimpan dalam bentuk hash di server
+func (t *tokenService) HashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+func (t *tokenService) AccessTokenTTL() time.Duration {
+	return t.config.AccessTTL
+}
+
+func (t *tokenService) RefreshTokenTTL() time.Duration {
+	return t.config.RefreshTTL
+}
+
+func (t *tokenService) ChallengeTokenTTL() time.Duration {
+	return t.config.ChallengeTTL
+}
+
+func (t *tokenService) GenerateChallengeToken(userID, purpose string) (string, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := &ChallengeClaims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.config.ChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    "go-json",
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(t.challengeKey())
+}
+
+func (t *tokenService) VerifyChallengeToken(tokenString string) (*ChallengeClaims, error) {
+	claims := &ChallengeClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return t.challengeKey(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (t *tokenService) challengeKey() []byte {
+	mac := hmac.New(sha256.New, t.jwtSecret)
+	mac.Write([]byte("two-factor-challenge"))
+	return mac.Sum(nil)
 }
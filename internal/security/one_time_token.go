@@ -0,0 +1,17 @@
+package security
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// GenerateOneTimeToken membuat token acak untuk link yang dikirim ke user, misalnya reset password
+func GenerateOneTimeToken() (string, error) {
+	return randomToken(32)
+}
+
+// Seperti refresh token, token sekali pakai hanya disimpan dalam bentuk hash
+func HashOneTimeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
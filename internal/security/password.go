@@ -0,0 +1,79 @@
+package security
+
+import (
+	"crypto/subtle"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Compare(hashedPassword, password string) (bool, error)
+	IsHashed(storedPassword string) bool
+	NeedsRehash(hashedPassword string) bool
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func NewPasswordHasher(cost int) PasswordHasher {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = bcrypt.DefaultCost
+	}
+	return &bcryptHasher{cost: cost}
+}
+
+// NewPasswordHasherFromEnv membaca cost bcrypt dari BCRYPT_COST, default ke bcrypt.DefaultCost
+func NewPasswordHasherFromEnv() PasswordHasher {
+	cost, err := strconv.Atoi(os.Getenv("BCRYPT_COST"))
+	if err != nil {
+		cost = bcrypt.DefaultCost
+	}
+	return NewPasswordHasher(cost)
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// Compare juga menerima password lama yang masih tersimpan sebagai plaintext,
+// sehingga login tetap berjalan sampai password tersebut di-hash ulang.
+func (h *bcryptHasher) Compare(hashedPassword, password string) (bool, error) {
+	if !h.IsHashed(hashedPassword) {
+		return subtle.ConstantTimeCompare([]byte(hashedPassword), []byte(password)) == 1, nil
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (h *bcryptHasher) IsHashed(storedPassword string) bool {
+	return strings.HasPrefix(storedPassword, "$2a$") ||
+		strings.HasPrefix(storedPassword, "$2b$") ||
+		strings.HasPrefix(storedPassword, "$2y$")
+}
+
+func (h *bcryptHasher) NeedsRehash(hashedPassword string) bool {
+	if !h.IsHashed(hashedPassword) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	if err != nil {
+		return true
+	}
+	return cost != h.cost
+}
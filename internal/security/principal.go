@@ -0,0 +1,51 @@
+package security
+
+import (
+	"context"
+	"slices"
+)
+
+// Principal adalah user yang terautentikasi untuk satu request, diambil dari claims JWT.
+// Permissions di-resolve dari Roles saat request masuk, bukan disimpan di token.
+// APIKeyID hanya terisi bila request diautentikasi dengan API key; SessionID kosong.
+type Principal struct {
+	UserID      string
+	Email       string
+	Roles       []string
+	Permissions []string
+	SessionID   string
+	APIKeyID    string
+}
+
+// PermissionResolver memetakan nama role ke permission yang dimilikinya
+type PermissionResolver interface {
+	PermissionsForRoles(roleNames []string) []string
+}
+
+type principalKey struct{}
+
+func PrincipalFromClaims(claims *Claims) Principal {
+	return Principal{
+		UserID:    claims.UserID,
+		Email:     claims.Email,
+		Roles:     claims.Role,
+		SessionID: claims.SessionID,
+	}
+}
+
+func (p Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+func (p Principal) HasPermission(permission string) bool {
+	return slices.Contains(p.Permissions, permission)
+}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
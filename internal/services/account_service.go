@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-json/internal/dtos/mapper"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	"go-json/internal/security"
+	"go-json/internal/validators"
+	"log"
+	"time"
+)
+
+var (
+	ErrAccountSuspended        = errors.New("account is suspended")
+	ErrAccountClosed           = errors.New("account is closed")
+	ErrInvalidStatusTransition = errors.New("account status transition is not allowed")
+	ErrUnknownReasonCode       = errors.New("unknown account status reason code")
+	ErrAccountBalanceNotZero   = errors.New("account balance must be zero before it can be closed")
+	ErrAccountHasPendingFunds  = errors.New("account cannot be closed while deposits, withdrawals or authorizations are pending")
+	ErrOwnAccountStatusChange  = errors.New("cannot change the status of your own account")
+)
+
+// AccountService dipakai admin untuk membekukan, mengaktifkan kembali, dan menutup akun,
+// serta untuk mengatur batas transfer user tertentu
+type AccountService interface {
+	ChangeStatus(actor security.Principal, userID string, change request.AccountStatusRequest) (*response.AccountStatusResponse, error)
+	SetTransferLimits(actor security.Principal, userID string, limits request.TransferLimitsRequest) (*response.TransferLimitsResponse, error)
+	ResetTransferLimits(actor security.Principal, userID string) (*response.TransferLimitsResponse, error)
+}
+
+type accountService struct {
+	userRepo        repositories.UserRepository
+	refreshRepo     repositories.RefreshTokenRepository
+	revokedRepo     repositories.RevokedTokenRepository
+	auditRepo       repositories.AuditRepository
+	transactionRepo repositories.TransactionRepository
+	ledger          LedgerService
+	token           security.TokenService
+	transferLimits  models.TransferLimits
+}
+
+func NewAccountService(userRepo repositories.UserRepository, refreshRepo repositories.RefreshTokenRepository, revokedRepo repositories.RevokedTokenRepository, auditRepo repositories.AuditRepository, transactionRepo repositories.TransactionRepository, ledger LedgerService, token security.TokenService, transferLimits models.TransferLimits) AccountService {
+	return &accountService{userRepo: userRepo, refreshRepo: refreshRepo, revokedRepo: revokedRepo, auditRepo: auditRepo, transactionRepo: transactionRepo, ledger: ledger, token: token, transferLimits: transferLimits}
+}
+
+// ChangeStatus memindahkan status akun sesuai models.AccountStatus.CanTransitionTo.
+// Akun yang dibekukan atau ditutup langsung kehilangan semua sesinya; akun hanya
+// bisa ditutup bila saldo semua wallet-nya di ledger nol dan tidak ada dana yang masih tertahan.
+func (s *accountService) ChangeStatus(actor security.Principal, userID string, change request.AccountStatusRequest) (*response.AccountStatusResponse, error) {
+	if err := validators.NewCustomerValidator().Validate(change); err != nil {
+		return nil, err
+	}
+	if !models.IsKnownAccountReason(change.ReasonCode) {
+		return nil, ErrUnknownReasonCode
+	}
+	if actor.UserID == userID {
+		return nil, ErrOwnAccountStatusChange
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	previous := user.AccountStatus()
+	if !previous.CanTransitionTo(change.Status) {
+		return nil, ErrInvalidStatusTransition
+	}
+	if change.Status == models.AccountClosed {
+		if err := s.closeBlocker(user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	if err := s.userRepo.UpdateStatus(user.ID, change.Status, string(change.ReasonCode), now); err != nil {
+		return nil, err
+	}
+	user.Status = change.Status
+	user.StatusReason = string(change.ReasonCode)
+	user.StatusChangedAt = &now
+
+	if !change.Status.CanLogin() {
+		if err := s.revokeSessions(user.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	reason := fmt.Sprintf("%s -> %s (%s)", previous, change.Status, change.ReasonCode)
+	if change.Note != "" {
+		reason += ": " + change.Note
+	}
+	s.audit(actor, models.AuditChangeAccountStatus, user.ID, reason, now)
+
+	statusResponse := mapper.UserModelToAccountStatusResponse(*user, previous)
+	return &statusResponse, nil
+}
+
+// SetTransferLimits memberi userID batas transfer sendiri yang menggantikan batas bawaan
+func (s *accountService) SetTransferLimits(actor security.Principal, userID string, change request.TransferLimitsRequest) (*response.TransferLimitsResponse, error) {
+	if err := validators.NewCustomerValidator().Validate(change); err != nil {
+		return nil, err
+	}
+	limits := models.TransferLimits{PerTransfer: change.PerTransfer, Daily: change.Daily}
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := s.userRepo.UpdateTransferLimits(user.ID, &limits); err != nil {
+		return nil, err
+	}
+	user.TransferLimits = &limits
+
+	reason := fmt.Sprintf("per transfer %s, daily %s", limits.PerTransfer, limits.Daily)
+	if change.Note != "" {
+		reason += ": " + change.Note
+	}
+	s.audit(actor, models.AuditChangeTransferLimits, user.ID, reason, time.Now())
+
+	limitsResponse := mapper.UserModelToTransferLimitsResponse(*user, s.transferLimits)
+	return &limitsResponse, nil
+}
+
+// ResetTransferLimits mengembalikan userID ke batas transfer bawaan
+func (s *accountService) ResetTransferLimits(actor security.Principal, userID string) (*response.TransferLimitsResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.TransferLimits != nil {
+		if err := s.userRepo.UpdateTransferLimits(user.ID, nil); err != nil {
+			return nil, err
+		}
+		user.TransferLimits = nil
+		s.audit(actor, models.AuditChangeTransferLimits, user.ID, "reset to defaults", time.Now())
+	}
+
+	limitsResponse := mapper.UserModelToTransferLimitsResponse(*user, s.transferLimits)
+	return &limitsResponse, nil
+}
+
+// closeBlocker memeriksa saldo di ledger, bukan salinan saldo di users.json. Dana di akun HOLD
+// berarti masih ada withdrawal atau otorisasi yang terbuka; deposit PENDING belum tercatat di ledger.
+func (s *accountService) closeBlocker(userID string) error {
+	wallets, held, err := s.ledger.Balances(userID)
+	if err != nil {
+		return err
+	}
+	if len(wallets) > 0 {
+		return ErrAccountBalanceNotZero
+	}
+	if len(held) > 0 {
+		return ErrAccountHasPendingFunds
+	}
+	transactions, err := s.transactionRepo.FindAllTransaction()
+	if err != nil {
+		return err
+	}
+	for _, trx := range transactions {
+		if trx.CustomerID == userID && trx.Status == models.TransactionPending {
+			return ErrAccountHasPendingFunds
+		}
+	}
+	return nil
+}
+
+func (s *accountService) audit(actor security.Principal, action, userID, reason string, at time.Time) {
+	_, err := s.auditRepo.Record(models.AuditEvent{
+		ActorID:   actor.UserID,
+		Action:    action,
+		Resource:  "user:" + userID,
+		Outcome:   models.AuditAllowed,
+		Reason:    reason,
+		Timestamp: at,
+	})
+	if err != nil {
+		log.Printf("Failed to record audit event: %v", err)
+	}
+}
+
+func (s *accountService) revokeSessions(userID string, now time.Time) error {
+	err := s.revokedRepo.Revoke(models.RevokedToken{
+		UserID:    userID,
+		RevokedAt: now,
+		ExpiresAt: now.Add(s.token.AccessTokenTTL()),
+	})
+	if err != nil {
+		return err
+	}
+	return s.refreshRepo.RevokeByUserID(userID)
+}
+
+// accountStatusError mengembalikan error untuk akun yang tidak boleh login atau bertransaksi
+func accountStatusError(status models.AccountStatus) error {
+	switch status {
+	case models.AccountSuspended:
+		return ErrAccountSuspended
+	case models.AccountClosed:
+		return ErrAccountClosed
+	default:
+		return nil
+	}
+}
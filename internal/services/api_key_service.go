@@ -0,0 +1,226 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"go-json/internal/dtos/mapper"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	"go-json/internal/security"
+	"go-json/internal/validators"
+	"log"
+	"slices"
+	"time"
+)
+
+var (
+	ErrAPIKeyNotFound     = errors.New("API key not found")
+	ErrInvalidAPIKey      = errors.New("invalid or revoked API key")
+	ErrInvalidAPIKeyScope = errors.New("scope cannot be granted to an API key")
+	ErrAPIKeyLimit        = errors.New("maximum number of active API keys reached")
+	ErrAPIKeyRevoked      = errors.New("API key is already revoked")
+)
+
+const maxActiveAPIKeys = 10
+
+// LastUsedAt hanya ditulis ulang bila sudah lebih lama dari ini, supaya setiap request
+// dengan API key tidak menulis ulang api_keys.json
+const apiKeyLastUsedInterval = time.Minute
+
+type APIKeyService interface {
+	CreateKey(actor security.Principal, create request.CreateAPIKeyRequest) (*response.APIKeyCreatedResponse, error)
+	ListKeys(actor security.Principal) ([]response.APIKeyResponse, error)
+	UpdateLabel(actor security.Principal, keyID string, update request.UpdateAPIKeyRequest) (*response.APIKeyResponse, error)
+	RevokeKey(actor security.Principal, keyID string) error
+	AuthenticateAPIKey(key string) (*security.Principal, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepo repositories.APIKeyRepository
+	userRepo   repositories.UserRepository
+	roleRepo   repositories.RoleRepository
+	auditRepo  repositories.AuditRepository
+}
+
+func NewAPIKeyService(apiKeyRepo repositories.APIKeyRepository, userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, auditRepo repositories.AuditRepository) APIKeyService {
+	return &apiKeyService{apiKeyRepo: apiKeyRepo, userRepo: userRepo, roleRepo: roleRepo, auditRepo: auditRepo}
+}
+
+// CreateKey membuat API key untuk actor. Scope harus ada di models.APIKeyScopes dan
+// sedang dimiliki actor lewat role-nya.
+func (s *apiKeyService) CreateKey(actor security.Principal, create request.CreateAPIKeyRequest) (*response.APIKeyCreatedResponse, error) {
+	if err := validators.NewCustomerValidator().Validate(create); err != nil {
+		return nil, err
+	}
+	for _, scope := range create.Scopes {
+		if !models.IsAPIKeyScope(scope) || !actor.HasPermission(scope) {
+			return nil, ErrInvalidAPIKeyScope
+		}
+	}
+
+	keys, err := s.apiKeyRepo.FindByUserID(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	active := 0
+	for _, key := range keys {
+		if !key.IsRevoked() {
+			active++
+		}
+	}
+	if active >= maxActiveAPIKeys {
+		return nil, ErrAPIKeyLimit
+	}
+
+	plaintext, prefix, err := security.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	scopes := slices.Clone(create.Scopes)
+	slices.Sort(scopes)
+	key, err := s.apiKeyRepo.Create(models.APIKey{
+		UserID:    actor.UserID,
+		Label:     create.Label,
+		Prefix:    prefix,
+		KeyHash:   security.HashAPIKey(plaintext),
+		Scopes:    slices.Compact(scopes),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit(actor.UserID, models.AuditCreateAPIKey, key.ID)
+
+	created := mapper.APIKeyModelToCreatedResponse(*key, plaintext)
+	return &created, nil
+}
+
+func (s *apiKeyService) ListKeys(actor security.Principal) ([]response.APIKeyResponse, error) {
+	keys, err := s.apiKeyRepo.FindByUserID(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	keyResponses := []response.APIKeyResponse{}
+	for _, key := range keys {
+		keyResponses = append(keyResponses, mapper.APIKeyModelToResponse(key))
+	}
+	return keyResponses, nil
+}
+
+func (s *apiKeyService) UpdateLabel(actor security.Principal, keyID string, update request.UpdateAPIKeyRequest) (*response.APIKeyResponse, error) {
+	if err := validators.NewCustomerValidator().Validate(update); err != nil {
+		return nil, err
+	}
+	key, err := s.ownKey(actor, keyID)
+	if err != nil {
+		return nil, err
+	}
+	key.Label = update.Label
+	if err := s.apiKeyRepo.Update(*key); err != nil {
+		return nil, err
+	}
+	keyResponse := mapper.APIKeyModelToResponse(*key)
+	return &keyResponse, nil
+}
+
+// RevokeKey mencabut key secara permanen; key tetap muncul di daftar dengan revoked_at terisi
+func (s *apiKeyService) RevokeKey(actor security.Principal, keyID string) error {
+	key, err := s.ownKey(actor, keyID)
+	if err != nil {
+		return err
+	}
+	if key.IsRevoked() {
+		return ErrAPIKeyRevoked
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	if err := s.apiKeyRepo.Update(*key); err != nil {
+		return err
+	}
+	s.audit(actor.UserID, models.AuditRevokeAPIKey, key.ID)
+	return nil
+}
+
+// AuthenticateAPIKey dipakai middleware. Permission principal adalah scope key yang masih
+// dimiliki role pemiliknya, sehingga mencabut permission dari role juga membatasi key-nya.
+func (s *apiKeyService) AuthenticateAPIKey(plaintext string) (*security.Principal, error) {
+	prefix, ok := security.APIKeyPrefixOf(plaintext)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := s.apiKeyRepo.FindByPrefix(prefix)
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(security.HashAPIKey(plaintext))) != 1 || key.IsRevoked() {
+		return nil, ErrInvalidAPIKey
+	}
+	owner, err := s.userRepo.FindByID(key.UserID)
+	if err != nil || !owner.AccountStatus().CanLogin() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	roleNames := s.roleNames(owner.ID)
+	rolePermissions := s.roleRepo.PermissionsForRoles(roleNames)
+	// Key lama bisa menyimpan scope yang sudah dikeluarkan dari models.APIKeyScopes
+	permissions := []string{}
+	for _, scope := range key.Scopes {
+		if models.IsAPIKeyScope(scope) && slices.Contains(rolePermissions, scope) {
+			permissions = append(permissions, scope)
+		}
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyLastUsedInterval {
+		key.LastUsedAt = &now
+		if err := s.apiKeyRepo.Update(*key); err != nil {
+			log.Printf("Failed to update API key last used time: %v", err)
+		}
+	}
+
+	return &security.Principal{
+		UserID:      owner.ID,
+		Email:       owner.Email,
+		Roles:       roleNames,
+		Permissions: permissions,
+		APIKeyID:    key.ID,
+	}, nil
+}
+
+// ownKey mengembalikan ErrAPIKeyNotFound juga untuk key milik user lain
+func (s *apiKeyService) ownKey(actor security.Principal, keyID string) (*models.APIKey, error) {
+	key, err := s.apiKeyRepo.FindByID(keyID)
+	if err != nil || key.UserID != actor.UserID {
+		return nil, ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func (s *apiKeyService) roleNames(userID string) []string {
+	roleNames := []string{}
+	userRoles, err := s.roleRepo.FindRoleByUserID(userID)
+	if err != nil {
+		return roleNames
+	}
+	for _, userRole := range *userRoles {
+		if role, err := s.roleRepo.FindByRoleID(userRole.RoleID); err == nil {
+			roleNames = append(roleNames, role.Name)
+		}
+	}
+	return roleNames
+}
+
+func (s *apiKeyService) audit(actorID, action, keyID string) {
+	_, err := s.auditRepo.Record(models.AuditEvent{
+		ActorID:   actorID,
+		Action:    action,
+		Resource:  "api_key:" + keyID,
+		Outcome:   models.AuditAllowed,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to record audit event: %v", err)
+	}
+}
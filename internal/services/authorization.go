@@ -0,0 +1,404 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-json/internal/dtos/mapper"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+	"log"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrAuthorizationNotFound = errors.New("authorization not found")
+	ErrAuthorizationAmount   = errors.New("authorization amount must be positive")
+	ErrAuthorizationClosed   = errors.New("authorization is already fully captured, voided or expired")
+	ErrAuthorizationExpired  = errors.New("authorization has expired")
+	ErrCaptureNotAllowed     = errors.New("only the merchant of the authorization can capture or void it")
+	ErrCaptureExceedsHold    = errors.New("capture amount exceeds the amount still held")
+)
+
+// AuthorizePayment menahan dana di wallet customer untuk merchant. Dana itu tetap milik customer
+// sampai di-capture, dan dilepas otomatis bila belum di-capture saat otorisasi kedaluwarsa.
+func (p *transactionService) AuthorizePayment(customerID string, authorization request.AuthorizationRequest) (*response.AuthorizationResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(authorization); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	transaction := models.Transaction{
+		CustomerID:   customerID,
+		MerchantID:   authorization.MerchantID,
+		Amount:       authorization.Amount,
+		ActivityType: models.FailedAuthorization,
+		Timestamp:    now,
+	}
+	transaction.Begin(now)
+
+	if !authorization.Amount.IsPositive() {
+		transaction.Details = "Authorization amount must be positive"
+		p.recordFailure(transaction, models.FailureInvalidAmount)
+		return nil, ErrAuthorizationAmount
+	}
+
+	user, err := p.userRepo.FindByID(customerID)
+	if err != nil {
+		transaction.Details = "Invalid customer ID"
+		p.recordFailure(transaction, models.FailureInvalidCustomer)
+		return nil, ErrInvalidCustomer
+	}
+	if reason, details, err := customerPaymentError(user); err != nil {
+		transaction.Details = details
+		p.recordFailure(transaction, reason)
+		return nil, err
+	}
+	if reason, details, err := p.merchantPaymentError(authorization.MerchantID); err != nil {
+		transaction.Details = details
+		p.recordFailure(transaction, reason)
+		return nil, err
+	}
+	if authorization.MerchantID == user.ID {
+		transaction.Details = "Customer and merchant must differ"
+		p.recordFailure(transaction, models.FailureSameParty)
+		return nil, ErrSameParty
+	}
+
+	entry, err := p.ledger.Hold(user.ID, authorization.Amount, "Authorization for merchant "+authorization.MerchantID)
+	if errors.Is(err, ErrInsufficientBalance) {
+		transaction.Details = "Insufficient balance"
+		p.recordFailure(transaction, models.FailureInsufficientBalance)
+		return nil, err
+	}
+	if err != nil {
+		transaction.Details = "Failed to post ledger entry"
+		p.recordFailure(transaction, models.FailureLedgerError)
+		return nil, err
+	}
+
+	expiresAt := now.Add(p.authorizationTTL)
+	transaction.ActivityType = models.AuthorizationActivity
+	transaction.Details = "Funds held for merchant " + authorization.MerchantID
+	transaction.JournalEntryID = entry.ID
+	transaction.ExpiresAt = &expiresAt
+
+	trx, err := p.transactionRepo.CreateTransaction(transaction)
+	if err != nil {
+		return nil, err
+	}
+	authorizationResponse := mapper.TransactionModelToAuthorizationResponse(trx, trx, models.NewMoney(0, trx.Amount.Currency()), trx.Amount, models.AuthorizationOpen)
+	return &authorizationResponse, nil
+}
+
+// CaptureAuthorization memindahkan sebagian atau seluruh dana yang ditahan ke wallet merchant.
+// Otorisasi boleh di-capture beberapa kali selama totalnya tidak melebihi jumlah yang diotorisasi.
+func (p *transactionService) CaptureAuthorization(authorizationID, merchantID string, capture request.CaptureRequest) (*response.AuthorizationResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(capture); err != nil {
+		return nil, err
+	}
+
+	p.authorizationMu.Lock()
+	defer p.authorizationMu.Unlock()
+
+	authorization, captured, status, err := p.findAuthorization(authorizationID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	held, err := heldAmount(authorization, captured, status)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := models.Transaction{
+		CustomerID:            authorization.CustomerID,
+		MerchantID:            authorization.MerchantID,
+		Amount:                capture.Amount,
+		ActivityType:          models.FailedCapture,
+		Timestamp:             time.Now(),
+		OriginalTransactionID: authorization.ID,
+	}
+	transaction.Begin(transaction.Timestamp)
+
+	if status != models.AuthorizationOpen {
+		transaction.Details = "Authorization is " + string(status)
+		p.recordFailure(transaction, models.FailureAuthorizationClosed)
+		return nil, ErrAuthorizationClosed
+	}
+	// Job kedaluwarsa mungkin belum berjalan; lepaskan dananya sekarang
+	if authorization.ExpiresAt != nil && transaction.Timestamp.After(*authorization.ExpiresAt) {
+		if err := p.expireAuthorization(authorization, held); err != nil {
+			return nil, err
+		}
+		transaction.Details = "Authorization has expired"
+		p.recordFailure(transaction, models.FailureAuthorizationExpired)
+		return nil, ErrAuthorizationExpired
+	}
+
+	if capture.Amount.IsZero() {
+		transaction.Amount = held
+	}
+	cmp, err := transaction.Amount.Cmp(held)
+	if err != nil {
+		transaction.Details = "Capture currency must match the authorization currency"
+		p.recordFailure(transaction, models.FailureCurrencyMismatch)
+		return nil, err
+	}
+	if !transaction.Amount.IsPositive() || cmp > 0 {
+		transaction.Details = "Capture amount exceeds the amount still held"
+		p.recordFailure(transaction, models.FailureExceedsHeld)
+		return nil, ErrCaptureExceedsHold
+	}
+	if reason, details, err := p.merchantPaymentError(authorization.MerchantID); err != nil {
+		transaction.Details = details
+		p.recordFailure(transaction, reason)
+		return nil, err
+	}
+
+	description := "Capture of authorization " + authorization.ID
+	entry, err := p.ledger.CaptureHold(authorization.CustomerID, authorization.MerchantID, transaction.Amount, description)
+	if err != nil {
+		transaction.Details = "Failed to post ledger entry"
+		p.recordFailure(transaction, models.FailureLedgerError)
+		return nil, err
+	}
+
+	transaction.ActivityType = models.CaptureActivity
+	transaction.Details = description
+	transaction.JournalEntryID = entry.ID
+	if err := transaction.TransitionTo(models.TransactionSucceeded, time.Now()); err != nil {
+		return nil, err
+	}
+
+	trx, err := p.transactionRepo.CreateTransaction(transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	if captured, err = captured.Add(trx.Amount); err != nil {
+		return nil, err
+	}
+	if held, err = held.Sub(trx.Amount); err != nil {
+		return nil, err
+	}
+	if held.IsZero() {
+		status = models.AuthorizationCaptured
+		p.closeAuthorization(*authorization, held)
+	}
+	authorizationResponse := mapper.TransactionModelToAuthorizationResponse(trx, authorization, captured, held, status)
+	return &authorizationResponse, nil
+}
+
+// VoidAuthorization melepaskan seluruh dana yang masih ditahan kembali ke wallet customer
+func (p *transactionService) VoidAuthorization(authorizationID, merchantID string, void request.VoidRequest) (*response.AuthorizationResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(void); err != nil {
+		return nil, err
+	}
+
+	p.authorizationMu.Lock()
+	defer p.authorizationMu.Unlock()
+
+	authorization, captured, status, err := p.findAuthorization(authorizationID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if status != models.AuthorizationOpen {
+		return nil, ErrAuthorizationClosed
+	}
+	held, err := heldAmount(authorization, captured, status)
+	if err != nil {
+		return nil, err
+	}
+
+	description := "Void of authorization " + authorization.ID
+	if void.Reason != "" {
+		description += ": " + void.Reason
+	}
+	entry, err := p.ledger.ReleaseHold(authorization.CustomerID, held, "", description)
+	if err != nil {
+		return nil, err
+	}
+
+	trx, err := p.transactionRepo.CreateTransaction(settled(models.Transaction{
+		CustomerID:            authorization.CustomerID,
+		MerchantID:            authorization.MerchantID,
+		Amount:                held,
+		ActivityType:          models.VoidActivity,
+		Timestamp:             time.Now(),
+		Details:               description,
+		JournalEntryID:        entry.ID,
+		OriginalTransactionID: authorization.ID,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	p.closeAuthorization(*authorization, held)
+	authorizationResponse := mapper.TransactionModelToAuthorizationResponse(trx, authorization, captured, models.NewMoney(0, held.Currency()), models.AuthorizationVoided)
+	return &authorizationResponse, nil
+}
+
+// ExpireAuthorizations melepaskan dana dari otorisasi yang sudah lewat ExpiresAt dan belum selesai.
+// Dijalankan berkala oleh background job.
+func (p *transactionService) ExpireAuthorizations() error {
+	p.authorizationMu.Lock()
+	defer p.authorizationMu.Unlock()
+
+	transactions, err := p.transactionRepo.FindAllTransaction()
+	if err != nil {
+		return err
+	}
+	linked := map[string][]models.Transaction{}
+	for _, trx := range transactions {
+		if trx.OriginalTransactionID != "" {
+			linked[trx.OriginalTransactionID] = append(linked[trx.OriginalTransactionID], trx)
+		}
+	}
+
+	// Satu otorisasi yang gagal tidak boleh menahan otorisasi kedaluwarsa lainnya
+	now := time.Now()
+	expired := 0
+	var errs []error
+	for _, trx := range transactions {
+		if trx.ActivityType != models.AuthorizationActivity || trx.ExpiresAt == nil || !now.After(*trx.ExpiresAt) {
+			continue
+		}
+		released, err := p.expireIfOpen(trx, linked[trx.ID])
+		if err != nil {
+			log.Printf("Failed to expire authorization %s: %v", trx.ID, err)
+			errs = append(errs, fmt.Errorf("authorization %s: %w", trx.ID, err))
+			continue
+		}
+		if released {
+			expired++
+		}
+	}
+	if expired > 0 {
+		log.Printf("Released %d expired authorizations", expired)
+	}
+	return errors.Join(errs...)
+}
+
+// expireIfOpen melepaskan dana otorisasi yang masih OPEN dan melaporkan apakah ada yang dilepas
+func (p *transactionService) expireIfOpen(authorization models.Transaction, linked []models.Transaction) (bool, error) {
+	captured, status, err := authorizationState(authorization, linked)
+	if err != nil {
+		return false, err
+	}
+	if status != models.AuthorizationOpen {
+		return false, nil
+	}
+	held, err := heldAmount(&authorization, captured, status)
+	if err != nil {
+		return false, err
+	}
+	return true, p.expireAuthorization(&authorization, held)
+}
+
+func (p *transactionService) expireAuthorization(authorization *models.Transaction, held models.Money) error {
+	description := "Authorization " + authorization.ID + " expired"
+	entry, err := p.ledger.ReleaseHold(authorization.CustomerID, held, "", description)
+	if err != nil {
+		return err
+	}
+	_, err = p.transactionRepo.CreateTransaction(settled(models.Transaction{
+		CustomerID:            authorization.CustomerID,
+		MerchantID:            authorization.MerchantID,
+		Amount:                held,
+		ActivityType:          models.ExpiryActivity,
+		Timestamp:             time.Now(),
+		Details:               description,
+		JournalEntryID:        entry.ID,
+		OriginalTransactionID: authorization.ID,
+	}))
+	if err != nil {
+		return err
+	}
+	p.closeAuthorization(*authorization, held)
+	return nil
+}
+
+// closeAuthorization menutup status otorisasi setelah dananya tidak lagi ditahan: REVERSED bila
+// seluruhnya dikembalikan ke customer, SUCCEEDED bila ada yang di-capture
+func (p *transactionService) closeAuthorization(authorization models.Transaction, released models.Money) {
+	status := models.TransactionSucceeded
+	if released == authorization.Amount {
+		status = models.TransactionReversed
+	}
+	if err := authorization.TransitionTo(status, time.Now()); err != nil {
+		log.Printf("Failed to mark authorization %s as %s: %v", authorization.ID, status, err)
+		return
+	}
+	if err := p.transactionRepo.UpdateTransaction(authorization); err != nil {
+		log.Printf("Failed to update authorization %s: %v", authorization.ID, err)
+	}
+}
+
+// settled menandai transaksi yang langsung selesai saat dicatat, seperti void dan expiry
+func settled(transaction models.Transaction) models.Transaction {
+	transaction.Begin(transaction.Timestamp)
+	transaction.TransitionTo(models.TransactionSucceeded, transaction.Timestamp)
+	return transaction
+}
+
+// findAuthorization mencari otorisasi yang boleh diproses actorID beserta jumlah yang sudah di-capture dan statusnya
+func (p *transactionService) findAuthorization(authorizationID, actorID string) (*models.Transaction, models.Money, models.AuthorizationStatus, error) {
+	authorization, err := p.transactionRepo.FindByID(authorizationID)
+	if err != nil || authorization.ActivityType != models.AuthorizationActivity {
+		return nil, models.Money{}, "", ErrAuthorizationNotFound
+	}
+	if !p.actsForMerchant(actorID, authorization.MerchantID) {
+		return nil, models.Money{}, "", ErrCaptureNotAllowed
+	}
+
+	transactions, err := p.transactionRepo.FindAllTransaction()
+	if err != nil {
+		return nil, models.Money{}, "", err
+	}
+	var linked []models.Transaction
+	for _, trx := range transactions {
+		if trx.OriginalTransactionID == authorization.ID {
+			linked = append(linked, trx)
+		}
+	}
+	captured, status, err := authorizationState(*authorization, linked)
+	if err != nil {
+		return nil, models.Money{}, "", err
+	}
+	return authorization, captured, status, nil
+}
+
+// authorizationState menjumlahkan capture dan menentukan status otorisasi dari transaksi yang terhubung dengannya
+func authorizationState(authorization models.Transaction, linked []models.Transaction) (models.Money, models.AuthorizationStatus, error) {
+	captured := models.NewMoney(0, authorization.Amount.Currency())
+	status := models.AuthorizationOpen
+	for _, trx := range linked {
+		switch trx.ActivityType {
+		case models.CaptureActivity:
+			var err error
+			if captured, err = captured.Add(trx.Amount); err != nil {
+				return models.Money{}, "", err
+			}
+		case models.VoidActivity:
+			status = models.AuthorizationVoided
+		case models.ExpiryActivity:
+			status = models.AuthorizationExpired
+		}
+	}
+	if status == models.AuthorizationOpen && captured == authorization.Amount {
+		status = models.AuthorizationCaptured
+	}
+	return captured, status, nil
+}
+
+// heldAmount adalah bagian otorisasi yang masih ditahan; nol bila otorisasi sudah selesai
+func heldAmount(authorization *models.Transaction, captured models.Money, status models.AuthorizationStatus) (models.Money, error) {
+	if status != models.AuthorizationOpen {
+		return models.NewMoney(0, authorization.Amount.Currency()), nil
+	}
+	return authorization.Amount.Sub(captured)
+}
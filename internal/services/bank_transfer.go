@@ -0,0 +1,233 @@
+package services
+
+import (
+	"errors"
+	"go-json/internal/bankrails"
+	"go-json/internal/dtos/mapper"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+	"log"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrBankTransferAmount  = errors.New("amount must be positive")
+	ErrBankRailUnavailable = errors.New("bank is not accepting transfers right now")
+)
+
+// Deposit mencatat deposit PENDING dan meminta bank menarik dana dari rekening user.
+// Wallet baru dikredit setelah bank mengonfirmasi dananya masuk.
+func (p *transactionService) Deposit(userID string, deposit request.BankTransferRequest) (*response.BankTransferResponse, error) {
+	return p.bankTransfer(userID, deposit, bankrails.Deposit)
+}
+
+// Withdraw menahan dana di wallet user lalu meminta bank mengirimnya ke rekening user.
+// Dana yang ditahan keluar dari ledger setelah bank mengonfirmasi, atau kembali ke wallet bila ditolak.
+func (p *transactionService) Withdraw(userID string, withdrawal request.BankTransferRequest) (*response.BankTransferResponse, error) {
+	return p.bankTransfer(userID, withdrawal, bankrails.Withdrawal)
+}
+
+func (p *transactionService) bankTransfer(userID string, transfer request.BankTransferRequest, direction bankrails.Direction) (*response.BankTransferResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(transfer); err != nil {
+		return nil, err
+	}
+
+	activity, failedActivity := models.DepositActivity, models.FailedDeposit
+	if direction == bankrails.Withdrawal {
+		activity, failedActivity = models.WithdrawalActivity, models.FailedWithdrawal
+	}
+	transaction := models.Transaction{
+		CustomerID:   userID,
+		ActivityType: failedActivity,
+		Timestamp:    time.Now(),
+		Amount:       transfer.Amount,
+		BankAccount: &models.SettlementAccount{
+			BankCode:      transfer.BankAccount.BankCode,
+			AccountNumber: transfer.BankAccount.AccountNumber,
+			AccountHolder: transfer.BankAccount.AccountHolder,
+		},
+	}
+	transaction.Begin(transaction.Timestamp)
+
+	if !transfer.Amount.IsPositive() {
+		transaction.Details = "Amount must be positive"
+		p.recordFailure(transaction, models.FailureInvalidAmount)
+		return nil, ErrBankTransferAmount
+	}
+	if _, err := models.CurrencyExponent(transfer.Amount.Currency()); err != nil {
+		transaction.Details = "Unsupported currency"
+		p.recordFailure(transaction, models.FailureUnsupportedCurrency)
+		return nil, err
+	}
+	user, err := p.userRepo.FindByID(userID)
+	if err != nil {
+		transaction.Details = "Invalid customer ID"
+		p.recordFailure(transaction, models.FailureInvalidCustomer)
+		return nil, ErrInvalidCustomer
+	}
+	if reason, details, err := customerPaymentError(user); err != nil {
+		transaction.Details = details
+		p.recordFailure(transaction, reason)
+		return nil, err
+	}
+
+	transaction.ActivityType = activity
+	transaction.Details = "Waiting for bank confirmation"
+	if direction == bankrails.Withdrawal {
+		entry, err := p.ledger.Hold(userID, transfer.Amount, "Withdrawal to "+transfer.BankAccount.BankCode+" "+transfer.BankAccount.AccountNumber)
+		if errors.Is(err, ErrInsufficientBalance) {
+			transaction.ActivityType = failedActivity
+			transaction.Details = "Insufficient balance"
+			p.recordFailure(transaction, models.FailureInsufficientBalance)
+			return nil, err
+		}
+		if err != nil {
+			transaction.ActivityType = failedActivity
+			transaction.Details = "Failed to post ledger entry"
+			p.recordFailure(transaction, models.FailureLedgerError)
+			return nil, err
+		}
+		transaction.JournalEntryID = entry.ID
+	}
+
+	trx, err := p.transactionRepo.CreateTransaction(transaction)
+	if err != nil {
+		// Tanpa transaksi PENDING, ResumeBankTransfers tidak akan menemukan dana yang ditahan ini
+		if direction == bankrails.Withdrawal {
+			if _, releaseErr := p.ledger.ReleaseHold(userID, transfer.Amount, transaction.JournalEntryID+":released", "Withdrawal hold "+transaction.JournalEntryID+" released"); releaseErr != nil {
+				log.Printf("Failed to release withdrawal hold %s: %v", transaction.JournalEntryID, releaseErr)
+			}
+		}
+		return nil, err
+	}
+	if err := p.bankRail.Submit(bankInstruction(*trx), p.confirmBankTransfer); err != nil {
+		p.rejectBankTransfer(*trx, models.FailureBankUnavailable, "Bank did not accept the transfer")
+		return nil, ErrBankRailUnavailable
+	}
+
+	// Bank rail sinkron sudah mengonfirmasi sebelum Submit kembali, jadi status terbaru dibaca ulang
+	if current, err := p.transactionRepo.FindByID(trx.ID); err == nil {
+		trx = current
+	}
+	transferResponse := mapper.TransactionModelToBankTransferResponse(trx)
+	return &transferResponse, nil
+}
+
+// ResumeBankTransfers mengirim ulang deposit dan withdrawal yang masih PENDING saat startup,
+// karena konfirmasi yang ditunggu sebelum restart tidak akan pernah datang
+func (p *transactionService) ResumeBankTransfers() error {
+	transactions, err := p.transactionRepo.FindAllTransaction()
+	if err != nil {
+		return err
+	}
+	var pending []models.Transaction
+	for _, trx := range transactions {
+		if (trx.ActivityType == models.DepositActivity || trx.ActivityType == models.WithdrawalActivity) && trx.Status == models.TransactionPending {
+			pending = append(pending, trx)
+		}
+	}
+	for _, trx := range pending {
+		if err := p.bankRail.Submit(bankInstruction(trx), p.confirmBankTransfer); err != nil {
+			log.Printf("Failed to resubmit bank transfer %s: %v", trx.ID, err)
+		}
+	}
+	if len(pending) > 0 {
+		log.Printf("Resubmitted %d pending bank transfers", len(pending))
+	}
+	return nil
+}
+
+// confirmBankTransfer menyelesaikan transfer sesuai konfirmasi bank. Konfirmasi untuk transfer
+// yang sudah selesai diabaikan, sehingga konfirmasi ganda tidak memindahkan dana dua kali.
+func (p *transactionService) confirmBankTransfer(confirmation bankrails.Confirmation) {
+	p.bankMu.Lock()
+	defer p.bankMu.Unlock()
+
+	trx, err := p.transactionRepo.FindByID(confirmation.InstructionID)
+	if err != nil || trx.Status != models.TransactionPending {
+		return
+	}
+	trx.BankReference = confirmation.Reference
+	// Ledger dicatat sebelum status transaksi. Bila update status gagal dan transfer dikirim ulang,
+	// hasil yang sudah ada di ledger dipakai agar dana tidak dipindahkan dua kali.
+	if settled, ok := p.bankTransferOutcome(trx.ID); ok {
+		confirmation.Succeeded = settled
+	}
+	if !confirmation.Succeeded {
+		p.rejectBankTransfer(*trx, models.FailureBankRejected, "Rejected by bank: "+confirmation.Reason)
+		return
+	}
+
+	var entry *models.JournalEntry
+	if trx.ActivityType == models.WithdrawalActivity {
+		entry, err = p.ledger.PayoutHold(trx.CustomerID, trx.Amount, trx.ID, "Withdrawal "+trx.ID+" paid out by bank")
+	} else {
+		entry, err = p.ledger.Deposit(trx.CustomerID, trx.Amount, trx.ID, "Deposit "+trx.ID+" received from bank")
+	}
+	if err != nil {
+		// Tetap PENDING; konfirmasinya diminta ulang saat startup berikutnya
+		log.Printf("Failed to settle bank transfer %s: %v", trx.ID, err)
+		return
+	}
+	trx.JournalEntryID = entry.ID
+	trx.Details = "Confirmed by bank"
+	if err := trx.TransitionTo(models.TransactionSucceeded, confirmation.ConfirmedAt); err != nil {
+		log.Printf("Failed to mark bank transfer %s as succeeded: %v", trx.ID, err)
+		return
+	}
+	if err := p.transactionRepo.UpdateTransaction(*trx); err != nil {
+		log.Printf("Failed to update bank transfer %s: %v", trx.ID, err)
+	}
+}
+
+// rejectBankTransfer menandai transfer PENDING sebagai gagal dan mengembalikan dana withdrawal yang ditahan
+func (p *transactionService) rejectBankTransfer(trx models.Transaction, reason models.FailureReason, details string) {
+	if trx.ActivityType == models.WithdrawalActivity {
+		if _, err := p.ledger.ReleaseHold(trx.CustomerID, trx.Amount, returnedReference(trx.ID), "Withdrawal "+trx.ID+" returned"); err != nil {
+			log.Printf("Failed to release withdrawal %s: %v", trx.ID, err)
+			return
+		}
+		trx.ActivityType = models.FailedWithdrawal
+	} else {
+		trx.ActivityType = models.FailedDeposit
+	}
+	trx.Details = details
+	if err := trx.Fail(reason, time.Now()); err != nil {
+		log.Printf("Failed to mark bank transfer %s as failed: %v", trx.ID, err)
+		return
+	}
+	if err := p.transactionRepo.UpdateTransaction(trx); err != nil {
+		log.Printf("Failed to update bank transfer %s: %v", trx.ID, err)
+	}
+}
+
+// bankTransferOutcome melaporkan apakah ledger sudah menyelesaikan (true) atau mengembalikan (false) transfer id
+func (p *transactionService) bankTransferOutcome(id string) (settled bool, ok bool) {
+	if _, err := p.ledger.EntryByReference(id); err == nil {
+		return true, true
+	}
+	if _, err := p.ledger.EntryByReference(returnedReference(id)); err == nil {
+		return false, true
+	}
+	return false, false
+}
+
+func returnedReference(id string) string {
+	return id + ":returned"
+}
+
+func bankInstruction(trx models.Transaction) bankrails.Instruction {
+	direction := bankrails.Deposit
+	if trx.ActivityType == models.WithdrawalActivity {
+		direction = bankrails.Withdrawal
+	}
+	instruction := bankrails.Instruction{ID: trx.ID, Direction: direction, Amount: trx.Amount}
+	if trx.BankAccount != nil {
+		instruction.Account = *trx.BankAccount
+	}
+	return instruction
+}
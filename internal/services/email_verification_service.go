@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-json/internal/dtos/request"
+	"go-json/internal/models"
+	"go-json/internal/notifications"
+	"go-json/internal/repositories"
+	"go-json/internal/security"
+	"go-json/internal/validators"
+	"go-json/utils"
+	"log"
+	"time"
+)
+
+var (
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+	ErrAccountNotVerified       = errors.New("email address has not been verified")
+	ErrVerificationResendLimit  = errors.New("a verification email was sent recently, try again later")
+)
+
+// VerificationResendError membawa sisa waktu tunggu; errors.Is(err, ErrVerificationResendLimit) bernilai true
+type VerificationResendError struct {
+	RetryAfter time.Duration
+}
+
+func (e *VerificationResendError) Error() string {
+	return ErrVerificationResendLimit.Error()
+}
+
+func (e *VerificationResendError) Is(target error) bool {
+	return target == ErrVerificationResendLimit
+}
+
+type EmailVerificationConfig struct {
+	TokenTTL       time.Duration
+	ResendCooldown time.Duration
+}
+
+func EmailVerificationConfigFromEnv() EmailVerificationConfig {
+	return EmailVerificationConfig{
+		TokenTTL:       utils.DurationFromEnv("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		ResendCooldown: utils.DurationFromEnv("EMAIL_VERIFICATION_RESEND_COOLDOWN", time.Minute),
+	}
+}
+
+type EmailVerificationService interface {
+	SendVerification(user models.User) error
+	Resend(userID string) error
+	Verify(verify request.VerifyEmailRequest) error
+}
+
+type emailVerificationService struct {
+	userRepo    repositories.UserRepository
+	oneTimeRepo repositories.OneTimeTokenRepository
+	auditRepo   repositories.AuditRepository
+	notifier    notifications.Notifier
+	config      EmailVerificationConfig
+}
+
+func NewEmailVerificationService(userRepo repositories.UserRepository, oneTimeRepo repositories.OneTimeTokenRepository, auditRepo repositories.AuditRepository, notifier notifications.Notifier, config EmailVerificationConfig) EmailVerificationService {
+	return &emailVerificationService{userRepo: userRepo, oneTimeRepo: oneTimeRepo, auditRepo: auditRepo, notifier: notifier, config: config}
+}
+
+// SendVerification menerbitkan token verifikasi baru; token yang dikirim sebelumnya tidak berlaku lagi
+func (s *emailVerificationService) SendVerification(user models.User) error {
+	token, expiresAt, err := issueOneTimeToken(s.oneTimeRepo, user.ID, models.EmailVerificationToken, s.config.TokenTTL)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Use this token to verify your email address: %s\nThe token expires at %s.", token, expiresAt.Format(time.RFC3339))
+	return s.notifier.Send(notifications.Notification{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body:    body,
+	})
+}
+
+// Resend mengirim ulang token verifikasi, paling cepat sekali per ResendCooldown
+func (s *emailVerificationService) Resend(userID string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+	if latest, err := s.oneTimeRepo.FindLatestByUserID(user.ID, models.EmailVerificationToken); err == nil {
+		if wait := time.Until(latest.CreatedAt.Add(s.config.ResendCooldown)); wait > 0 {
+			return &VerificationResendError{RetryAfter: wait}
+		}
+	}
+	return s.SendVerification(*user)
+}
+
+// Verify memakai token verifikasi. Akun PENDING menjadi ACTIVE; status lain tidak diubah.
+func (s *emailVerificationService) Verify(verify request.VerifyEmailRequest) error {
+	if err := validators.NewCustomerValidator().Validate(verify); err != nil {
+		return err
+	}
+	consumed, err := s.oneTimeRepo.Consume(models.EmailVerificationToken, security.HashOneTimeToken(verify.Token))
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+	user, err := s.userRepo.FindByID(consumed.UserID)
+	if err != nil {
+		return ErrInvalidVerificationToken
+	}
+
+	now := time.Now()
+	if err := s.userRepo.MarkEmailVerified(user.ID, now); err != nil {
+		return err
+	}
+
+	_, err = s.auditRepo.Record(models.AuditEvent{
+		ActorID:   user.ID,
+		Action:    models.AuditVerifyEmail,
+		Resource:  "user:" + user.ID,
+		Outcome:   models.AuditAllowed,
+		Timestamp: now,
+	})
+	if err != nil {
+		log.Printf("Failed to record audit event: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-json/internal/models"
+	"time"
+)
+
+var ErrExchangeRateNotFound = errors.New("exchange rate not found")
+
+type FXRateProvider interface {
+	Quote(from, to models.Currency, at time.Time) (*models.ExchangeRate, error)
+}
+
+type fxRateProvider struct {
+	rates []models.FXRate
+}
+
+// NewFXRateProvider memakai kurs dari fx_rates.json. Kurs yang tidak valid
+// menggagalkan startup agar pembayaran tidak pernah memakai kurs yang salah.
+func NewFXRateProvider(rates []models.FXRate) (FXRateProvider, error) {
+	for i, rate := range rates {
+		quote := models.ExchangeRate{From: rate.From, To: rate.To, Rate: rate.Rate}
+		if err := quote.Validate(); err != nil {
+			return nil, fmt.Errorf("fx rate #%d: %w", i+1, err)
+		}
+		if rate.From == rate.To {
+			return nil, fmt.Errorf("fx rate #%d: from and to must differ", i+1)
+		}
+		if rate.EffectiveTo != nil && !rate.EffectiveTo.After(rate.EffectiveFrom) {
+			return nil, fmt.Errorf("fx rate #%d: effective_to must be after effective_from", i+1)
+		}
+	}
+	return &fxRateProvider{rates: rates}, nil
+}
+
+// Quote mencari kurs from/to yang berlaku pada waktu at. Bila hanya ada kurs
+// kebalikannya (to/from), kurs itu yang dikembalikan; models.ExchangeRate.Convert
+// bisa mengonversi ke dua arah.
+func (f *fxRateProvider) Quote(from, to models.Currency, at time.Time) (*models.ExchangeRate, error) {
+	if from == to {
+		return &models.ExchangeRate{From: from, To: to, Rate: "1"}, nil
+	}
+
+	if rate := f.find(from, to, at); rate != nil {
+		return rate, nil
+	}
+	if rate := f.find(to, from, at); rate != nil {
+		return rate, nil
+	}
+	return nil, fmt.Errorf("%w: %s/%s", ErrExchangeRateNotFound, from, to)
+}
+
+// find memilih kurs dengan effective_from paling baru bila ada rentang yang tumpang tindih
+func (f *fxRateProvider) find(from, to models.Currency, at time.Time) *models.ExchangeRate {
+	var latest *models.FXRate
+	for i, rate := range f.rates {
+		if rate.From != from || rate.To != to || !rate.IsEffectiveAt(at) {
+			continue
+		}
+		if latest == nil || rate.EffectiveFrom.After(latest.EffectiveFrom) {
+			latest = &f.rates[i]
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	return &models.ExchangeRate{From: latest.From, To: latest.To, Rate: latest.Rate}
+}
@@ -0,0 +1,535 @@
+package services
+
+import (
+	"errors"
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	"log"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrInsufficientBalance = errors.New("insufficient balance")
+
+type LedgerService interface {
+	Transfer(fromUserID, toUserID string, amount models.Money, description string) (*models.JournalEntry, error)
+	TransferWithConversion(fromUserID, toUserID string, debit, credit models.Money, description string) (*models.JournalEntry, error)
+	Hold(userID string, amount models.Money, description string) (*models.JournalEntry, error)
+	ReleaseHold(userID string, amount models.Money, reference, description string) (*models.JournalEntry, error)
+	CaptureHold(fromUserID, toUserID string, amount models.Money, description string) (*models.JournalEntry, error)
+	Deposit(userID string, amount models.Money, reference, description string) (*models.JournalEntry, error)
+	PayoutHold(userID string, amount models.Money, reference, description string) (*models.JournalEntry, error)
+	FundSandbox(userID string, amount models.Money) (*models.JournalEntry, error)
+	PostEntry(entry models.JournalEntry) (*models.JournalEntry, error)
+	EntryByReference(reference string) (*models.JournalEntry, error)
+	WalletAccount(userID string, currency models.Currency) (*models.LedgerAccount, error)
+	Balance(userID string, currency models.Currency) (models.Money, error)
+	Balances(userID string) (wallets, held []models.Money, err error)
+	Reconcile() error
+}
+
+type ledgerService struct {
+	ledgerRepo repositories.LedgerRepository
+	userRepo   repositories.UserRepository
+	mu         sync.Mutex
+}
+
+func NewLedgerService(ledgerRepo repositories.LedgerRepository, userRepo repositories.UserRepository) LedgerService {
+	return &ledgerService{ledgerRepo: ledgerRepo, userRepo: userRepo}
+}
+
+// Transfer mendebit wallet pengirim dan mengkredit wallet penerima dalam satu journal entry
+func (l *ledgerService) Transfer(fromUserID, toUserID string, amount models.Money, description string) (*models.JournalEntry, error) {
+	return l.TransferWithConversion(fromUserID, toUserID, amount, amount, description)
+}
+
+// TransferWithConversion mendebit wallet pengirim dalam mata uang debit dan mengkredit
+// wallet penerima dalam mata uang credit. Bila mata uangnya berbeda, selisihnya dicatat
+// di akun sistem FX_CONVERSION per mata uang sehingga setiap mata uang tetap seimbang.
+func (l *ledgerService) TransferWithConversion(fromUserID, toUserID string, debit, credit models.Money, description string) (*models.JournalEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if debit.Currency() == credit.Currency() && debit != credit {
+		return nil, errors.New("debit and credit must be equal in the same currency")
+	}
+
+	from, err := l.walletAccount(fromUserID, debit.Currency())
+	if err != nil {
+		return nil, err
+	}
+	to, err := l.walletAccount(toUserID, credit.Currency())
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := l.ledgerRepo.AccountBalance(from.ID)
+	if err != nil {
+		return nil, err
+	}
+	cmp, err := balance.Cmp(debit)
+	if err != nil {
+		return nil, err
+	}
+	if cmp < 0 {
+		return nil, ErrInsufficientBalance
+	}
+
+	postings := []models.Posting{
+		{AccountID: from.ID, Direction: models.Debit, Amount: debit},
+		{AccountID: to.ID, Direction: models.Credit, Amount: credit},
+	}
+	if debit.Currency() != credit.Currency() {
+		fxFrom, err := l.systemAccount(models.FXConversionAccount, debit.Currency())
+		if err != nil {
+			return nil, err
+		}
+		fxTo, err := l.systemAccount(models.FXConversionAccount, credit.Currency())
+		if err != nil {
+			return nil, err
+		}
+		postings = append(postings,
+			models.Posting{AccountID: fxFrom.ID, Direction: models.Credit, Amount: debit},
+			models.Posting{AccountID: fxTo.ID, Direction: models.Debit, Amount: credit},
+		)
+	}
+
+	entry, err := l.post(models.JournalEntry{
+		Description: description,
+		Timestamp:   time.Now(),
+		Postings:    postings,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.syncUserBalance(fromUserID); err != nil {
+		return nil, err
+	}
+	if err := l.syncUserBalance(toUserID); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Hold memindahkan dana dari wallet user ke akun HOLD miliknya; dana itu tidak bisa dipakai sampai dilepas atau di-capture
+func (l *ledgerService) Hold(userID string, amount models.Money, description string) (*models.JournalEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	wallet, err := l.walletAccount(userID, amount.Currency())
+	if err != nil {
+		return nil, err
+	}
+	hold, err := l.holdAccount(userID, amount.Currency())
+	if err != nil {
+		return nil, err
+	}
+	return l.move(wallet, hold, amount, "", description, userID)
+}
+
+// ReleaseHold mengembalikan dana dari akun HOLD ke wallet user
+func (l *ledgerService) ReleaseHold(userID string, amount models.Money, reference, description string) (*models.JournalEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hold, err := l.holdAccount(userID, amount.Currency())
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := l.walletAccount(userID, amount.Currency())
+	if err != nil {
+		return nil, err
+	}
+	return l.move(hold, wallet, amount, reference, description, userID)
+}
+
+// CaptureHold memindahkan dana yang ditahan milik fromUserID ke wallet toUserID
+func (l *ledgerService) CaptureHold(fromUserID, toUserID string, amount models.Money, description string) (*models.JournalEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hold, err := l.holdAccount(fromUserID, amount.Currency())
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := l.walletAccount(toUserID, amount.Currency())
+	if err != nil {
+		return nil, err
+	}
+	return l.move(hold, wallet, amount, "", description, fromUserID, toUserID)
+}
+
+// Deposit mengkredit wallet user dengan dana yang sudah dikonfirmasi masuk oleh bank
+func (l *ledgerService) Deposit(userID string, amount models.Money, reference, description string) (*models.JournalEntry, error) {
+	return l.fund(models.BankSettlementAccount, userID, amount, reference, description)
+}
+
+// PayoutHold mengirim dana yang ditahan untuk withdrawal keluar ke bank
+func (l *ledgerService) PayoutHold(userID string, amount models.Money, reference, description string) (*models.JournalEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hold, err := l.holdAccount(userID, amount.Currency())
+	if err != nil {
+		return nil, err
+	}
+	settlement, err := l.systemAccount(models.BankSettlementAccount, amount.Currency())
+	if err != nil {
+		return nil, err
+	}
+	return l.move(hold, settlement, amount, reference, description, userID)
+}
+
+// FundSandbox memberi saldo awal sandbox ke user baru
+func (l *ledgerService) FundSandbox(userID string, amount models.Money) (*models.JournalEntry, error) {
+	return l.fund(models.SandboxFundingAccount, userID, amount, "", "Sandbox starting balance")
+}
+
+func (l *ledgerService) PostEntry(entry models.JournalEntry) (*models.JournalEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.post(entry)
+}
+
+func (l *ledgerService) EntryByReference(reference string) (*models.JournalEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ledgerRepo.FindJournalEntryByReference(reference)
+}
+
+func (l *ledgerService) WalletAccount(userID string, currency models.Currency) (*models.LedgerAccount, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.walletAccount(userID, currency)
+}
+
+func (l *ledgerService) Balance(userID string, currency models.Currency) (models.Money, error) {
+	account, err := l.WalletAccount(userID, currency)
+	if err != nil {
+		return models.Money{}, err
+	}
+	return l.ledgerRepo.AccountBalance(account.ID)
+}
+
+// Balances mengembalikan saldo wallet dan dana yang ditahan milik userID langsung dari ledger.
+// Akun yang saldonya nol tidak ikut dikembalikan.
+func (l *ledgerService) Balances(userID string) ([]models.Money, []models.Money, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	wallets, err := l.nonZeroBalances(userID, models.WalletAccount)
+	if err != nil {
+		return nil, nil, err
+	}
+	held, err := l.nonZeroBalances(userID, models.HoldAccount)
+	if err != nil {
+		return nil, nil, err
+	}
+	return wallets, held, nil
+}
+
+func (l *ledgerService) nonZeroBalances(userID string, accountType models.AccountType) ([]models.Money, error) {
+	accounts, err := l.ledgerRepo.FindAccountsByUserID(userID, accountType)
+	if err != nil {
+		return nil, err
+	}
+	var balances []models.Money
+	for _, account := range accounts {
+		balance, err := l.ledgerRepo.AccountBalance(account.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !balance.IsZero() {
+			balances = append(balances, balance)
+		}
+	}
+	return balances, nil
+}
+
+// Reconcile memastikan setiap user punya wallet DefaultCurrency di ledger dan menyamakan
+// saldo di models.User dengan saldo ledger, yang menjadi sumber kebenaran.
+func (l *ledgerService) Reconcile() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	users, err := l.userRepo.FindAll()
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		account, err := l.walletAccount(user.ID, models.DefaultCurrency)
+		if err != nil {
+			return err
+		}
+		balance, err := l.ledgerRepo.AccountBalance(account.ID)
+		if err != nil {
+			return err
+		}
+		if user.Balance != balance {
+			log.Printf("Ledger reconcile: user %s balance %s differs from ledger %s", user.ID, user.Balance, balance)
+		}
+		if err := l.syncUserBalance(user.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *ledgerService) post(entry models.JournalEntry) (*models.JournalEntry, error) {
+	if len(entry.Postings) < 2 {
+		return nil, errors.New("journal entry needs at least two postings")
+	}
+
+	// Selisih kredit dan debit dihitung per mata uang dan harus nol untuk setiap mata uang
+	totals := map[models.Currency]models.Money{}
+	for _, posting := range entry.Postings {
+		if !posting.Amount.IsPositive() {
+			return nil, errors.New("posting amount must be positive")
+		}
+		account, err := l.ledgerRepo.FindAccountByID(posting.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		if account.AccountCurrency() != posting.Amount.Currency() {
+			return nil, models.ErrCurrencyMismatch
+		}
+
+		total := totals[posting.Amount.Currency()]
+		if total.IsZero() {
+			total = models.NewMoney(0, posting.Amount.Currency())
+		}
+
+		switch posting.Direction {
+		case models.Debit:
+			total, err = total.Sub(posting.Amount)
+		case models.Credit:
+			total, err = total.Add(posting.Amount)
+		default:
+			return nil, errors.New("invalid posting direction")
+		}
+		if err != nil {
+			return nil, err
+		}
+		totals[posting.Amount.Currency()] = total
+	}
+
+	for _, total := range totals {
+		if !total.IsZero() {
+			return nil, errors.New("journal entry is not balanced")
+		}
+	}
+
+	return l.ledgerRepo.CreateJournalEntry(entry)
+}
+
+// walletAccount membuat wallet baru bila belum ada. Wallet DefaultCurrency mendapat saldo
+// awal dari models.User.Balance lewat akun sistem OPENING_BALANCE; wallet lain mulai dari nol.
+func (l *ledgerService) walletAccount(userID string, currency models.Currency) (*models.LedgerAccount, error) {
+	if _, err := models.CurrencyExponent(currency); err != nil {
+		return nil, err
+	}
+
+	account, err := l.ledgerRepo.FindAccountByUserID(userID, models.WalletAccount, currency)
+	if err == nil {
+		return account, nil
+	}
+
+	user, err := l.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err = l.ledgerRepo.CreateAccount(models.LedgerAccount{
+		UserID:    userID,
+		Type:      models.WalletAccount,
+		Currency:  currency,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if currency == models.DefaultCurrency && user.Balance.IsPositive() {
+		opening, err := l.systemAccount(models.OpeningBalanceAccount, currency)
+		if err != nil {
+			return nil, err
+		}
+		_, err = l.post(models.JournalEntry{
+			Description: "Opening balance",
+			Timestamp:   time.Now(),
+			Postings: []models.Posting{
+				{AccountID: opening.ID, Direction: models.Debit, Amount: user.Balance},
+				{AccountID: account.ID, Direction: models.Credit, Amount: user.Balance},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return account, nil
+}
+
+// fund mengkredit wallet user dari akun sistem di luar wallet. Akun sistem itu boleh bersaldo
+// negatif karena mewakili uang yang datang dari luar buku besar.
+func (l *ledgerService) fund(source models.AccountType, userID string, amount models.Money, reference, description string) (*models.JournalEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry, ok := l.posted(reference); ok {
+		return entry, nil
+	}
+
+	wallet, err := l.walletAccount(userID, amount.Currency())
+	if err != nil {
+		return nil, err
+	}
+	system, err := l.systemAccount(source, amount.Currency())
+	if err != nil {
+		return nil, err
+	}
+	entry, err := l.post(models.JournalEntry{
+		Reference:   reference,
+		Description: description,
+		Timestamp:   time.Now(),
+		Postings: []models.Posting{
+			{AccountID: system.ID, Direction: models.Debit, Amount: amount},
+			{AccountID: wallet.ID, Direction: models.Credit, Amount: amount},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := l.syncUserBalance(userID); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (l *ledgerService) holdAccount(userID string, currency models.Currency) (*models.LedgerAccount, error) {
+	account, err := l.ledgerRepo.FindAccountByUserID(userID, models.HoldAccount, currency)
+	if err == nil {
+		return account, nil
+	}
+	return l.ledgerRepo.CreateAccount(models.LedgerAccount{
+		UserID:    userID,
+		Type:      models.HoldAccount,
+		Currency:  currency,
+		CreatedAt: time.Now(),
+	})
+}
+
+// move mendebit from dan mengkredit to dalam satu mata uang, lalu menyamakan saldo userIDs
+func (l *ledgerService) move(from, to *models.LedgerAccount, amount models.Money, reference, description string, userIDs ...string) (*models.JournalEntry, error) {
+	if entry, ok := l.posted(reference); ok {
+		return entry, nil
+	}
+
+	balance, err := l.ledgerRepo.AccountBalance(from.ID)
+	if err != nil {
+		return nil, err
+	}
+	cmp, err := balance.Cmp(amount)
+	if err != nil {
+		return nil, err
+	}
+	if cmp < 0 {
+		return nil, ErrInsufficientBalance
+	}
+
+	entry, err := l.post(models.JournalEntry{
+		Reference:   reference,
+		Description: description,
+		Timestamp:   time.Now(),
+		Postings: []models.Posting{
+			{AccountID: from.ID, Direction: models.Debit, Amount: amount},
+			{AccountID: to.ID, Direction: models.Credit, Amount: amount},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, userID := range userIDs {
+		if err := l.syncUserBalance(userID); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+// posted mengembalikan entry yang sudah dicatat dengan reference, agar posting ulang untuk
+// transfer yang sama tidak memindahkan dana lagi. Dipanggil dengan l.mu terkunci.
+func (l *ledgerService) posted(reference string) (*models.JournalEntry, bool) {
+	if reference == "" {
+		return nil, false
+	}
+	entry, err := l.ledgerRepo.FindJournalEntryByReference(reference)
+	return entry, err == nil
+}
+
+func (l *ledgerService) systemAccount(accountType models.AccountType, currency models.Currency) (*models.LedgerAccount, error) {
+	account, err := l.ledgerRepo.FindSystemAccount(accountType, currency)
+	if err == nil {
+		return account, nil
+	}
+	return l.ledgerRepo.CreateAccount(models.LedgerAccount{
+		Type:      accountType,
+		Currency:  currency,
+		CreatedAt: time.Now(),
+	})
+}
+
+// syncUserBalance menyalin saldo semua wallet dan dana yang ditahan dari ledger ke models.User
+func (l *ledgerService) syncUserBalance(userID string) error {
+	user, err := l.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	accounts, err := l.ledgerRepo.FindAccountsByUserID(userID, models.WalletAccount)
+	if err != nil {
+		return err
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].AccountCurrency() < accounts[j].AccountCurrency()
+	})
+
+	balance := models.NewMoney(0, models.DefaultCurrency)
+	wallets := []models.Money{}
+	for _, account := range accounts {
+		accountBalance, err := l.ledgerRepo.AccountBalance(account.ID)
+		if err != nil {
+			return err
+		}
+		if account.AccountCurrency() == models.DefaultCurrency {
+			balance = accountBalance
+		}
+		wallets = append(wallets, accountBalance)
+	}
+
+	holds, err := l.ledgerRepo.FindAccountsByUserID(userID, models.HoldAccount)
+	if err != nil {
+		return err
+	}
+	sort.Slice(holds, func(i, j int) bool {
+		return holds[i].AccountCurrency() < holds[j].AccountCurrency()
+	})
+	var held []models.Money
+	for _, account := range holds {
+		accountBalance, err := l.ledgerRepo.AccountBalance(account.ID)
+		if err != nil {
+			return err
+		}
+		if accountBalance.IsPositive() {
+			held = append(held, accountBalance)
+		}
+	}
+
+	if user.Balance == balance && slices.Equal(user.Wallets, wallets) && slices.Equal(user.Held, held) {
+		return nil
+	}
+	return l.userRepo.UpdateBalances(user.ID, balance, wallets, held)
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"errors"
+	"go-json/internal/dtos/mapper"
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	"go-json/internal/security"
+	"log"
+	"net"
+	"time"
+)
+
+var ErrInvalidIP = errors.New("invalid IP address")
+
+// LockoutService dipakai admin untuk melihat dan membuka lockout login
+type LockoutService interface {
+	FindAllLockouts() ([]response.LockoutResponse, error)
+	UserLockout(userID string) (*response.LockoutResponse, error)
+	UnlockUser(actor security.Principal, userID string) error
+	UnlockIP(actor security.Principal, ip string) error
+}
+
+type lockoutService struct {
+	attemptRepo repositories.LoginAttemptRepository
+	userRepo    repositories.UserRepository
+	auditRepo   repositories.AuditRepository
+	config      LoginThrottleConfig
+}
+
+func NewLockoutService(attemptRepo repositories.LoginAttemptRepository, userRepo repositories.UserRepository, auditRepo repositories.AuditRepository, config LoginThrottleConfig) LockoutService {
+	return &lockoutService{attemptRepo: attemptRepo, userRepo: userRepo, auditRepo: auditRepo, config: config}
+}
+
+// FindAllLockouts mengembalikan username dan IP yang punya kegagalan aktif, termasuk yang sedang terkunci
+func (s *lockoutService) FindAllLockouts() ([]response.LockoutResponse, error) {
+	attempts, err := s.attemptRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	lockouts := []response.LockoutResponse{}
+	for _, attempt := range attempts {
+		if s.config.IsStale(attempt, now) {
+			continue
+		}
+		userID := ""
+		if attempt.Kind == models.AccountLoginAttempt {
+			if user, err := s.userRepo.FindByUsername(attempt.Key); err == nil {
+				userID = user.ID
+			}
+		}
+		lockouts = append(lockouts, mapper.LoginAttemptToLockoutResponse(attempt, userID, s.config.RetryAt(attempt, now), now))
+	}
+	return lockouts, nil
+}
+
+func (s *lockoutService) UserLockout(userID string) (*response.LockoutResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	now := time.Now()
+	attempt, err := s.attemptRepo.Find(models.AccountLoginAttempt, user.Username)
+	if err != nil || s.config.IsStale(*attempt, now) {
+		attempt = &models.LoginAttempt{Kind: models.AccountLoginAttempt, Key: user.Username}
+	}
+	lockout := mapper.LoginAttemptToLockoutResponse(*attempt, user.ID, s.config.RetryAt(*attempt, now), now)
+	return &lockout, nil
+}
+
+func (s *lockoutService) UnlockUser(actor security.Principal, userID string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if err := s.attemptRepo.Delete(models.AccountLoginAttempt, user.Username); err != nil {
+		return err
+	}
+	s.audit(actor, models.AuditUnlockAccount, "user:"+user.ID)
+	return nil
+}
+
+func (s *lockoutService) UnlockIP(actor security.Principal, ip string) error {
+	if net.ParseIP(ip) == nil {
+		return ErrInvalidIP
+	}
+	if err := s.attemptRepo.Delete(models.IPLoginAttempt, ip); err != nil {
+		return err
+	}
+	s.audit(actor, models.AuditUnlockIP, "ip:"+ip)
+	return nil
+}
+
+func (s *lockoutService) audit(actor security.Principal, action, resource string) {
+	_, err := s.auditRepo.Record(models.AuditEvent{
+		ActorID:   actor.UserID,
+		Action:    action,
+		Resource:  resource,
+		Outcome:   models.AuditAllowed,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to record audit event: %v", err)
+	}
+}
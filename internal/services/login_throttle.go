@@ -0,0 +1,239 @@
+package services
+
+import (
+	"errors"
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	"go-json/utils"
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrLoginThrottled     = errors.New("too many failed login attempts, try again later")
+)
+
+// LoginThrottledError membawa sisa waktu tunggu; errors.Is(err, ErrLoginThrottled) bernilai true
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return "login temporarily locked after too many failed attempts"
+	}
+	return ErrLoginThrottled.Error()
+}
+
+func (e *LoginThrottledError) Is(target error) bool {
+	return target == ErrLoginThrottled
+}
+
+type LoginThrottleConfig struct {
+	MaxAccountFailures int
+	MaxIPFailures      int
+	AccountDelayAfter  int
+	IPDelayAfter       int
+	BaseDelay          time.Duration
+	FailureWindow      time.Duration
+	LockoutDuration    time.Duration
+}
+
+func LoginThrottleConfigFromEnv() LoginThrottleConfig {
+	return LoginThrottleConfig{
+		MaxAccountFailures: utils.IntFromEnv("LOGIN_MAX_ACCOUNT_FAILURES", 5),
+		MaxIPFailures:      utils.IntFromEnv("LOGIN_MAX_IP_FAILURES", 20),
+		AccountDelayAfter:  utils.IntFromEnv("LOGIN_ACCOUNT_DELAY_AFTER", 3),
+		IPDelayAfter:       utils.IntFromEnv("LOGIN_IP_DELAY_AFTER", 10),
+		BaseDelay:          utils.DurationFromEnv("LOGIN_BASE_DELAY", time.Second),
+		FailureWindow:      utils.DurationFromEnv("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		LockoutDuration:    utils.DurationFromEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+	}
+}
+
+// IsStale bernilai true bila kegagalan terakhir sudah di luar window dan tidak sedang terkunci,
+// sehingga hitungannya dianggap mulai dari nol lagi.
+func (c LoginThrottleConfig) IsStale(attempt models.LoginAttempt, now time.Time) bool {
+	return !attempt.IsLockedAt(now) && now.Sub(attempt.LastFailureAt) > c.FailureWindow
+}
+
+// RetryAt adalah waktu paling awal percobaan login berikutnya boleh dilakukan.
+// Setelah batas delay terlewati, jeda berlipat dua setiap kegagalan sampai maksimal LockoutDuration.
+func (c LoginThrottleConfig) RetryAt(attempt models.LoginAttempt, now time.Time) time.Time {
+	if attempt.IsLockedAt(now) {
+		return *attempt.LockedUntil
+	}
+	delayAfter := c.AccountDelayAfter
+	if attempt.Kind == models.IPLoginAttempt {
+		delayAfter = c.IPDelayAfter
+	}
+	if c.IsStale(attempt, now) || attempt.Failures < delayAfter {
+		return time.Time{}
+	}
+	delay := c.LockoutDuration
+	if shift := attempt.Failures - delayAfter; shift < 20 {
+		delay = min(c.BaseDelay<<shift, c.LockoutDuration)
+	}
+	return attempt.LastFailureAt.Add(delay)
+}
+
+func (c LoginThrottleConfig) maxFailures(kind models.LoginAttemptKind) int {
+	if kind == models.IPLoginAttempt {
+		return c.MaxIPFailures
+	}
+	return c.MaxAccountFailures
+}
+
+// LoginThrottle membatasi login gagal per username dan per IP klien.
+// Setiap Check yang lolos harus diikuti Release setelah percobaan login selesai.
+type LoginThrottle interface {
+	Check(username, ip string) error
+	Release(username, ip string)
+	Failed(username, ip string) error
+	Succeeded(username string) error
+}
+
+type loginThrottle struct {
+	attemptRepo repositories.LoginAttemptRepository
+	auditRepo   repositories.AuditRepository
+	config      LoginThrottleConfig
+	mu          sync.Mutex
+	// inFlight menghitung percobaan yang sudah lolos Check tetapi belum di-Release
+	inFlight map[attemptKey]int
+}
+
+func NewLoginThrottle(attemptRepo repositories.LoginAttemptRepository, auditRepo repositories.AuditRepository, config LoginThrottleConfig) LoginThrottle {
+	return &loginThrottle{attemptRepo: attemptRepo, auditRepo: auditRepo, config: config, inFlight: map[attemptKey]int{}}
+}
+
+// Check mengembalikan *LoginThrottledError bila username atau IP masih harus menunggu.
+// Bila lolos, percobaan ini dicadangkan sampai Release. Percobaan yang sedang berjalan dihitung
+// seperti kegagalan, sehingga burst paralel tidak bisa melewati delay maupun batas lockout.
+func (t *loginThrottle) Check(username, ip string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+
+	var throttled *LoginThrottledError
+	for _, key := range t.keys(username, ip) {
+		attempt, err := t.attemptRepo.Find(key.kind, key.key)
+		if err != nil || t.config.IsStale(*attempt, now) {
+			attempt = &models.LoginAttempt{Kind: key.kind, Key: key.key}
+		}
+		retryAt := t.config.RetryAt(*attempt, now)
+		if inFlight := t.inFlight[key]; inFlight > 0 && t.reachesLimit(key.kind, attempt.Failures+inFlight) {
+			retryAt = maxTime(retryAt, now.Add(t.config.BaseDelay))
+		}
+		if !retryAt.After(now) {
+			continue
+		}
+		if throttled == nil || retryAt.Sub(now) > throttled.RetryAfter {
+			throttled = &LoginThrottledError{RetryAfter: retryAt.Sub(now), Locked: attempt.IsLockedAt(now)}
+		}
+	}
+	if throttled != nil {
+		return throttled
+	}
+	for _, key := range t.keys(username, ip) {
+		t.inFlight[key]++
+	}
+	return nil
+}
+
+// Release melepas cadangan dari Check
+func (t *loginThrottle) Release(username, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, key := range t.keys(username, ip) {
+		if t.inFlight[key] <= 1 {
+			delete(t.inFlight, key)
+			continue
+		}
+		t.inFlight[key]--
+	}
+}
+
+// reachesLimit bernilai true bila failures kegagalan sudah cukup untuk delay atau lockout
+func (t *loginThrottle) reachesLimit(kind models.LoginAttemptKind, failures int) bool {
+	delayAfter := t.config.AccountDelayAfter
+	if kind == models.IPLoginAttempt {
+		delayAfter = t.config.IPDelayAfter
+	}
+	return failures >= delayAfter || failures >= t.config.maxFailures(kind)
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// Failed menambah hitungan kegagalan username dan IP, lalu mengunci yang melewati batas
+func (t *loginThrottle) Failed(username, ip string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+
+	for _, key := range t.keys(username, ip) {
+		attempt, err := t.attemptRepo.Find(key.kind, key.key)
+		if err != nil || t.config.IsStale(*attempt, now) {
+			attempt = &models.LoginAttempt{Kind: key.kind, Key: key.key}
+		}
+		attempt.Failures++
+		attempt.LastFailureAt = now
+		if attempt.Failures >= t.config.maxFailures(key.kind) && !attempt.IsLockedAt(now) {
+			lockedUntil := now.Add(t.config.LockoutDuration)
+			attempt.LockedUntil = &lockedUntil
+			t.auditLockout(*attempt)
+		}
+		if err := t.attemptRepo.Save(*attempt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Succeeded mereset hitungan akun. Hitungan IP sengaja tidak direset supaya satu login
+// berhasil dari IP yang sama tidak menghapus jejak percobaan ke akun lain.
+func (t *loginThrottle) Succeeded(username string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.attemptRepo.Delete(models.AccountLoginAttempt, username)
+}
+
+type attemptKey struct {
+	kind models.LoginAttemptKind
+	key  string
+}
+
+func (t *loginThrottle) keys(username, ip string) []attemptKey {
+	keys := []attemptKey{{kind: models.AccountLoginAttempt, key: username}}
+	if ip != "" {
+		keys = append(keys, attemptKey{kind: models.IPLoginAttempt, key: ip})
+	}
+	return keys
+}
+
+func (t *loginThrottle) auditLockout(attempt models.LoginAttempt) {
+	_, err := t.auditRepo.Record(models.AuditEvent{
+		Action:    models.AuditLoginLockout,
+		Resource:  lockoutResource(attempt.Kind, attempt.Key),
+		Outcome:   models.AuditDenied,
+		Reason:    "too many failed login attempts",
+		Timestamp: attempt.LastFailureAt,
+	})
+	if err != nil {
+		log.Printf("Failed to record audit event: %v", err)
+	}
+}
+
+func lockoutResource(kind models.LoginAttemptKind, key string) string {
+	if kind == models.IPLoginAttempt {
+		return "ip:" + key
+	}
+	return "username:" + key
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-json/internal/dtos/mapper"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	"go-json/internal/security"
+	"go-json/internal/validators"
+	"log"
+	"slices"
+	"time"
+)
+
+var (
+	ErrMerchantNotFound          = errors.New("merchant not found")
+	ErrMerchantExists            = errors.New("a merchant profile already exists for this account")
+	ErrInvalidMerchant           = errors.New("invalid merchant ID")
+	ErrMerchantNotActive         = errors.New("merchant is not approved to accept payments yet")
+	ErrMerchantSuspended         = errors.New("merchant is suspended")
+	ErrInvalidMerchantTransition = errors.New("merchant status transition is not allowed")
+	ErrMerchantOwnerExists       = errors.New("user already owns this merchant")
+	ErrMerchantOwnerNotFound     = errors.New("user does not own this merchant")
+	ErrWalletOwnerRequired       = errors.New("the owner of the merchant's wallet cannot be removed")
+)
+
+const merchantRoleName = "merchant"
+
+// MerchantService mengelola profil merchant. Merchant dibuat oleh user-nya sendiri
+// lewat onboarding dan baru bisa menerima pembayaran setelah disetujui admin.
+type MerchantService interface {
+	Onboard(actor security.Principal, onboard request.MerchantRequest) (*response.MerchantResponse, error)
+	FindOwnMerchants(actor security.Principal) ([]response.MerchantResponse, error)
+	FindMerchant(actor security.Principal, merchantID string) (*response.MerchantResponse, error)
+	UpdateMerchant(actor security.Principal, merchantID string, update request.MerchantRequest) (*response.MerchantResponse, error)
+	FindAllMerchants(status models.MerchantStatus) ([]response.MerchantResponse, error)
+	ChangeStatus(actor security.Principal, merchantID string, change request.MerchantStatusRequest) (*response.MerchantResponse, error)
+	AddOwner(actor security.Principal, merchantID string, owner request.MerchantOwnerRequest) (*response.MerchantResponse, error)
+	RemoveOwner(actor security.Principal, merchantID, userID string) (*response.MerchantResponse, error)
+	ProvisionLegacyMerchants() error
+}
+
+type merchantService struct {
+	merchantRepo    repositories.MerchantRepository
+	userRepo        repositories.UserRepository
+	roleRepo        repositories.RoleRepository
+	transactionRepo repositories.TransactionRepository
+	auditRepo       repositories.AuditRepository
+}
+
+func NewMerchantService(merchantRepo repositories.MerchantRepository, userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, transactionRepo repositories.TransactionRepository, auditRepo repositories.AuditRepository) MerchantService {
+	return &merchantService{merchantRepo: merchantRepo, userRepo: userRepo, roleRepo: roleRepo, transactionRepo: transactionRepo, auditRepo: auditRepo}
+}
+
+// Onboard membuat merchant PENDING untuk wallet actor sendiri
+func (s *merchantService) Onboard(actor security.Principal, onboard request.MerchantRequest) (*response.MerchantResponse, error) {
+	if err := validators.NewCustomerValidator().Validate(onboard); err != nil {
+		return nil, err
+	}
+	if _, err := s.merchantRepo.FindByID(actor.UserID); err == nil {
+		return nil, ErrMerchantExists
+	}
+
+	now := time.Now()
+	merchant := mapper.MerchantRequestToModel(onboard)
+	merchant.ID = actor.UserID
+	merchant.Status = models.MerchantPending
+	merchant.OwnerIDs = []string{actor.UserID}
+	merchant.CreatedAt = now
+	merchant.UpdatedAt = now
+	created, err := s.merchantRepo.Create(merchant)
+	if err != nil {
+		return nil, err
+	}
+	s.audit(actor.UserID, models.AuditOnboardMerchant, created.ID, "")
+
+	merchantResponse := mapper.MerchantModelToResponse(*created)
+	return &merchantResponse, nil
+}
+
+func (s *merchantService) FindOwnMerchants(actor security.Principal) ([]response.MerchantResponse, error) {
+	merchants, err := s.merchantRepo.FindByOwnerID(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return merchantResponses(merchants), nil
+}
+
+// FindMerchant hanya mengembalikan merchant milik actor, kecuali actor boleh meninjau merchant
+func (s *merchantService) FindMerchant(actor security.Principal, merchantID string) (*response.MerchantResponse, error) {
+	merchant, err := s.merchantRepo.FindByID(merchantID)
+	if err != nil || (!merchant.IsOwnedBy(actor.UserID) && !actor.HasPermission(models.PermissionMerchantReview)) {
+		return nil, ErrMerchantNotFound
+	}
+	merchantResponse := mapper.MerchantModelToResponse(*merchant)
+	return &merchantResponse, nil
+}
+
+func (s *merchantService) UpdateMerchant(actor security.Principal, merchantID string, update request.MerchantRequest) (*response.MerchantResponse, error) {
+	if err := validators.NewCustomerValidator().Validate(update); err != nil {
+		return nil, err
+	}
+	merchant, err := s.merchantRepo.FindByID(merchantID)
+	if err != nil || !merchant.IsOwnedBy(actor.UserID) {
+		return nil, ErrMerchantNotFound
+	}
+
+	profile := mapper.MerchantRequestToModel(update)
+	merchant.LegalName = profile.LegalName
+	merchant.DisplayName = profile.DisplayName
+	merchant.CategoryCode = profile.CategoryCode
+	merchant.SettlementAccount = profile.SettlementAccount
+	merchant.UpdatedAt = time.Now()
+	if err := s.merchantRepo.Update(*merchant); err != nil {
+		return nil, err
+	}
+	s.audit(actor.UserID, models.AuditUpdateMerchant, merchant.ID, "")
+
+	merchantResponse := mapper.MerchantModelToResponse(*merchant)
+	return &merchantResponse, nil
+}
+
+// FindAllMerchants mengembalikan semua merchant; status kosong berarti tanpa filter
+func (s *merchantService) FindAllMerchants(status models.MerchantStatus) ([]response.MerchantResponse, error) {
+	merchants, err := s.merchantRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	if status != "" {
+		merchants = slices.DeleteFunc(merchants, func(merchant models.Merchant) bool {
+			return merchant.Status != status
+		})
+	}
+	return merchantResponses(merchants), nil
+}
+
+// ChangeStatus menyetujui, membekukan, atau mengaktifkan kembali merchant
+func (s *merchantService) ChangeStatus(actor security.Principal, merchantID string, change request.MerchantStatusRequest) (*response.MerchantResponse, error) {
+	if err := validators.NewCustomerValidator().Validate(change); err != nil {
+		return nil, err
+	}
+	if !models.IsKnownAccountReason(change.ReasonCode) {
+		return nil, ErrUnknownReasonCode
+	}
+	merchant, err := s.merchantRepo.FindByID(merchantID)
+	if err != nil {
+		return nil, ErrMerchantNotFound
+	}
+	previous := merchant.Status
+	if !previous.CanTransitionTo(change.Status) {
+		return nil, ErrInvalidMerchantTransition
+	}
+
+	merchant.Status = change.Status
+	merchant.StatusReason = string(change.ReasonCode)
+	merchant.UpdatedAt = time.Now()
+	if err := s.merchantRepo.Update(*merchant); err != nil {
+		return nil, err
+	}
+
+	reason := fmt.Sprintf("%s -> %s (%s)", previous, change.Status, change.ReasonCode)
+	if change.Note != "" {
+		reason += ": " + change.Note
+	}
+	s.audit(actor.UserID, models.AuditChangeMerchantStatus, merchant.ID, reason)
+
+	merchantResponse := mapper.MerchantModelToResponse(*merchant)
+	return &merchantResponse, nil
+}
+
+func (s *merchantService) AddOwner(actor security.Principal, merchantID string, owner request.MerchantOwnerRequest) (*response.MerchantResponse, error) {
+	if err := validators.NewCustomerValidator().Validate(owner); err != nil {
+		return nil, err
+	}
+	merchant, err := s.merchantRepo.FindByID(merchantID)
+	if err != nil {
+		return nil, ErrMerchantNotFound
+	}
+	if _, err := s.userRepo.FindByID(owner.UserID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	if merchant.IsOwnedBy(owner.UserID) {
+		return nil, ErrMerchantOwnerExists
+	}
+
+	merchant.OwnerIDs = append(merchant.OwnerIDs, owner.UserID)
+	merchant.UpdatedAt = time.Now()
+	if err := s.merchantRepo.Update(*merchant); err != nil {
+		return nil, err
+	}
+	s.audit(actor.UserID, models.AuditAddMerchantOwner, merchant.ID, "user:"+owner.UserID)
+
+	merchantResponse := mapper.MerchantModelToResponse(*merchant)
+	return &merchantResponse, nil
+}
+
+func (s *merchantService) RemoveOwner(actor security.Principal, merchantID, userID string) (*response.MerchantResponse, error) {
+	merchant, err := s.merchantRepo.FindByID(merchantID)
+	if err != nil {
+		return nil, ErrMerchantNotFound
+	}
+	if userID == merchant.ID {
+		return nil, ErrWalletOwnerRequired
+	}
+	if !merchant.IsOwnedBy(userID) {
+		return nil, ErrMerchantOwnerNotFound
+	}
+
+	merchant.OwnerIDs = slices.DeleteFunc(merchant.OwnerIDs, func(ownerID string) bool {
+		return ownerID == userID
+	})
+	merchant.UpdatedAt = time.Now()
+	if err := s.merchantRepo.Update(*merchant); err != nil {
+		return nil, err
+	}
+	s.audit(actor.UserID, models.AuditRemoveMerchantOwner, merchant.ID, "user:"+userID)
+
+	merchantResponse := mapper.MerchantModelToResponse(*merchant)
+	return &merchantResponse, nil
+}
+
+// ProvisionLegacyMerchants membuat merchant ACTIVE untuk data lama yang dibuat sebelum ada
+// merchants.json: user dengan role merchant dan user yang pernah menerima pembayaran.
+// Tanpa ini pembayaran ke merchant lama akan ditolak.
+func (s *merchantService) ProvisionLegacyMerchants() error {
+	users, err := s.userRepo.FindAll()
+	if err != nil {
+		return err
+	}
+	payees := map[string]bool{}
+	transactions, err := s.transactionRepo.FindAllTransaction()
+	if err != nil {
+		return err
+	}
+	for _, trx := range transactions {
+		if trx.ActivityType == models.PaymentActivity {
+			payees[trx.MerchantID] = true
+		}
+	}
+
+	provisioned := 0
+	for _, user := range users {
+		if _, err := s.merchantRepo.FindByID(user.ID); err == nil {
+			continue
+		}
+		if !payees[user.ID] && !s.hasMerchantRole(user.ID) {
+			continue
+		}
+		now := time.Now()
+		_, err := s.merchantRepo.Create(models.Merchant{
+			ID:          user.ID,
+			LegalName:   user.Username,
+			DisplayName: user.Username,
+			Status:      models.MerchantActive,
+			OwnerIDs:    []string{user.ID},
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		if err != nil {
+			return err
+		}
+		provisioned++
+	}
+	if provisioned > 0 {
+		log.Printf("Provisioned %d merchant profiles from existing users", provisioned)
+	}
+	return nil
+}
+
+func (s *merchantService) hasMerchantRole(userID string) bool {
+	userRoles, err := s.roleRepo.FindRoleByUserID(userID)
+	if err != nil {
+		return false
+	}
+	for _, userRole := range *userRoles {
+		if role, err := s.roleRepo.FindByRoleID(userRole.RoleID); err == nil && role.Name == merchantRoleName {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *merchantService) audit(actorID, action, merchantID, reason string) {
+	_, err := s.auditRepo.Record(models.AuditEvent{
+		ActorID:   actorID,
+		Action:    action,
+		Resource:  "merchant:" + merchantID,
+		Outcome:   models.AuditAllowed,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to record audit event: %v", err)
+	}
+}
+
+// merchantStatusError mengembalikan error untuk merchant yang belum atau tidak boleh menerima pembayaran
+func merchantStatusError(status models.MerchantStatus) error {
+	switch status {
+	case models.MerchantActive:
+		return nil
+	case models.MerchantSuspended:
+		return ErrMerchantSuspended
+	default:
+		return ErrMerchantNotActive
+	}
+}
+
+func merchantResponses(merchants []models.Merchant) []response.MerchantResponse {
+	merchantResponses := []response.MerchantResponse{}
+	for _, merchant := range merchants {
+		merchantResponses = append(merchantResponses, mapper.MerchantModelToResponse(merchant))
+	}
+	return merchantResponses
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	"go-json/internal/security"
+	"time"
+)
+
+// issueOneTimeToken mengganti token lama user untuk purpose yang sama dengan token baru,
+// lalu mengembalikan token mentahnya untuk dikirim lewat notifier.
+func issueOneTimeToken(repo repositories.OneTimeTokenRepository, userID string, purpose models.OneTimeTokenPurpose, ttl time.Duration) (string, time.Time, error) {
+	if err := repo.DeleteByUserID(userID, purpose); err != nil {
+		return "", time.Time{}, err
+	}
+	token, err := security.GenerateOneTimeToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	err = repo.Create(models.OneTimeToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: security.HashOneTimeToken(token),
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
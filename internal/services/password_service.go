@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-json/internal/dtos/request"
+	"go-json/internal/models"
+	"go-json/internal/notifications"
+	"go-json/internal/repositories"
+	"go-json/internal/security"
+	"go-json/internal/validators"
+	"log"
+	"time"
+)
+
+var (
+	ErrIncorrectPassword = errors.New("current password is incorrect")
+	ErrPasswordUnchanged = errors.New("new password must differ from the current password")
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+)
+
+type PasswordService interface {
+	ChangePassword(actor security.Principal, change request.ChangePasswordRequest, clientIP string) error
+	RequestReset(forgot request.ForgotPasswordRequest) error
+	ResetPassword(reset request.ResetPasswordRequest) error
+}
+
+type passwordService struct {
+	userRepo      repositories.UserRepository
+	refreshRepo   repositories.RefreshTokenRepository
+	revokedRepo   repositories.RevokedTokenRepository
+	oneTimeRepo   repositories.OneTimeTokenRepository
+	auditRepo     repositories.AuditRepository
+	token         security.TokenService
+	hasher        security.PasswordHasher
+	throttle      LoginThrottle
+	notifier      notifications.Notifier
+	resetTokenTTL time.Duration
+}
+
+func NewPasswordService(userRepo repositories.UserRepository, refreshRepo repositories.RefreshTokenRepository, revokedRepo repositories.RevokedTokenRepository, oneTimeRepo repositories.OneTimeTokenRepository, auditRepo repositories.AuditRepository, token security.TokenService, hasher security.PasswordHasher, throttle LoginThrottle, notifier notifications.Notifier, resetTokenTTL time.Duration) PasswordService {
+	return &passwordService{
+		userRepo:      userRepo,
+		refreshRepo:   refreshRepo,
+		revokedRepo:   revokedRepo,
+		oneTimeRepo:   oneTimeRepo,
+		auditRepo:     auditRepo,
+		token:         token,
+		hasher:        hasher,
+		throttle:      throttle,
+		notifier:      notifier,
+		resetTokenTTL: resetTokenTTL,
+	}
+}
+
+// ChangePassword mengganti password user yang sedang login. Sesi lain dicabut, sesi yang
+// dipakai untuk request ini tetap berlaku. Salah password lama dihitung seperti gagal login,
+// supaya access token curian tidak bisa dipakai menebak password.
+func (s *passwordService) ChangePassword(actor security.Principal, change request.ChangePasswordRequest, clientIP string) error {
+	if err := validators.NewCustomerValidator().Validate(change); err != nil {
+		return err
+	}
+	user, err := s.userRepo.FindByID(actor.UserID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+
+	if err := s.throttle.Check(user.Username, clientIP); err != nil {
+		return err
+	}
+	defer s.throttle.Release(user.Username, clientIP)
+	match, err := s.hasher.Compare(user.Password, change.CurrentPassword)
+	if err != nil || !match {
+		if err := s.throttle.Failed(user.Username, clientIP); err != nil {
+			log.Printf("Failed to record failed login attempt: %v", err)
+		}
+		return ErrIncorrectPassword
+	}
+	if change.NewPassword == change.CurrentPassword {
+		return ErrPasswordUnchanged
+	}
+
+	if err := s.setPassword(user, change.NewPassword); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = s.revokedRepo.Revoke(models.RevokedToken{
+		UserID:          user.ID,
+		ExceptSessionID: actor.SessionID,
+		RevokedAt:       now,
+		ExpiresAt:       now.Add(s.token.AccessTokenTTL()),
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.refreshRepo.RevokeOtherFamilies(user.ID, actor.SessionID); err != nil {
+		return err
+	}
+
+	s.audit(actor.UserID, models.AuditChangePassword, user.ID)
+	s.notify(user, "Your password was changed", "The password for your account was changed. If this was not you, reset your password immediately.")
+	return nil
+}
+
+// RequestReset mengirim token reset lewat notifier. Email yang tidak terdaftar tidak
+// menghasilkan error, supaya endpoint ini tidak bisa dipakai mengecek email mana yang terdaftar.
+func (s *passwordService) RequestReset(forgot request.ForgotPasswordRequest) error {
+	if err := validators.NewCustomerValidator().Validate(forgot); err != nil {
+		return err
+	}
+	user, err := s.userRepo.FindByEmail(forgot.Email)
+	if err != nil {
+		return nil
+	}
+
+	// Hanya link reset terbaru yang berlaku
+	resetToken, expiresAt, err := issueOneTimeToken(s.oneTimeRepo, user.ID, models.PasswordResetToken, s.resetTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Use this token to reset your password: %s\nThe token expires at %s. If you did not request a reset, ignore this message.", resetToken, expiresAt.Format(time.RFC3339))
+	return s.notifier.Send(notifications.Notification{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body:    body,
+	})
+}
+
+// ResetPassword memakai token reset sekali pakai dan mencabut semua sesi user
+func (s *passwordService) ResetPassword(reset request.ResetPasswordRequest) error {
+	if err := validators.NewCustomerValidator().Validate(reset); err != nil {
+		return err
+	}
+	consumed, err := s.oneTimeRepo.Consume(models.PasswordResetToken, security.HashOneTimeToken(reset.Token))
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	user, err := s.userRepo.FindByID(consumed.UserID)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.setPassword(user, reset.NewPassword); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = s.revokedRepo.Revoke(models.RevokedToken{
+		UserID:    user.ID,
+		RevokedAt: now,
+		ExpiresAt: now.Add(s.token.AccessTokenTTL()),
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.refreshRepo.RevokeByUserID(user.ID); err != nil {
+		return err
+	}
+
+	s.audit(user.ID, models.AuditResetPassword, user.ID)
+	s.notify(user, "Your password was reset", "The password for your account was reset and all sessions were signed out.")
+	return nil
+}
+
+func (s *passwordService) setPassword(user *models.User, password string) error {
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	return s.userRepo.UpdatePassword(user.ID, hashedPassword)
+}
+
+func (s *passwordService) audit(actorID, action, userID string) {
+	_, err := s.auditRepo.Record(models.AuditEvent{
+		ActorID:   actorID,
+		Action:    action,
+		Resource:  "user:" + userID,
+		Outcome:   models.AuditAllowed,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to record audit event: %v", err)
+	}
+}
+
+// notify bersifat best effort; password sudah tersimpan meskipun notifikasi gagal terkirim
+func (s *passwordService) notify(user *models.User, subject, body string) {
+	err := s.notifier.Send(notifications.Notification{To: user.Email, Subject: subject, Body: body})
+	if err != nil {
+		log.Printf("Failed to send notification: %v", err)
+	}
+}
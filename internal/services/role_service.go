@@ -0,0 +1,276 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go-json/internal/dtos/mapper"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	"go-json/internal/security"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrRoleNotFound        = errors.New("role not found")
+	ErrRoleExists          = errors.New("role already exists")
+	ErrUnknownPermission   = errors.New("unknown permission")
+	ErrRoleAlreadyAssigned = errors.New("user already has this role")
+	ErrRoleNotAssigned     = errors.New("user does not have this role")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrSelfLockout         = errors.New("cannot remove your own permission to manage roles")
+)
+
+type RoleService interface {
+	FindAllRoles() ([]response.RoleResponse, error)
+	CreateRole(actor security.Principal, role request.CreateRoleRequest) (*response.RoleResponse, error)
+	GrantPermissions(actor security.Principal, roleID string, grant request.RolePermissionsRequest) (*response.RoleResponse, error)
+	RevokePermission(actor security.Principal, roleID, permission string) (*response.RoleResponse, error)
+	SetTwoFactorRequired(actor security.Principal, roleID string, policy request.RoleTwoFactorRequest) (*response.RoleResponse, error)
+	AssignRole(actor security.Principal, userID string, assign request.AssignRoleRequest) (*response.UserRolesResponse, error)
+	UnassignRole(actor security.Principal, userID, roleID string) (*response.UserRolesResponse, error)
+}
+
+type roleService struct {
+	roleRepo  repositories.RoleRepository
+	userRepo  repositories.UserRepository
+	auditRepo repositories.AuditRepository
+}
+
+func NewRoleService(roleRepo repositories.RoleRepository, userRepo repositories.UserRepository, auditRepo repositories.AuditRepository) RoleService {
+	return &roleService{roleRepo: roleRepo, userRepo: userRepo, auditRepo: auditRepo}
+}
+
+func (s *roleService) FindAllRoles() ([]response.RoleResponse, error) {
+	roles, err := s.roleRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	roleResponses := []response.RoleResponse{}
+	for _, role := range roles {
+		roleResponses = append(roleResponses, mapper.RoleModelToResponse(role))
+	}
+	return roleResponses, nil
+}
+
+func (s *roleService) CreateRole(actor security.Principal, role request.CreateRoleRequest) (*response.RoleResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(role); err != nil {
+		return nil, err
+	}
+	if err := checkPermissions(role.Permissions); err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(role.Name)
+	if _, err := s.roleRepo.FindByRoleName(name); err == nil {
+		return nil, ErrRoleExists
+	}
+
+	created, err := s.roleRepo.CreateRole(models.Role{
+		Name:             name,
+		Permissions:      uniquePermissions(nil, role.Permissions),
+		RequireTwoFactor: role.RequireTwoFactor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit(actor, models.AuditCreateRole, "role:"+created.ID, "")
+
+	roleResponse := mapper.RoleModelToResponse(*created)
+	return &roleResponse, nil
+}
+
+func (s *roleService) GrantPermissions(actor security.Principal, roleID string, grant request.RolePermissionsRequest) (*response.RoleResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(grant); err != nil {
+		return nil, err
+	}
+	if err := checkPermissions(grant.Permissions); err != nil {
+		return nil, err
+	}
+
+	role, err := s.roleRepo.FindByRoleID(roleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+	role.Permissions = uniquePermissions(role.Permissions, grant.Permissions)
+	if err := s.roleRepo.UpdateRole(*role); err != nil {
+		return nil, err
+	}
+	s.audit(actor, models.AuditGrantPermission, "role:"+role.ID, strings.Join(grant.Permissions, ","))
+
+	roleResponse := mapper.RoleModelToResponse(*role)
+	return &roleResponse, nil
+}
+
+func (s *roleService) RevokePermission(actor security.Principal, roleID, permission string) (*response.RoleResponse, error) {
+	if err := checkPermissions([]string{permission}); err != nil {
+		return nil, err
+	}
+
+	role, err := s.roleRepo.FindByRoleID(roleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+	// Admin tidak boleh mencabut role:manage dari role yang sedang ia pakai
+	if permission == models.PermissionRoleManage && slices.Contains(actor.Roles, role.Name) {
+		return nil, ErrSelfLockout
+	}
+
+	role.Permissions = slices.DeleteFunc(role.Permissions, func(p string) bool { return p == permission })
+	if err := s.roleRepo.UpdateRole(*role); err != nil {
+		return nil, err
+	}
+	s.audit(actor, models.AuditRevokePermission, "role:"+role.ID, permission)
+
+	roleResponse := mapper.RoleModelToResponse(*role)
+	return &roleResponse, nil
+}
+
+// SetTwoFactorRequired mengubah kebijakan 2FA role. Pemegang role yang belum punya 2FA
+// diminta mendaftar pada login berikutnya; sesi yang sedang berjalan tidak terpengaruh.
+func (s *roleService) SetTwoFactorRequired(actor security.Principal, roleID string, policy request.RoleTwoFactorRequest) (*response.RoleResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(policy); err != nil {
+		return nil, err
+	}
+	role, err := s.roleRepo.FindByRoleID(roleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+	role.RequireTwoFactor = *policy.Required
+	if err := s.roleRepo.UpdateRole(*role); err != nil {
+		return nil, err
+	}
+	s.audit(actor, models.AuditSetRoleTwoFactor, "role:"+role.ID, strconv.FormatBool(role.RequireTwoFactor))
+
+	roleResponse := mapper.RoleModelToResponse(*role)
+	return &roleResponse, nil
+}
+
+// AssignRole memberi role ke user. Token yang sudah terbit tetap membawa role lama
+// sampai user login ulang atau me-refresh token.
+func (s *roleService) AssignRole(actor security.Principal, userID string, assign request.AssignRoleRequest) (*response.UserRolesResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(assign); err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	role, err := s.roleRepo.FindByRoleName(strings.ToLower(assign.Role))
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	roleIDs, err := s.userRoleIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(roleIDs, role.ID) {
+		return nil, ErrRoleAlreadyAssigned
+	}
+	if err := s.roleRepo.AssignRoles(userID, []string{role.ID}); err != nil {
+		return nil, err
+	}
+	s.audit(actor, models.AuditAssignRole, "user:"+userID, role.Name)
+
+	return s.userRoles(userID)
+}
+
+func (s *roleService) UnassignRole(actor security.Principal, userID, roleID string) (*response.UserRolesResponse, error) {
+	role, err := s.roleRepo.FindByRoleID(roleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+	if actor.UserID == userID && role.HasPermission(models.PermissionRoleManage) {
+		return nil, ErrSelfLockout
+	}
+
+	roleIDs, err := s.userRoleIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(roleIDs, roleID) {
+		return nil, ErrRoleNotAssigned
+	}
+	if err := s.roleRepo.RemoveRole(userID, roleID); err != nil {
+		return nil, err
+	}
+	s.audit(actor, models.AuditUnassignRole, "user:"+userID, role.Name)
+
+	return s.userRoles(userID)
+}
+
+func (s *roleService) userRoleIDs(userID string) ([]string, error) {
+	userRoles, err := s.roleRepo.FindRoleByUserID(userID)
+	if err != nil {
+		// FindRoleByUserID mengembalikan error bila user belum punya role sama sekali
+		return []string{}, nil
+	}
+	roleIDs := []string{}
+	for _, userRole := range *userRoles {
+		roleIDs = append(roleIDs, userRole.RoleID)
+	}
+	return roleIDs, nil
+}
+
+func (s *roleService) userRoles(userID string) (*response.UserRolesResponse, error) {
+	roleIDs, err := s.userRoleIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	roleNames := []string{}
+	for _, roleID := range roleIDs {
+		role, err := s.roleRepo.FindByRoleID(roleID)
+		if err != nil {
+			return nil, err
+		}
+		roleNames = append(roleNames, role.Name)
+	}
+	return &response.UserRolesResponse{UserID: userID, Roles: roleNames}, nil
+}
+
+func (s *roleService) audit(actor security.Principal, action, resource, reason string) {
+	_, err := s.auditRepo.Record(models.AuditEvent{
+		ActorID:   actor.UserID,
+		Action:    action,
+		Resource:  resource,
+		Outcome:   models.AuditAllowed,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to record audit event: %v", err)
+	}
+}
+
+func checkPermissions(permissions []string) error {
+	for _, permission := range permissions {
+		if !models.IsKnownPermission(permission) {
+			return fmt.Errorf("%w: %s", ErrUnknownPermission, permission)
+		}
+	}
+	return nil
+}
+
+func uniquePermissions(existing, added []string) []string {
+	permissions := slices.Clone(existing)
+	if permissions == nil {
+		permissions = []string{}
+	}
+	for _, permission := range added {
+		if !slices.Contains(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
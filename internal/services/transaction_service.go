@@ -2,32 +2,100 @@ package services
 
 import (
 	"errors"
+	"go-json/internal/bankrails"
 	"go-json/internal/dtos/mapper"
 	"go-json/internal/dtos/request"
 	"go-json/internal/dtos/response"
 	"go-json/internal/models"
 	"go-json/internal/repositories"
+	"go-json/internal/security"
+	"log"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
+var (
+	ErrTransactionNotFound  = errors.New("transaction not found")
+	ErrRefundNotAllowed     = errors.New("only the merchant who received the payment can refund it")
+	ErrRefundExceedsPayment = errors.New("refund amount exceeds the refundable amount")
+	ErrCustomerMismatch     = errors.New("customer_id does not match the authenticated user")
+	ErrCustomerRequired     = errors.New("customer_id is required")
+	ErrHistoryAccessDenied  = errors.New("not allowed to read this transaction history")
+	ErrPaymentAmount        = errors.New("payment amount must be positive")
+	ErrInvalidCustomer      = errors.New("invalid customer ID")
+	ErrSameParty            = errors.New("customer and merchant must differ")
+)
+
 type TransactionService interface {
-	ProcessPayment(payment request.PaymentRequest) (*response.PaymentResponse, error)
-	TransactionHistoryByUserID(userID string) ([]response.UserTransactionHistoryResponse, error)
+	ProcessPayment(customerID string, payment request.PaymentRequest) (*response.PaymentResponse, error)
+	ProcessPaymentOnBehalf(actorID string, payment request.PaymentRequest) (*response.PaymentResponse, error)
+	RefundPayment(transactionID, merchantID string, refund request.RefundRequest) (*response.RefundResponse, error)
+	AuthorizePayment(customerID string, authorization request.AuthorizationRequest) (*response.AuthorizationResponse, error)
+	CaptureAuthorization(authorizationID, merchantID string, capture request.CaptureRequest) (*response.AuthorizationResponse, error)
+	VoidAuthorization(authorizationID, merchantID string, void request.VoidRequest) (*response.AuthorizationResponse, error)
+	ExpireAuthorizations() error
+	Deposit(userID string, deposit request.BankTransferRequest) (*response.BankTransferResponse, error)
+	Withdraw(userID string, withdrawal request.BankTransferRequest) (*response.BankTransferResponse, error)
+	ResumeBankTransfers() error
+	FindRecipient(senderID, query string) (*response.RecipientResponse, error)
+	Transfer(senderID string, transfer request.TransferRequest) (*response.TransferResponse, error)
+	TransferLimits(userID string) (*response.TransferLimitsResponse, error)
+	BackfillStatuses() error
+	TransactionHistoryByUserID(viewer security.Principal, userID string) ([]response.UserTransactionHistoryResponse, error)
 }
 
 type transactionService struct {
-	userRepo        repositories.UserRepository
-	transactionRepo repositories.TransactionRepository
-	roleRepo        repositories.RoleRepository
+	userRepo         repositories.UserRepository
+	transactionRepo  repositories.TransactionRepository
+	roleRepo         repositories.RoleRepository
+	merchantRepo     repositories.MerchantRepository
+	ledger           LedgerService
+	fx               FXRateProvider
+	auditRepo        repositories.AuditRepository
+	authorizationTTL time.Duration
+	bankRail         bankrails.BankRail
+	transferLimits   models.TransferLimits
+	refundMu         sync.Mutex
+	authorizationMu  sync.Mutex
+	bankMu           sync.Mutex
+	transferMu       sync.Mutex
+}
+
+func NewTransactionService(userRepo repositories.UserRepository, transactionRepo repositories.TransactionRepository, roleRepo repositories.RoleRepository, merchantRepo repositories.MerchantRepository, ledger LedgerService, fx FXRateProvider, auditRepo repositories.AuditRepository, authorizationTTL time.Duration, bankRail bankrails.BankRail, transferLimits models.TransferLimits) TransactionService {
+	return &transactionService{userRepo: userRepo, transactionRepo: transactionRepo, roleRepo: roleRepo, merchantRepo: merchantRepo, ledger: ledger, fx: fx, auditRepo: auditRepo, authorizationTTL: authorizationTTL, bankRail: bankRail, transferLimits: transferLimits}
+}
+
+// ProcessPayment selalu mendebit customerID, yaitu user yang terautentikasi.
+// customer_id di body boleh kosong, tapi bila diisi harus sama dengan customerID.
+func (p *transactionService) ProcessPayment(customerID string, payment request.PaymentRequest) (*response.PaymentResponse, error) {
+	if payment.CustomerID != "" && payment.CustomerID != customerID {
+		p.recordFailure(models.Transaction{
+			CustomerID:   customerID,
+			MerchantID:   payment.MerchantID,
+			Amount:       payment.Amount,
+			ActivityType: models.FailedPayment,
+			Timestamp:    time.Now(),
+			Details:      "Customer ID does not match the authenticated user",
+		}, models.FailureCustomerMismatch)
+		return nil, ErrCustomerMismatch
+	}
+	payment.CustomerID = customerID
+	return p.processPayment(payment, "")
 }
 
-func NewTransactionService(userRepo repositories.UserRepository, transactionRepo repositories.TransactionRepository, roleRepo repositories.RoleRepository) TransactionService {
-	return &transactionService{userRepo: userRepo, transactionRepo: transactionRepo, roleRepo: roleRepo}
+// ProcessPaymentOnBehalf dipakai role istimewa untuk membayar atas nama customer lain.
+// actorID dicatat di transaksi sebagai InitiatedBy.
+func (p *transactionService) ProcessPaymentOnBehalf(actorID string, payment request.PaymentRequest) (*response.PaymentResponse, error) {
+	if payment.CustomerID == "" {
+		return nil, ErrCustomerRequired
+	}
+	return p.processPayment(payment, actorID)
 }
 
-func (p *transactionService) ProcessPayment(payment request.PaymentRequest) (*response.PaymentResponse, error) {
+func (p *transactionService) processPayment(payment request.PaymentRequest, initiatedBy string) (*response.PaymentResponse, error) {
 	validate := validator.New()
 	err := validate.Struct(payment)
 	if err != nil {
@@ -35,109 +103,442 @@ func (p *transactionService) ProcessPayment(payment request.PaymentRequest) (*re
 	}
 	var transaction models.Transaction
 	transaction.CustomerID = payment.CustomerID
+	transaction.InitiatedBy = initiatedBy
 	transaction.MerchantID = payment.MerchantID
 	transaction.Amount = payment.Amount
 	transaction.Timestamp = time.Now()
 	transaction.Details = "Payment processing"
+	transaction.Begin(transaction.Timestamp)
 
-	if payment.Amount <= 0 {
+	if !payment.Amount.IsPositive() {
 		transaction.ActivityType = models.FailedPayment
 		transaction.Details = "Payment amount must be positive"
-		p.transactionRepo.CreateTransaction(transaction)
-		return nil, errors.New("payment amount must be positive")
+		p.recordFailure(transaction, models.FailureInvalidAmount)
+		return nil, ErrPaymentAmount
 	}
 
 	user, err := p.userRepo.FindByID(payment.CustomerID)
 	if err != nil {
 		transaction.ActivityType = models.FailedPayment
 		transaction.Details = "Invalid customer ID"
-		p.transactionRepo.CreateTransaction(transaction)
-		return nil, errors.New("invalid customer ID")
+		p.recordFailure(transaction, models.FailureInvalidCustomer)
+		return nil, ErrInvalidCustomer
 	}
 
-	if !user.IsActive {
+	if reason, details, err := customerPaymentError(user); err != nil {
 		transaction.ActivityType = models.FailedPayment
-		transaction.Details = "Customer is not active"
-		p.transactionRepo.CreateTransaction(transaction)
-		return nil, errors.New("customer is not active")
+		transaction.Details = details
+		p.recordFailure(transaction, reason)
+		return nil, err
 	}
 
-	if user.Balance < payment.Amount {
+	// Merchant selalu menerima payment.Amount; customer membayar dari wallet SourceCurrency
+	sourceCurrency := payment.Amount.Currency()
+	if payment.SourceCurrency != "" {
+		sourceCurrency = models.Currency(strings.ToUpper(string(payment.SourceCurrency)))
+	}
+	if _, err := models.CurrencyExponent(sourceCurrency); err != nil {
 		transaction.ActivityType = models.FailedPayment
-		transaction.Details = "Insufficient balance"
-		p.transactionRepo.CreateTransaction(transaction)
-		return nil, errors.New("insufficient balance")
+		transaction.Details = "Unsupported payment currency"
+		p.recordFailure(transaction, models.FailureUnsupportedCurrency)
+		return nil, err
 	}
 
-	user.Balance -= payment.Amount
+	debit := payment.Amount
+	if sourceCurrency != payment.Amount.Currency() {
+		quote, err := p.fx.Quote(payment.Amount.Currency(), sourceCurrency, transaction.Timestamp)
+		if err == nil {
+			debit, err = quote.Convert(payment.Amount)
+		}
+		if err != nil || !debit.IsPositive() {
+			transaction.ActivityType = models.FailedPayment
+			transaction.Details = "Exchange rate not available"
+			p.recordFailure(transaction, models.FailureExchangeRateUnavailable)
+			return nil, ErrExchangeRateNotFound
+		}
+		transaction.SourceAmount = &debit
+		transaction.ExchangeRate = quote
+	}
 
-	userRoles, err := p.roleRepo.FindRoleByUserID(user.ID)
+	cmp, err := user.BalanceIn(sourceCurrency).Cmp(debit)
 	if err != nil {
+		return nil, err
+	}
+	if cmp < 0 {
+		transaction.ActivityType = models.FailedPayment
+		transaction.Details = "Insufficient balance"
+		p.recordFailure(transaction, models.FailureInsufficientBalance)
+		return nil, ErrInsufficientBalance
+	}
+
+	if reason, details, err := p.merchantPaymentError(payment.MerchantID); err != nil {
 		transaction.ActivityType = models.FailedPayment
-		transaction.Details = "Error finding user roles"
-		p.transactionRepo.CreateTransaction(transaction)
+		transaction.Details = details
+		p.recordFailure(transaction, reason)
 		return nil, err
 	}
 
-	for _, role := range *userRoles {
-		if role.RoleID == "1" {
-			user.Balance += payment.Amount
-		}
+	if payment.MerchantID == user.ID {
+		transaction.ActivityType = models.FailedPayment
+		transaction.Details = "Customer and merchant must differ"
+		p.recordFailure(transaction, models.FailureSameParty)
+		return nil, ErrSameParty
 	}
 
-	if err := p.userRepo.UpdateUser(*user); err != nil {
+	var entry *models.JournalEntry
+	if transaction.SourceAmount != nil {
+		entry, err = p.ledger.TransferWithConversion(user.ID, payment.MerchantID, debit, payment.Amount, "Payment to merchant "+payment.MerchantID)
+	} else {
+		entry, err = p.ledger.Transfer(user.ID, payment.MerchantID, payment.Amount, "Payment to merchant "+payment.MerchantID)
+	}
+	if errors.Is(err, ErrInsufficientBalance) {
+		transaction.ActivityType = models.FailedPayment
+		transaction.Details = "Insufficient balance"
+		p.recordFailure(transaction, models.FailureInsufficientBalance)
+		return nil, err
+	}
+	if err != nil {
 		transaction.ActivityType = models.FailedPayment
-		transaction.Details = "Failed to update customer"
-		p.transactionRepo.CreateTransaction(transaction)
+		transaction.Details = "Failed to post ledger entry"
+		p.recordFailure(transaction, models.FailureLedgerError)
 		return nil, err
 	}
+
 	transaction.ActivityType = models.PaymentActivity
 	transaction.Details = "Payment processed successfully"
+	transaction.JournalEntryID = entry.ID
+	if err := transaction.TransitionTo(models.TransactionSucceeded, time.Now()); err != nil {
+		return nil, err
+	}
 
 	trx, err := p.transactionRepo.CreateTransaction(transaction)
 	if err != nil {
 		return nil, err
 	}
 	paymentResponse := mapper.TransactionModelToPaymentResponse(trx)
+	paymentResponse.Amount = payment.Amount
 
-	if userRoles != nil {
-		for _, role := range *userRoles {
-			if role.RoleID == "1" {
-				paymentResponse.MerchantID = payment.MerchantID
-			}
-			if role.RoleID == "2" {
-				paymentResponse.CustomerID = payment.CustomerID
-			}
+	return &paymentResponse, nil
+}
+
+// customerPaymentError memeriksa apakah customer boleh membayar. reason dan details dicatat di transaksi yang gagal.
+func customerPaymentError(user *models.User) (models.FailureReason, string, error) {
+	if err := accountStatusError(user.AccountStatus()); err != nil {
+		return customerStatusReason(err), "Customer account is " + strings.ToLower(string(user.AccountStatus())), err
+	}
+	if user.AccountStatus() == models.AccountPending {
+		return models.FailureCustomerNotVerified, "Customer email is not verified", ErrAccountNotVerified
+	}
+	return "", "", nil
+}
+
+// customerStatusReason memetakan error dari accountStatusError ke kode alasan transaksi
+func customerStatusReason(err error) models.FailureReason {
+	if errors.Is(err, ErrAccountClosed) {
+		return models.FailureCustomerClosed
+	}
+	return models.FailureCustomerSuspended
+}
+
+// merchantPaymentError memeriksa apakah merchantID boleh menerima pembayaran: harus merchant
+// terdaftar yang sudah disetujui dan akun user-nya aktif
+func (p *transactionService) merchantPaymentError(merchantID string) (models.FailureReason, string, error) {
+	merchantProfile, err := p.merchantRepo.FindByID(merchantID)
+	if err != nil {
+		return models.FailureInvalidMerchant, "Invalid merchant ID", ErrInvalidMerchant
+	}
+	if err := merchantStatusError(merchantProfile.Status); err != nil {
+		reason := models.FailureMerchantNotActive
+		if errors.Is(err, ErrMerchantSuspended) {
+			reason = models.FailureMerchantSuspended
 		}
+		return reason, "Merchant is " + strings.ToLower(string(merchantProfile.Status)), err
 	}
-	paymentResponse.Amount = payment.Amount
 
-	return &paymentResponse, nil
+	merchant, err := p.userRepo.FindByID(merchantID)
+	if err != nil {
+		return models.FailureInvalidMerchant, "Invalid merchant ID", ErrInvalidMerchant
+	}
+	if err := accountStatusError(merchant.AccountStatus()); err != nil {
+		reason := models.FailureMerchantAccountSuspended
+		if errors.Is(err, ErrAccountClosed) {
+			reason = models.FailureMerchantAccountClosed
+		}
+		return reason, "Merchant account is " + strings.ToLower(string(merchant.AccountStatus())), err
+	}
+	return "", "", nil
 }
 
-func (p *transactionService) TransactionHistoryByUserID(userID string) ([]response.UserTransactionHistoryResponse, error) {
-	transactions, err := p.transactionRepo.FindAllTransaction()
+// recordFailure menyimpan transaksi yang gagal dengan status FAILED dan kode alasannya
+func (p *transactionService) recordFailure(transaction models.Transaction, reason models.FailureReason) {
+	if transaction.Status == "" {
+		transaction.Begin(transaction.Timestamp)
+	}
+	if err := transaction.Fail(reason, time.Now()); err != nil {
+		log.Printf("Failed to mark transaction as failed: %v", err)
+	}
+	p.transactionRepo.CreateTransaction(transaction)
+}
+
+// RefundPayment mengembalikan dana dari wallet merchant ke wallet customer.
+// Satu payment boleh direfund berkali-kali selama totalnya tidak melebihi jumlah payment.
+func (p *transactionService) RefundPayment(transactionID, merchantID string, refund request.RefundRequest) (*response.RefundResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(refund); err != nil {
+		return nil, err
+	}
+
+	// Cek sisa refund dan posting ke ledger harus atomik agar dua refund paralel tidak melewati batas
+	p.refundMu.Lock()
+	defer p.refundMu.Unlock()
+
+	transaction := models.Transaction{
+		ActivityType:          models.FailedRefund,
+		Timestamp:             time.Now(),
+		Amount:                refund.Amount,
+		OriginalTransactionID: transactionID,
+	}
+	transaction.Begin(transaction.Timestamp)
+
+	// Capture dari otorisasi direfund seperti payment biasa
+	original, err := p.transactionRepo.FindByID(transactionID)
+	if err != nil || (original.ActivityType != models.PaymentActivity && original.ActivityType != models.CaptureActivity) {
+		return nil, ErrTransactionNotFound
+	}
+	transaction.CustomerID = original.CustomerID
+	transaction.MerchantID = original.MerchantID
+
+	if !p.actsForMerchant(merchantID, original.MerchantID) {
+		return nil, ErrRefundNotAllowed
+	}
+
+	refunded, sourceRefunded, err := p.refundedAmount(original)
+	if err != nil {
+		return nil, err
+	}
+	refundable, err := original.Amount.Sub(refunded)
 	if err != nil {
 		return nil, err
 	}
 
+	if refund.Amount.IsZero() {
+		transaction.Amount = refundable
+	}
+	cmp, err := transaction.Amount.Cmp(refundable)
+	if err != nil {
+		transaction.Details = "Refund currency must match the payment currency"
+		p.recordFailure(transaction, models.FailureCurrencyMismatch)
+		return nil, err
+	}
+	if !transaction.Amount.IsPositive() || cmp > 0 {
+		transaction.Details = "Refund amount exceeds the refundable amount"
+		p.recordFailure(transaction, models.FailureExceedsRefundable)
+		return nil, ErrRefundExceedsPayment
+	}
+
+	description := "Refund of transaction " + original.ID
+	if refund.Reason != "" {
+		description += ": " + refund.Reason
+	}
+
+	var entry *models.JournalEntry
+	if original.SourceAmount != nil && original.ExchangeRate != nil {
+		credit, convertErr := p.refundSourceAmount(original, transaction.Amount, refundable, sourceRefunded)
+		if convertErr != nil {
+			transaction.Details = "Refund amount is too small to convert"
+			p.recordFailure(transaction, models.FailureAmountTooSmall)
+			return nil, convertErr
+		}
+		transaction.SourceAmount = &credit
+		transaction.ExchangeRate = original.ExchangeRate
+		entry, err = p.ledger.TransferWithConversion(original.MerchantID, original.CustomerID, transaction.Amount, credit, description)
+	} else {
+		entry, err = p.ledger.Transfer(original.MerchantID, original.CustomerID, transaction.Amount, description)
+	}
+	if errors.Is(err, ErrInsufficientBalance) {
+		transaction.Details = "Insufficient merchant balance"
+		p.recordFailure(transaction, models.FailureInsufficientMerchantFunds)
+		return nil, err
+	}
+	if err != nil {
+		transaction.Details = "Failed to post ledger entry"
+		p.recordFailure(transaction, models.FailureLedgerError)
+		return nil, err
+	}
+
+	transaction.ActivityType = models.RefundActivity
+	transaction.Details = description
+	transaction.JournalEntryID = entry.ID
+	if err := transaction.TransitionTo(models.TransactionSucceeded, time.Now()); err != nil {
+		return nil, err
+	}
+
+	trx, err := p.transactionRepo.CreateTransaction(transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	if refunded, err = refunded.Add(trx.Amount); err != nil {
+		return nil, err
+	}
+	if refundable, err = original.Amount.Sub(refunded); err != nil {
+		return nil, err
+	}
+	p.markRefunded(*original, refundable.IsZero())
+	refundResponse := mapper.TransactionModelToRefundResponse(trx, refunded, refundable)
+	return &refundResponse, nil
+}
+
+// markRefunded memindahkan payment asli ke PARTIALLY_REFUNDED atau REFUNDED. Refund sudah
+// terposting di ledger, jadi kegagalan di sini hanya dicatat di log.
+func (p *transactionService) markRefunded(original models.Transaction, full bool) {
+	status := models.TransactionPartiallyRefunded
+	if full {
+		status = models.TransactionRefunded
+	}
+	if err := original.TransitionTo(status, time.Now()); err != nil {
+		log.Printf("Failed to mark transaction %s as %s: %v", original.ID, status, err)
+		return
+	}
+	if err := p.transactionRepo.UpdateTransaction(original); err != nil {
+		log.Printf("Failed to update transaction %s: %v", original.ID, err)
+	}
+}
+
+// refundedAmount menjumlahkan refund sebelumnya dalam mata uang merchant dan,
+// untuk payment lintas mata uang, dalam mata uang yang dibayar customer
+func (p *transactionService) refundedAmount(original *models.Transaction) (models.Money, models.Money, error) {
+	transactions, err := p.transactionRepo.FindAllTransaction()
+	if err != nil {
+		return models.Money{}, models.Money{}, err
+	}
+
+	refunded := models.NewMoney(0, original.Amount.Currency())
+	sourceRefunded := refunded
+	if original.SourceAmount != nil {
+		sourceRefunded = models.NewMoney(0, original.SourceAmount.Currency())
+	}
+	for _, trx := range transactions {
+		if trx.ActivityType != models.RefundActivity || trx.OriginalTransactionID != original.ID {
+			continue
+		}
+		if refunded, err = refunded.Add(trx.Amount); err != nil {
+			return models.Money{}, models.Money{}, err
+		}
+		if trx.SourceAmount != nil {
+			if sourceRefunded, err = sourceRefunded.Add(*trx.SourceAmount); err != nil {
+				return models.Money{}, models.Money{}, err
+			}
+		}
+	}
+	return refunded, sourceRefunded, nil
+}
+
+// refundSourceAmount menghitung jumlah yang dikembalikan ke wallet customer dengan kurs payment asli.
+// Refund terakhir selalu mengembalikan sisa persisnya agar total refund sama dengan yang dibayar customer.
+func (p *transactionService) refundSourceAmount(original *models.Transaction, amount, refundable, sourceRefunded models.Money) (models.Money, error) {
+	sourceRemaining, err := original.SourceAmount.Sub(sourceRefunded)
+	if err != nil {
+		return models.Money{}, err
+	}
+	if amount == refundable {
+		return sourceRemaining, nil
+	}
+
+	credit, err := original.ExchangeRate.Convert(amount)
+	if err != nil {
+		return models.Money{}, err
+	}
+	if cmp, err := credit.Cmp(sourceRemaining); err != nil {
+		return models.Money{}, err
+	} else if cmp > 0 {
+		credit = sourceRemaining
+	}
+	if !credit.IsPositive() {
+		return models.Money{}, ErrRefundExceedsPayment
+	}
+	return credit, nil
+}
+
+// TransactionHistoryByUserID mengembalikan riwayat transaksi userID sesuai permission viewer:
+// history:read:any melihat semuanya, history:read:own hanya riwayatnya sendiri, dan
+// history:read:merchant hanya transaksi userID yang dibayarkan kepadanya.
+// Akses yang ditolak dicatat di audit log.
+func (p *transactionService) TransactionHistoryByUserID(viewer security.Principal, userID string) ([]response.UserTransactionHistoryResponse, error) {
+	isAdmin := viewer.HasPermission(models.PermissionHistoryReadAny)
+	isOwner := viewer.UserID != "" && viewer.UserID == userID && viewer.HasPermission(models.PermissionHistoryReadOwn)
+	if !isAdmin && !isOwner && !viewer.HasPermission(models.PermissionHistoryReadMerchant) {
+		p.auditDenied(viewer, userID, "no permission to read this history")
+		return nil, ErrHistoryAccessDenied
+	}
+
 	user, err := p.userRepo.FindByID(userID)
 	if err != nil {
 		return nil, err
 	}
 
+	transactions, err := p.transactionRepo.FindAllTransaction()
+	if err != nil {
+		return nil, err
+	}
+
+	// Pemilik merchant melihat transaksi semua merchant yang dimilikinya
+	ownedMerchants := map[string]bool{viewer.UserID: viewer.UserID != ""}
+	if merchants, err := p.merchantRepo.FindByOwnerID(viewer.UserID); err == nil {
+		for _, merchant := range merchants {
+			ownedMerchants[merchant.ID] = true
+		}
+	}
+
 	var userTransactions []*models.Transaction
 	for _, trx := range transactions {
-		if trx.CustomerID == userID {
+		// Penerima hanya melihat transfer yang berhasil; percobaan yang gagal milik pengirim
+		received := trx.RecipientID == userID && trx.ActivityType == models.TransferActivity
+		if trx.CustomerID != userID && trx.MerchantID != userID && !received {
+			continue
+		}
+		if isAdmin || isOwner || ownedMerchants[trx.MerchantID] {
 			userTransactions = append(userTransactions, &trx)
 		}
 	}
 
+	// Merchant tanpa transaksi bersama user ini tidak boleh melihat profilnya sama sekali
+	if !isAdmin && !isOwner && len(userTransactions) == 0 {
+		p.auditDenied(viewer, userID, "merchant has no transactions with this user")
+		return nil, ErrHistoryAccessDenied
+	}
+
 	userTransactionHistory := response.UserTransactionHistoryResponse{
-		User:         *user,
+		User:         mapper.UserModelToHistoryUserResponse(*user, isAdmin || isOwner),
 		Transactions: userTransactions,
 		TotalCount:   len(userTransactions),
 	}
 
 	return []response.UserTransactionHistoryResponse{userTransactionHistory}, nil
 }
+
+func (p *transactionService) auditDenied(viewer security.Principal, userID, reason string) {
+	_, err := p.auditRepo.Record(models.AuditEvent{
+		ActorID:   viewer.UserID,
+		Action:    models.AuditReadTransactionHistory,
+		Resource:  "user:" + userID,
+		Outcome:   models.AuditDenied,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to record audit event: %v", err)
+	}
+}
+
+// actsForMerchant bernilai true bila userID adalah merchant itu sendiri atau salah satu pemiliknya
+func (p *transactionService) actsForMerchant(userID, merchantID string) bool {
+	if userID == "" {
+		return false
+	}
+	if userID == merchantID {
+		return true
+	}
+	merchant, err := p.merchantRepo.FindByID(merchantID)
+	return err == nil && merchant.IsOwnedBy(userID)
+}
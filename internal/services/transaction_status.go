@@ -0,0 +1,153 @@
+package services
+
+import (
+	"go-json/internal/models"
+	"log"
+	"strings"
+	"time"
+)
+
+// legacyFailureReasons memetakan Details transaksi gagal yang dicatat sebelum ada FailureReason.
+// Dicocokkan sebagai prefix karena sebagian Details diikuti status akun atau IP; yang lebih spesifik ditaruh lebih dulu.
+var legacyFailureReasons = []struct {
+	prefix string
+	reason models.FailureReason
+}{
+	{"Customer ID does not match", models.FailureCustomerMismatch},
+	{"Payment amount must be positive", models.FailureInvalidAmount},
+	{"Authorization amount must be positive", models.FailureInvalidAmount},
+	{"Invalid customer ID", models.FailureInvalidCustomer},
+	{"Customer account is closed", models.FailureCustomerClosed},
+	{"Customer account is ", models.FailureCustomerSuspended},
+	{"Customer is not active", models.FailureCustomerSuspended},
+	{"Customer email is not verified", models.FailureCustomerNotVerified},
+	{"Invalid merchant ID", models.FailureInvalidMerchant},
+	{"Merchant is suspended", models.FailureMerchantSuspended},
+	{"Merchant is ", models.FailureMerchantNotActive},
+	{"Merchant account is closed", models.FailureMerchantAccountClosed},
+	{"Merchant account is ", models.FailureMerchantAccountSuspended},
+	{"Customer and merchant must differ", models.FailureSameParty},
+	{"Unsupported payment currency", models.FailureUnsupportedCurrency},
+	{"Exchange rate not available", models.FailureExchangeRateUnavailable},
+	{"Insufficient balance", models.FailureInsufficientBalance},
+	{"Insufficient merchant balance", models.FailureInsufficientMerchantFunds},
+	{"Refund currency must match", models.FailureCurrencyMismatch},
+	{"Capture currency must match", models.FailureCurrencyMismatch},
+	{"Refund amount exceeds", models.FailureExceedsRefundable},
+	{"Refund amount is too small", models.FailureAmountTooSmall},
+	{"Capture amount exceeds", models.FailureExceedsHeld},
+	{"Authorization has expired", models.FailureAuthorizationExpired},
+	{"Authorization is ", models.FailureAuthorizationClosed},
+	{"Failed to post ledger entry", models.FailureLedgerError},
+	{"Invalid credentials", models.FailureInvalidCredentials},
+	{"Invalid two-factor code", models.FailureInvalidTwoFactorCode},
+	{"Login throttled", models.FailureLoginThrottled},
+	{"Login to CLOSED account", models.FailureCustomerClosed},
+	{"Login to ", models.FailureCustomerSuspended},
+}
+
+func legacyFailureReason(details string) models.FailureReason {
+	for _, legacy := range legacyFailureReasons {
+		if strings.HasPrefix(details, legacy.prefix) {
+			return legacy.reason
+		}
+	}
+	return models.FailureUnknown
+}
+
+func isFailedActivity(activity models.ActivityType) bool {
+	return strings.HasPrefix(string(activity), "FAILED_")
+}
+
+// BackfillStatuses mengisi Status, FailureReason, dan StatusHistory transaksi lama yang dicatat
+// sebelum ada state machine. Dijalankan saat startup; transaksi yang sudah punya status dilewati.
+func (p *transactionService) BackfillStatuses() error {
+	transactions, err := p.transactionRepo.FindAllTransaction()
+	if err != nil {
+		return err
+	}
+	linked := map[string][]models.Transaction{}
+	for _, trx := range transactions {
+		if trx.OriginalTransactionID != "" {
+			linked[trx.OriginalTransactionID] = append(linked[trx.OriginalTransactionID], trx)
+		}
+	}
+
+	backfilled := 0
+	for _, trx := range transactions {
+		if trx.Status != "" {
+			continue
+		}
+		if err := legacyStatus(&trx, linked[trx.ID]); err != nil {
+			return err
+		}
+		if err := p.transactionRepo.UpdateTransaction(trx); err != nil {
+			return err
+		}
+		backfilled++
+	}
+	if backfilled > 0 {
+		log.Printf("Backfilled status of %d transactions", backfilled)
+	}
+	return nil
+}
+
+// legacyStatus menurunkan status transaksi lama dari jenis aktivitasnya dan transaksi yang terhubung dengannya
+func legacyStatus(trx *models.Transaction, linked []models.Transaction) error {
+	trx.Begin(trx.Timestamp)
+	if isFailedActivity(trx.ActivityType) {
+		return trx.Fail(legacyFailureReason(trx.Details), trx.Timestamp)
+	}
+
+	switch trx.ActivityType {
+	case models.AuthorizationActivity:
+		captured, status, err := authorizationState(*trx, linked)
+		if err != nil {
+			return err
+		}
+		switch {
+		case status == models.AuthorizationOpen:
+			return nil
+		case captured.IsPositive():
+			return trx.TransitionTo(models.TransactionSucceeded, lastLinkedTimestamp(trx, linked))
+		default:
+			return trx.TransitionTo(models.TransactionReversed, lastLinkedTimestamp(trx, linked))
+		}
+	case models.PaymentActivity, models.CaptureActivity:
+		if err := trx.TransitionTo(models.TransactionSucceeded, trx.Timestamp); err != nil {
+			return err
+		}
+		var refunds []models.Transaction
+		refunded := models.NewMoney(0, trx.Amount.Currency())
+		for _, refund := range linked {
+			if refund.ActivityType != models.RefundActivity {
+				continue
+			}
+			var err error
+			if refunded, err = refunded.Add(refund.Amount); err != nil {
+				return err
+			}
+			refunds = append(refunds, refund)
+		}
+		if len(refunds) == 0 {
+			return nil
+		}
+		status := models.TransactionPartiallyRefunded
+		if refunded == trx.Amount {
+			status = models.TransactionRefunded
+		}
+		return trx.TransitionTo(status, lastLinkedTimestamp(trx, refunds))
+	default:
+		return trx.TransitionTo(models.TransactionSucceeded, trx.Timestamp)
+	}
+}
+
+func lastLinkedTimestamp(trx *models.Transaction, linked []models.Transaction) time.Time {
+	last := trx.Timestamp
+	for _, linkedTrx := range linked {
+		if linkedTrx.Timestamp.After(last) {
+			last = linkedTrx.Timestamp
+		}
+	}
+	return last
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"errors"
+	"go-json/internal/dtos/mapper"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrTransferAmount             = errors.New("amount must be positive")
+	ErrRecipientNotFound          = errors.New("recipient not found")
+	ErrRecipientNotActive         = errors.New("recipient cannot receive transfers")
+	ErrTransferToSelf             = errors.New("cannot transfer to yourself")
+	ErrTransferLimitExceeded      = errors.New("amount exceeds the per-transfer limit")
+	ErrDailyTransferLimitExceeded = errors.New("amount exceeds what is left of the daily transfer limit")
+)
+
+const customerRoleName = "customer"
+
+// FindRecipient mencari penerima transfer agar pengirim bisa memastikannya sebelum mengirim dana.
+// Aturannya sama dengan Transfer, sehingga penerima yang ditemukan di sini juga bisa menerima transfer.
+func (p *transactionService) FindRecipient(senderID, query string) (*response.RecipientResponse, error) {
+	recipient, err := p.resolveRecipient(query)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.ID == senderID {
+		return nil, ErrTransferToSelf
+	}
+	if err := accountStatusError(recipient.AccountStatus()); err != nil {
+		return nil, ErrRecipientNotActive
+	}
+	recipientResponse := mapper.UserModelToRecipientResponse(*recipient)
+	return &recipientResponse, nil
+}
+
+// resolveRecipient mencocokkan query sebagai email bila mengandung @, selain itu sebagai username lalu ID.
+// Hanya customer yang bisa menerima transfer; merchant dan admin diperlakukan seolah tidak ada
+// agar pencarian penerima tidak bisa dipakai untuk mendaftar akun mereka.
+func (p *transactionService) resolveRecipient(query string) (*models.User, error) {
+	recipient := p.findRecipientUser(strings.TrimSpace(query))
+	if recipient == nil || !p.hasCustomerRole(recipient.ID) {
+		return nil, ErrRecipientNotFound
+	}
+	return recipient, nil
+}
+
+func (p *transactionService) findRecipientUser(query string) *models.User {
+	if query == "" {
+		return nil
+	}
+	if strings.Contains(query, "@") {
+		if user, err := p.userRepo.FindByEmail(query); err == nil {
+			return user
+		}
+		return nil
+	}
+	if user, err := p.userRepo.FindByUsername(query); err == nil {
+		return user
+	}
+	if user, err := p.userRepo.FindByID(query); err == nil {
+		return user
+	}
+	return nil
+}
+
+func (p *transactionService) hasCustomerRole(userID string) bool {
+	userRoles, err := p.roleRepo.FindRoleByUserID(userID)
+	if err != nil {
+		return false
+	}
+	for _, userRole := range *userRoles {
+		if role, err := p.roleRepo.FindByRoleID(userRole.RoleID); err == nil && role.Name == customerRoleName {
+			return true
+		}
+	}
+	return false
+}
+
+// Transfer memindahkan dana dari wallet senderID ke wallet user lain dalam mata uang yang sama.
+// Transfer tercatat sekali dan muncul di riwayat pengirim maupun penerima.
+func (p *transactionService) Transfer(senderID string, transfer request.TransferRequest) (*response.TransferResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(transfer); err != nil {
+		return nil, err
+	}
+
+	// Cek batas harian dan posting ke ledger harus atomik agar transfer paralel tidak melewati batas
+	p.transferMu.Lock()
+	defer p.transferMu.Unlock()
+
+	transaction := models.Transaction{
+		CustomerID:   senderID,
+		ActivityType: models.FailedTransfer,
+		Timestamp:    time.Now(),
+		Amount:       transfer.Amount,
+		Note:         transfer.Note,
+	}
+	transaction.Begin(transaction.Timestamp)
+
+	if !transfer.Amount.IsPositive() {
+		transaction.Details = "Transfer amount must be positive"
+		p.recordFailure(transaction, models.FailureInvalidAmount)
+		return nil, ErrTransferAmount
+	}
+	if _, err := models.CurrencyExponent(transfer.Amount.Currency()); err != nil {
+		transaction.Details = "Unsupported transfer currency"
+		p.recordFailure(transaction, models.FailureUnsupportedCurrency)
+		return nil, err
+	}
+	sender, err := p.userRepo.FindByID(senderID)
+	if err != nil {
+		transaction.Details = "Invalid customer ID"
+		p.recordFailure(transaction, models.FailureInvalidCustomer)
+		return nil, ErrInvalidCustomer
+	}
+	if reason, details, err := customerPaymentError(sender); err != nil {
+		transaction.Details = details
+		p.recordFailure(transaction, reason)
+		return nil, err
+	}
+
+	recipient, err := p.resolveRecipient(transfer.Recipient)
+	if err != nil {
+		transaction.Details = "Recipient not found"
+		p.recordFailure(transaction, models.FailureInvalidRecipient)
+		return nil, err
+	}
+	transaction.RecipientID = recipient.ID
+	if recipient.ID == sender.ID {
+		transaction.Details = "Sender and recipient must differ"
+		p.recordFailure(transaction, models.FailureSameParty)
+		return nil, ErrTransferToSelf
+	}
+	if err := accountStatusError(recipient.AccountStatus()); err != nil {
+		transaction.Details = "Recipient account is " + strings.ToLower(string(recipient.AccountStatus()))
+		p.recordFailure(transaction, models.FailureRecipientNotActive)
+		return nil, ErrRecipientNotActive
+	}
+
+	converted, err := p.inDefaultCurrency(transfer.Amount, transaction.Timestamp)
+	if err != nil {
+		transaction.Details = "Exchange rate not available"
+		p.recordFailure(transaction, models.FailureExchangeRateUnavailable)
+		return nil, ErrExchangeRateNotFound
+	}
+	transaction.LimitAmount = &converted
+	if reason, details, err := p.transferLimitError(*sender, converted, transaction.Timestamp); err != nil {
+		transaction.Details = details
+		p.recordFailure(transaction, reason)
+		return nil, err
+	}
+
+	entry, err := p.ledger.Transfer(sender.ID, recipient.ID, transfer.Amount, "Transfer to "+recipient.Username)
+	if errors.Is(err, ErrInsufficientBalance) {
+		transaction.Details = "Insufficient balance"
+		p.recordFailure(transaction, models.FailureInsufficientBalance)
+		return nil, err
+	}
+	if err != nil {
+		transaction.Details = "Failed to post ledger entry"
+		p.recordFailure(transaction, models.FailureLedgerError)
+		return nil, err
+	}
+
+	transaction.ActivityType = models.TransferActivity
+	transaction.Details = "Transfer to " + recipient.Username
+	transaction.JournalEntryID = entry.ID
+	if err := transaction.TransitionTo(models.TransactionSucceeded, time.Now()); err != nil {
+		return nil, err
+	}
+	trx, err := p.transactionRepo.CreateTransaction(transaction)
+	if err != nil {
+		return nil, err
+	}
+	transferResponse := mapper.TransactionModelToTransferResponse(trx, *recipient)
+	return &transferResponse, nil
+}
+
+// TransferLimits mengembalikan batas transfer userID beserta pemakaiannya dalam 24 jam terakhir
+func (p *transactionService) TransferLimits(userID string) (*response.TransferLimitsResponse, error) {
+	user, err := p.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	used, err := p.transferredSince(userID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	limitsResponse := mapper.UserModelToTransferLimitsResponse(*user, p.transferLimits)
+	remaining, err := limitsResponse.Daily.Sub(used)
+	if err != nil {
+		return nil, err
+	}
+	if remaining.IsNegative() {
+		remaining = models.NewMoney(0, models.DefaultCurrency)
+	}
+	limitsResponse.DailyUsed = &used
+	limitsResponse.DailyRemaining = &remaining
+	return &limitsResponse, nil
+}
+
+// transferLimitError memeriksa converted, yaitu amount dalam DefaultCurrency, terhadap batas per transfer
+// dan sisa batas harian sender
+func (p *transactionService) transferLimitError(sender models.User, converted models.Money, at time.Time) (models.FailureReason, string, error) {
+	limits := sender.EffectiveTransferLimits(p.transferLimits)
+	if cmp, err := converted.Cmp(limits.PerTransfer); err != nil || cmp > 0 {
+		return models.FailureTransferLimitExceeded, "Amount exceeds the per-transfer limit of " + limits.PerTransfer.String(), ErrTransferLimitExceeded
+	}
+
+	used, err := p.transferredSince(sender.ID, at.Add(-24*time.Hour))
+	if err == nil {
+		used, err = used.Add(converted)
+	}
+	if err != nil {
+		return models.FailureUnknown, "Failed to add up recent transfers", err
+	}
+	if cmp, err := used.Cmp(limits.Daily); err != nil || cmp > 0 {
+		return models.FailureDailyLimitExceeded, "Amount exceeds the daily transfer limit of " + limits.Daily.String(), ErrDailyTransferLimitExceeded
+	}
+	return "", "", nil
+}
+
+// transferredSince menjumlahkan transfer berhasil dari userID sejak since, dalam DefaultCurrency.
+// Yang dijumlahkan adalah LimitAmount yang disimpan saat transfer, bukan kurs yang dicari ulang.
+func (p *transactionService) transferredSince(userID string, since time.Time) (models.Money, error) {
+	transactions, err := p.transactionRepo.FindAllTransaction()
+	if err != nil {
+		return models.Money{}, err
+	}
+	total := models.NewMoney(0, models.DefaultCurrency)
+	for _, trx := range transactions {
+		if trx.ActivityType != models.TransferActivity || trx.CustomerID != userID || trx.Timestamp.Before(since) {
+			continue
+		}
+		amount := trx.Amount
+		if trx.LimitAmount != nil {
+			amount = *trx.LimitAmount
+		}
+		// Transfer lama tanpa LimitAmount dalam mata uang lain tidak bisa dihitung tanpa kurs
+		if amount.Currency() != models.DefaultCurrency {
+			continue
+		}
+		if total, err = total.Add(amount); err != nil {
+			return models.Money{}, err
+		}
+	}
+	return total, nil
+}
+
+func (p *transactionService) inDefaultCurrency(amount models.Money, at time.Time) (models.Money, error) {
+	if amount.Currency() == models.DefaultCurrency {
+		return amount, nil
+	}
+	quote, err := p.fx.Quote(amount.Currency(), models.DefaultCurrency, at)
+	if err != nil {
+		return models.Money{}, err
+	}
+	return quote.Convert(amount)
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"go-json/internal/dtos/response"
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	"go-json/internal/security"
+	"slices"
+	"time"
+)
+
+const (
+	TwoFactorIssuer   = "Merchant-Bank"
+	recoveryCodeCount = 10
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor authentication is not enrolled")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrTwoFactorRequired       = errors.New("two-factor authentication is required for your role")
+)
+
+type TwoFactorService interface {
+	Enroll(userID string) (*response.TwoFactorEnrollResponse, error)
+	Confirm(userID, code string) error
+	Disable(userID, code string) error
+	VerifyCode(userID, code string) error
+	IsEnabled(userID string) bool
+	IsRequired(userID string) bool
+}
+
+type twoFactorService struct {
+	twoFactorRepo repositories.TwoFactorRepository
+	userRepo      repositories.UserRepository
+	roleRepo      repositories.RoleRepository
+}
+
+func NewTwoFactorService(twoFactorRepo repositories.TwoFactorRepository, userRepo repositories.UserRepository, roleRepo repositories.RoleRepository) TwoFactorService {
+	return &twoFactorService{twoFactorRepo: twoFactorRepo, userRepo: userRepo, roleRepo: roleRepo}
+}
+
+// Enroll membuat secret dan recovery code baru. 2FA belum aktif sampai Confirm berhasil,
+// dan enrollment yang belum dikonfirmasi boleh diulang.
+func (s *twoFactorService) Enroll(userID string) (*response.TwoFactorEnrollResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if s.IsEnabled(userID) {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	recoveryCodes := []string{}
+	recoveryCodeHashes := []string{}
+	for range recoveryCodeCount {
+		code, err := security.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		recoveryCodes = append(recoveryCodes, code)
+		recoveryCodeHashes = append(recoveryCodeHashes, security.HashRecoveryCode(code))
+	}
+
+	err = s.twoFactorRepo.Save(models.TwoFactor{
+		UserID:             userID,
+		Secret:             secret,
+		RecoveryCodeHashes: recoveryCodeHashes,
+		CreatedAt:          time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &response.TwoFactorEnrollResponse{
+		Secret:        secret,
+		OTPAuthURI:    security.TOTPURI(TwoFactorIssuer, user.Username, secret),
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+// Confirm mengaktifkan 2FA dengan kode TOTP pertama dari aplikasi authenticator
+func (s *twoFactorService) Confirm(userID, code string) error {
+	twoFactor, err := s.twoFactorRepo.FindByUserID(userID)
+	if err != nil {
+		return ErrTwoFactorNotEnrolled
+	}
+	if twoFactor.Enabled {
+		return ErrTwoFactorAlreadyEnabled
+	}
+	step, ok := security.VerifyTOTP(twoFactor.Secret, code, time.Now(), twoFactor.LastUsedStep)
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	now := time.Now()
+	twoFactor.Enabled = true
+	twoFactor.EnabledAt = &now
+	twoFactor.LastUsedStep = step
+	return s.twoFactorRepo.Save(*twoFactor)
+}
+
+// Disable mematikan 2FA setelah kode terakhir dibuktikan, kecuali role user mewajibkannya
+func (s *twoFactorService) Disable(userID, code string) error {
+	if s.IsRequired(userID) {
+		return ErrTwoFactorRequired
+	}
+	if err := s.VerifyCode(userID, code); err != nil {
+		return err
+	}
+	return s.twoFactorRepo.Delete(userID)
+}
+
+// VerifyCode menerima kode TOTP atau recovery code. Recovery code hanya bisa dipakai sekali.
+func (s *twoFactorService) VerifyCode(userID, code string) error {
+	twoFactor, err := s.twoFactorRepo.FindByUserID(userID)
+	if err != nil || !twoFactor.Enabled {
+		return ErrTwoFactorNotEnrolled
+	}
+
+	if step, ok := security.VerifyTOTP(twoFactor.Secret, code, time.Now(), twoFactor.LastUsedStep); ok {
+		twoFactor.LastUsedStep = step
+		return s.twoFactorRepo.Save(*twoFactor)
+	}
+
+	hash := security.HashRecoveryCode(code)
+	index := slices.Index(twoFactor.RecoveryCodeHashes, hash)
+	if index < 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	twoFactor.RecoveryCodeHashes = slices.Delete(twoFactor.RecoveryCodeHashes, index, index+1)
+	return s.twoFactorRepo.Save(*twoFactor)
+}
+
+func (s *twoFactorService) IsEnabled(userID string) bool {
+	twoFactor, err := s.twoFactorRepo.FindByUserID(userID)
+	return err == nil && twoFactor.Enabled
+}
+
+// IsRequired bernilai true bila salah satu role user punya RequireTwoFactor
+func (s *twoFactorService) IsRequired(userID string) bool {
+	userRoles, err := s.roleRepo.FindRoleByUserID(userID)
+	if err != nil {
+		return false
+	}
+	for _, userRole := range *userRoles {
+		role, err := s.roleRepo.FindByRoleID(userRole.RoleID)
+		if err == nil && role.RequireTwoFactor {
+			return true
+		}
+	}
+	return false
+}
@@ -5,43 +5,77 @@ import (
 	"go-json/internal/dtos/mapper"
 	"go-json/internal/dtos/request"
 	"go-json/internal/dtos/response"
-
+	"go-json/internal/models"
 	"go-json/internal/repositories"
 	"go-json/internal/security"
+	"go-json/internal/validators"
+	"log"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
+var (
+	ErrRoleNotRegistrable = errors.New("role cannot be chosen at registration")
+	ErrInvalidChallenge   = errors.New("invalid or expired challenge token")
+)
+
 type UserService interface {
 	CreateUser(customer request.RegisterRequest) (*response.RegisterResponse, error)
-	Login(login request.LoginRequest) (*response.LoginResponse, error)
+	Login(login request.LoginRequest, clientIP string) (*response.LoginResponse, error)
+	VerifyLogin(verify request.VerifyLoginRequest, clientIP string) (*response.LoginResponse, error)
+	SetupTwoFactor(setup request.TwoFactorChallengeRequest) (*response.TwoFactorEnrollResponse, error)
 	Logout(token string) (*response.LogoutResponse, error)
+	LogoutAll(token string) (*response.LogoutResponse, error)
 	Refresh(token string) (*response.RefreshResponse, error)
-	FindAllUser() ([]*response.UserResponse, error)
+	FindAllUser(includeClosed bool) ([]*response.UserResponse, error)
 	//ProfileCustomer(id string)(*response.ProfileCustomerResponse, error)
 }
 
 type userService struct {
-	userRepo repositories.UserRepository
-	roleRepo repositories.RoleRepository
-	token    security.TokenService
+	userRepo        repositories.UserRepository
+	roleRepo        repositories.RoleRepository
+	refreshRepo     repositories.RefreshTokenRepository
+	revokedRepo     repositories.RevokedTokenRepository
+	transactionRepo repositories.TransactionRepository
+	token           security.TokenService
+	hasher          security.PasswordHasher
+	throttle        LoginThrottle
+	twoFactor       TwoFactorService
+	verification    EmailVerificationService
+	ledger          LedgerService
+	sandboxBalance  models.Money
 }
 
-func NewUserService(user repositories.UserRepository, role repositories.RoleRepository, token security.TokenService) UserService {
+func NewUserService(user repositories.UserRepository, role repositories.RoleRepository, refresh repositories.RefreshTokenRepository, revoked repositories.RevokedTokenRepository, transaction repositories.TransactionRepository, token security.TokenService, hasher security.PasswordHasher, throttle LoginThrottle, twoFactor TwoFactorService, verification EmailVerificationService, ledger LedgerService, sandboxBalance models.Money) UserService {
 	return &userService{
-		userRepo: user,
-		roleRepo: role,
-		token:    token,
+		userRepo:        user,
+		roleRepo:        role,
+		refreshRepo:     refresh,
+		revokedRepo:     revoked,
+		transactionRepo: transaction,
+		token:           token,
+		hasher:          hasher,
+		throttle:        throttle,
+		twoFactor:       twoFactor,
+		verification:    verification,
+		ledger:          ledger,
+		sandboxBalance:  sandboxBalance,
 	}
 }
 
+// CreateUser mendaftarkan user dengan status PENDING dan mengirim token verifikasi email
 func (s *userService) CreateUser(user request.RegisterRequest) (*response.RegisterResponse, error) {
-	validate := validator.New()
-	err := validate.Struct(user)
+	err := validators.NewCustomerValidator().Validate(user)
 	if err != nil {
 		return nil, err
 	}
 	userModel := mapper.UserRequestToModel(user)
+	hashedPassword, err := s.hasher.Hash(user.Password)
+	if err != nil {
+		return nil, err
+	}
+	userModel.Password = hashedPassword
 
 	var roleIDs []string
 	for _, roleName := range user.Role {
@@ -49,6 +83,9 @@ func (s *userService) CreateUser(user request.RegisterRequest) (*response.Regist
 		if err != nil {
 			return nil, err
 		}
+		if !role.Registrable {
+			return nil, ErrRoleNotRegistrable
+		}
 		roleIDs = append(roleIDs, role.ID)
 	}
 
@@ -56,51 +93,171 @@ func (s *userService) CreateUser(user request.RegisterRequest) (*response.Regist
 	if err != nil {
 		return nil, err
 	}
+	// Gagal mengirim email tidak membatalkan registrasi; user bisa meminta kirim ulang
+	if err := s.verification.SendVerification(*users); err != nil {
+		log.Printf("Failed to send verification email: %v", err)
+	}
+	// User baru mulai dengan saldo nol kecuali saldo sandbox diaktifkan
+	if s.sandboxBalance.IsPositive() {
+		if _, err := s.ledger.FundSandbox(users.ID, s.sandboxBalance); err != nil {
+			log.Printf("Failed to fund sandbox balance: %v", err)
+		} else if funded, err := s.userRepo.FindByID(users.ID); err == nil {
+			users = funded
+		}
+	}
 	userResponse := mapper.UserModelToResponse(*users)
 	return &userResponse, nil
 }
 
-func (s *userService) Login(login request.LoginRequest) (*response.LoginResponse, error) {
+// Login dibatasi per username dan per IP klien. Password tidak diperiksa selama masih
+// di-throttle, sehingga percobaan saat terkunci tidak membocorkan benar/salahnya password.
+// User dengan 2FA, atau yang role-nya mewajibkan 2FA, hanya mendapat challenge token.
+func (s *userService) Login(login request.LoginRequest, clientIP string) (*response.LoginResponse, error) {
 	validate := validator.New()
 	err := validate.Struct(login)
 	if err != nil {
 		return nil, err
 	}
 	loginReq := mapper.LoginRequestToModel(login)
-	customer, err := s.userRepo.FindByUsername(loginReq.Username)
+	// customer bernilai nil bila username tidak dikenal
+	customer, _ := s.userRepo.FindByUsername(loginReq.Username)
+
+	// Penolakan throttle tidak dicatat sebagai FAILED_LOGIN; cukup lockout-nya yang masuk audit log
+	if err := s.throttle.Check(loginReq.Username, clientIP); err != nil {
+		return nil, err
+	}
+	defer s.throttle.Release(loginReq.Username, clientIP)
+
+	if customer == nil {
+		s.loginFailed(nil, loginReq.Username, clientIP, models.FailureInvalidCredentials, "Invalid credentials")
+		return nil, ErrInvalidCredentials
+	}
+	match, err := s.hasher.Compare(customer.Password, login.Password)
+	if err != nil || !match {
+		s.loginFailed(customer, loginReq.Username, clientIP, models.FailureInvalidCredentials, "Invalid credentials")
+		return nil, ErrInvalidCredentials
+	}
+	// Status akun baru diperiksa setelah password cocok, supaya status tidak bocor ke penebak password
+	if err := accountStatusError(customer.AccountStatus()); err != nil {
+		s.recordFailedLogin(customer, customerStatusReason(err), "Login to "+string(customer.AccountStatus())+" account from "+clientIP)
+		return nil, err
+	}
+
+	// Password lama (plaintext atau cost berbeda) di-hash ulang setelah login berhasil
+	if s.hasher.NeedsRehash(customer.Password) {
+		hashedPassword, err := s.hasher.Hash(login.Password)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.userRepo.UpdatePassword(customer.ID, hashedPassword); err != nil {
+			return nil, err
+		}
+	}
+
+	purpose := ""
+	if s.twoFactor.IsEnabled(customer.ID) {
+		purpose = security.ChallengeTwoFactorVerify
+	} else if s.twoFactor.IsRequired(customer.ID) {
+		purpose = security.ChallengeTwoFactorSetup
+	}
+	if purpose == "" {
+		return s.completeLogin(customer)
+	}
+
+	challengeToken, err := s.token.GenerateChallengeToken(customer.ID, purpose)
 	if err != nil {
-		return nil, errors.New("invalid credentials")
+		return nil, err
 	}
-	if customer.Password != login.Password {
-		return nil, errors.New("invalid credentials")
+	challengeResponse := mapper.ToLoginChallengeResponse(challengeToken, purpose, s.token.ChallengeTokenTTL())
+	return &challengeResponse, nil
+}
+
+// VerifyLogin menyelesaikan login dua langkah. Untuk challenge 2fa_setup, kode pertama
+// sekaligus mengonfirmasi enrollment yang dibuat lewat SetupTwoFactor.
+func (s *userService) VerifyLogin(verify request.VerifyLoginRequest, clientIP string) (*response.LoginResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(verify); err != nil {
+		return nil, err
+	}
+	claims, err := s.token.VerifyChallengeToken(verify.ChallengeToken)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+	customer, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidChallenge
+	}
+	if err := accountStatusError(customer.AccountStatus()); err != nil {
+		return nil, err
 	}
 
-	customer.IsActive = true
-	err = s.userRepo.UpdateUser(*customer)
+	if err := s.throttle.Check(customer.Username, clientIP); err != nil {
+		return nil, err
+	}
+	defer s.throttle.Release(customer.Username, clientIP)
+
+	switch claims.Purpose {
+	case security.ChallengeTwoFactorVerify:
+		err = s.twoFactor.VerifyCode(customer.ID, verify.Code)
+	case security.ChallengeTwoFactorSetup:
+		err = s.twoFactor.Confirm(customer.ID, verify.Code)
+	default:
+		return nil, ErrInvalidChallenge
+	}
+	if errors.Is(err, ErrInvalidTwoFactorCode) {
+		s.loginFailed(customer, customer.Username, clientIP, models.FailureInvalidTwoFactorCode, "Invalid two-factor code")
+		return nil, err
+	}
 	if err != nil {
 		return nil, err
 	}
+	return s.completeLogin(customer)
+}
+
+// SetupTwoFactor mendaftarkan 2FA untuk user yang role-nya mewajibkan 2FA tetapi belum punya
+func (s *userService) SetupTwoFactor(setup request.TwoFactorChallengeRequest) (*response.TwoFactorEnrollResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(setup); err != nil {
+		return nil, err
+	}
+	claims, err := s.token.VerifyChallengeToken(setup.ChallengeToken)
+	if err != nil || claims.Purpose != security.ChallengeTwoFactorSetup {
+		return nil, ErrInvalidChallenge
+	}
+	return s.twoFactor.Enroll(claims.UserID)
+}
+
+func (s *userService) completeLogin(customer *models.User) (*response.LoginResponse, error) {
+	if err := s.throttle.Succeeded(customer.Username); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateLastLogin(customer.ID, time.Now()); err != nil {
+		return nil, err
+	}
 
-	userRoles, err := s.roleRepo.FindRoleByUserID(customer.ID)
+	roleNames, err := s.roleNamesByUserID(customer.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	var roleNames []string
-	for _, userRole := range *userRoles {
-		role, err := s.roleRepo.FindByRoleID(userRole.RoleID)
-		if err != nil {
-			return nil, err
-		}
-		roleNames = append(roleNames, role.Name)
+	refreshToken, sessionID, err := s.issueRefreshToken(customer.ID, "")
+	if err != nil {
+		return nil, err
 	}
 
-	token, err := s.token.GenerateToken(customer.Username, customer.Email, roleNames...)
+	token, err := s.token.GenerateToken(security.TokenSubject{
+		UserID:    customer.ID,
+		Username:  customer.Username,
+		Email:     customer.Email,
+		SessionID: sessionID,
+		Roles:     roleNames,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	customerResponse := mapper.UserModelToLoginResponse(token)
+	customerResponse := mapper.UserModelToLoginResponse(token, refreshToken, s.token.AccessTokenTTL())
 	return &customerResponse, nil
 }
 
@@ -110,43 +267,196 @@ func (s *userService) Logout(token string) (*response.LogoutResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	user, err := s.userRepo.FindByEmail(claims.Email)
+	user, err := s.userRepo.FindByID(claims.UserID)
 	if err != nil {
 		return nil, err
 	}
-	user.IsActive = false
-	err = s.userRepo.UpdateUser(*user)
+
+	err = s.revokedRepo.Revoke(models.RevokedToken{
+		JTI:       claims.ID,
+		UserID:    user.ID,
+		RevokedAt: time.Now(),
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	if claims.SessionID != "" {
+		if err := s.refreshRepo.RevokeFamily(claims.SessionID); err != nil {
+			return nil, err
+		}
+	}
+	return &logoutResponse, nil
+}
+
+// LogoutAll mencabut semua access token dan refresh token milik user
+func (s *userService) LogoutAll(token string) (*response.LogoutResponse, error) {
+	claims, err := s.token.VerifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.revokedRepo.Revoke(models.RevokedToken{
+		UserID:    user.ID,
+		RevokedAt: now,
+		ExpiresAt: now.Add(s.token.AccessTokenTTL()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshRepo.RevokeByUserID(user.ID); err != nil {
+		return nil, err
+	}
+
+	logoutResponse := mapper.ToLogoutAllResponse(token)
 	return &logoutResponse, nil
 }
 
-func (s *userService) Refresh(token string) (*response.RefreshResponse, error) {
-	refreshResponse := mapper.ToRefreshResponse(token)
+func (s *userService) loginFailed(customer *models.User, username, clientIP string, reason models.FailureReason, details string) {
+	if err := s.throttle.Failed(username, clientIP); err != nil {
+		log.Printf("Failed to record failed login attempt: %v", err)
+	}
+	s.recordFailedLogin(customer, reason, details+" from "+clientIP)
+}
+
+// recordFailedLogin mencatat FAILED_LOGIN di riwayat transaksi; username yang tidak
+// dikenal dicatat tanpa customer ID.
+func (s *userService) recordFailedLogin(customer *models.User, reason models.FailureReason, details string) {
+	transaction := models.Transaction{
+		ActivityType: models.FailedLogin,
+		Timestamp:    time.Now(),
+		Details:      details,
+	}
+	if customer != nil {
+		transaction.CustomerID = customer.ID
+	}
+	transaction.Begin(transaction.Timestamp)
+	transaction.Fail(reason, transaction.Timestamp)
+	if _, err := s.transactionRepo.CreateTransaction(transaction); err != nil {
+		log.Printf("Failed to record failed login: %v", err)
+	}
+}
+
+// Refresh menukar refresh token dengan pasangan token baru. Refresh token hanya
+// bisa dipakai sekali; pemakaian ulang token yang sudah dirotasi mencabut seluruh family.
+func (s *userService) Refresh(refreshToken string) (*response.RefreshResponse, error) {
+	// Consume sekaligus mendeteksi pemakaian ulang dan mencabut family-nya
+	stored, err := s.refreshRepo.Consume(s.token.HashRefreshToken(refreshToken))
+	if errors.Is(err, models.ErrRefreshTokenReused) && stored != nil {
+		// Access token dari sesi yang sama ikut dicabut, termasuk yang mungkin dipegang pencuri
+		now := time.Now()
+		revokeErr := s.revokedRepo.Revoke(models.RevokedToken{
+			UserID:    stored.UserID,
+			SessionID: stored.FamilyID,
+			RevokedAt: now,
+			ExpiresAt: now.Add(s.token.AccessTokenTTL()),
+		})
+		if revokeErr != nil {
+			log.Printf("Failed to revoke session %s: %v", stored.FamilyID, revokeErr)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(stored.UserID)
+	if err != nil {
+		return nil, models.ErrRefreshTokenNotFound
+	}
+	if err := accountStatusError(user.AccountStatus()); err != nil {
+		return nil, err
+	}
+
+	roleNames, err := s.roleNamesByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	newRefreshToken, _, err := s.issueRefreshToken(user.ID, stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.token.GenerateToken(security.TokenSubject{
+		UserID:    user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		SessionID: stored.FamilyID,
+		Roles:     roleNames,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refreshResponse := mapper.ToRefreshResponse(token, newRefreshToken, s.token.AccessTokenTTL())
 	return &refreshResponse, nil
 }
 
-func (s *userService) FindAllUser() ([]*response.UserResponse, error) {
-	customers, err := s.userRepo.FindAll()
+// issueRefreshToken mengembalikan refresh token beserta family ID-nya, yang juga
+// dipakai sebagai session ID pada access token.
+func (s *userService) issueRefreshToken(userID, familyID string) (string, string, error) {
+	refreshToken, err := s.token.GenerateRefreshToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	stored, err := s.refreshRepo.CreateRefreshToken(models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: s.token.HashRefreshToken(refreshToken),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.token.RefreshTokenTTL()),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return refreshToken, stored.FamilyID, nil
+}
+
+func (s *userService) roleNamesByUserID(userID string) ([]string, error) {
+	userRoles, err := s.roleRepo.FindRoleByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	var userResponses []*response.UserResponse
-	for _, customer := range customers {
-		userRoles, err := s.roleRepo.FindRoleByUserID(customer.ID)
+	var roleNames []string
+	for _, userRole := range *userRoles {
+		role, err := s.roleRepo.FindByRoleID(userRole.RoleID)
 		if err != nil {
 			return nil, err
 		}
+		roleNames = append(roleNames, role.Name)
+	}
+	return roleNames, nil
+}
 
-		var roleNames []string
-		for _, userRole := range *userRoles {
-			role, err := s.roleRepo.FindByRoleID(userRole.RoleID)
+// FindAllUser menyembunyikan akun CLOSED kecuali includeClosed bernilai true
+func (s *userService) FindAllUser(includeClosed bool) ([]*response.UserResponse, error) {
+	customers, err := s.userRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	userResponses := []*response.UserResponse{}
+	for _, customer := range customers {
+		if customer.AccountStatus() == models.AccountClosed && !includeClosed {
+			continue
+		}
+		// User lama bisa belum punya baris di user_roles; tampilkan dengan role kosong
+		roleNames := []string{}
+		if _, err := s.roleRepo.FindRoleByUserID(customer.ID); err == nil {
+			roleNames, err = s.roleNamesByUserID(customer.ID)
 			if err != nil {
 				return nil, err
 			}
-			roleNames = append(roleNames, role.Name)
 		}
 
 		userResponse := mapper.UserModelToUserResponse(customer, roleNames)
@@ -24,7 +24,9 @@ func init() {
 }
 
 func main() {
-	routes.InitRoute()
+	if err := routes.InitRoute(); err != nil {
+		log.Fatalf("Failed to load data files: %v", err)
+	}
 	fmt.Println(constant.URL)
 	parsedURL, err := url.Parse(constant.URL)
 	if err != nil {
@@ -1,9 +1,20 @@
 package constant_test
 
 const (
-	USER_FILE        = "./data/users.json"
-	MERCHANT_FILE    = "./data/merchants.json"
-	TRANSACTION_FILE = "./data/transactions.json"
-	ROLE_FILE        = "./data/roles.json"
-	USER_ROLE_FILE   = "./data/user_roles.json"
+	USER_FILE           = "./data/users.json"
+	MERCHANT_FILE       = "./data/merchants.json"
+	TRANSACTION_FILE    = "./data/transactions.json"
+	ROLE_FILE           = "./data/roles.json"
+	USER_ROLE_FILE      = "./data/user_roles.json"
+	REFRESH_TOKEN_FILE  = "./data/refresh_tokens.json"
+	REVOKED_TOKEN_FILE  = "./data/revoked_tokens.json"
+	LEDGER_ACCOUNT_FILE = "./data/ledger_accounts.json"
+	JOURNAL_ENTRY_FILE  = "./data/journal_entries.json"
+	IDEMPOTENCY_FILE    = "./data/idempotency_keys.json"
+	FX_RATE_FILE        = "./data/fx_rates.json"
+	AUDIT_FILE          = "./data/audit_log.json"
+	LOGIN_ATTEMPT_FILE  = "./data/login_attempts.json"
+	TWO_FACTOR_FILE     = "./data/two_factor.json"
+	ONE_TIME_TOKEN_FILE = "./data/one_time_tokens.json"
+	API_KEY_FILE        = "./data/api_keys.json"
 )
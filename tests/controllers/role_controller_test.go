@@ -0,0 +1,143 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-json/internal/controllers"
+	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
+	"go-json/internal/security"
+	"go-json/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockRoleService struct {
+	mock.Mock
+}
+
+func (m *MockRoleService) FindAllRoles() ([]response.RoleResponse, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]response.RoleResponse), args.Error(1)
+}
+
+func (m *MockRoleService) CreateRole(actor security.Principal, role request.CreateRoleRequest) (*response.RoleResponse, error) {
+	args := m.Called(actor, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.RoleResponse), args.Error(1)
+}
+
+func (m *MockRoleService) GrantPermissions(actor security.Principal, roleID string, grant request.RolePermissionsRequest) (*response.RoleResponse, error) {
+	args := m.Called(actor, roleID, grant)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.RoleResponse), args.Error(1)
+}
+
+func (m *MockRoleService) RevokePermission(actor security.Principal, roleID, permission string) (*response.RoleResponse, error) {
+	args := m.Called(actor, roleID, permission)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.RoleResponse), args.Error(1)
+}
+
+func (m *MockRoleService) AssignRole(actor security.Principal, userID string, assign request.AssignRoleRequest) (*response.UserRolesResponse, error) {
+	args := m.Called(actor, userID, assign)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.UserRolesResponse), args.Error(1)
+}
+
+func (m *MockRoleService) UnassignRole(actor security.Principal, userID, roleID string) (*response.UserRolesResponse, error) {
+	args := m.Called(actor, userID, roleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.UserRolesResponse), args.Error(1)
+}
+
+func (m *MockRoleService) SetTwoFactorRequired(actor security.Principal, roleID string, policy request.RoleTwoFactorRequest) (*response.RoleResponse, error) {
+	args := m.Called(actor, roleID, policy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.RoleResponse), args.Error(1)
+}
+
+type RoleControllerTestSuite struct {
+	suite.Suite
+	roleService *MockRoleService
+	router      *mux.Router
+}
+
+func (suite *RoleControllerTestSuite) SetupTest() {
+	suite.roleService = new(MockRoleService)
+	controller := controllers.NewRoleController(suite.roleService)
+	suite.router = mux.NewRouter()
+	suite.router.HandleFunc("/admin/roles", controller.CreateRole).Methods("POST")
+	suite.router.HandleFunc("/admin/users/{id}/roles", controller.AssignRole).Methods("POST")
+}
+
+func (suite *RoleControllerTestSuite) serve(path string, body interface{}) *httptest.ResponseRecorder {
+	reqBody, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", path, bytes.NewBuffer(reqBody))
+	req = withPrincipal(req, "9", "admin")
+	rr := httptest.NewRecorder()
+	suite.router.ServeHTTP(rr, req)
+	return rr
+}
+
+func (suite *RoleControllerTestSuite) TestCreateRole() {
+	createReq := request.CreateRoleRequest{Name: "support", Permissions: []string{"user:list"}}
+	suite.roleService.On("CreateRole", mock.Anything, createReq).Return(&response.RoleResponse{ID: "4", Name: "support"}, nil)
+
+	rr := suite.serve("/admin/roles", createReq)
+
+	assert.Equal(suite.T(), http.StatusCreated, rr.Code)
+	suite.roleService.AssertExpectations(suite.T())
+}
+
+func (suite *RoleControllerTestSuite) TestCreateRoleErrors() {
+	cases := map[string]struct {
+		err    error
+		status int
+	}{
+		"unknown":  {services.ErrUnknownPermission, http.StatusBadRequest},
+		"existing": {services.ErrRoleExists, http.StatusConflict},
+	}
+	for name, c := range cases {
+		createReq := request.CreateRoleRequest{Name: name}
+		suite.roleService.On("CreateRole", mock.Anything, createReq).Return(nil, c.err)
+
+		rr := suite.serve("/admin/roles", createReq)
+
+		assert.Equal(suite.T(), c.status, rr.Code, name)
+	}
+}
+
+func (suite *RoleControllerTestSuite) TestAssignRoleUnknownUser() {
+	assignReq := request.AssignRoleRequest{Role: "merchant"}
+	suite.roleService.On("AssignRole", mock.Anything, "99", assignReq).Return(nil, services.ErrUserNotFound)
+
+	rr := suite.serve("/admin/users/99/roles", assignReq)
+
+	assert.Equal(suite.T(), http.StatusNotFound, rr.Code)
+}
+
+func TestRoleControllerSuite(t *testing.T) {
+	suite.Run(t, new(RoleControllerTestSuite))
+}
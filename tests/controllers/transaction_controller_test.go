@@ -8,6 +8,8 @@ import (
 	"go-json/internal/dtos/request"
 	"go-json/internal/dtos/response"
 	"go-json/internal/models"
+	"go-json/internal/security"
+	"go-json/internal/services"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -23,22 +25,125 @@ type MockTransactionService struct {
 	mock.Mock
 }
 
-func (m *MockTransactionService) ProcessPayment(payment request.PaymentRequest) (*response.PaymentResponse, error) {
-	args := m.Called(payment)
+func (m *MockTransactionService) ProcessPayment(customerID string, payment request.PaymentRequest) (*response.PaymentResponse, error) {
+	args := m.Called(customerID, payment)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*response.PaymentResponse), args.Error(1)
 }
 
-func (m *MockTransactionService) TransactionHistoryByUserID(userID string) ([]response.UserTransactionHistoryResponse, error) {
-	args := m.Called(userID)
+func (m *MockTransactionService) ProcessPaymentOnBehalf(actorID string, payment request.PaymentRequest) (*response.PaymentResponse, error) {
+	args := m.Called(actorID, payment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.PaymentResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) RefundPayment(transactionID, merchantID string, refund request.RefundRequest) (*response.RefundResponse, error) {
+	args := m.Called(transactionID, merchantID, refund)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.RefundResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) TransactionHistoryByUserID(viewer security.Principal, userID string) ([]response.UserTransactionHistoryResponse, error) {
+	args := m.Called(viewer, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]response.UserTransactionHistoryResponse), args.Error(1)
 }
 
+func (m *MockTransactionService) AuthorizePayment(customerID string, authorization request.AuthorizationRequest) (*response.AuthorizationResponse, error) {
+	args := m.Called(customerID, authorization)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.AuthorizationResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) CaptureAuthorization(authorizationID, merchantID string, capture request.CaptureRequest) (*response.AuthorizationResponse, error) {
+	args := m.Called(authorizationID, merchantID, capture)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.AuthorizationResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) VoidAuthorization(authorizationID, merchantID string, void request.VoidRequest) (*response.AuthorizationResponse, error) {
+	args := m.Called(authorizationID, merchantID, void)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.AuthorizationResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) ExpireAuthorizations() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) BackfillStatuses() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) Deposit(userID string, deposit request.BankTransferRequest) (*response.BankTransferResponse, error) {
+	args := m.Called(userID, deposit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.BankTransferResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) Withdraw(userID string, withdrawal request.BankTransferRequest) (*response.BankTransferResponse, error) {
+	args := m.Called(userID, withdrawal)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.BankTransferResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) ResumeBankTransfers() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockTransactionService) FindRecipient(senderID, query string) (*response.RecipientResponse, error) {
+	args := m.Called(senderID, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.RecipientResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) Transfer(senderID string, transfer request.TransferRequest) (*response.TransferResponse, error) {
+	args := m.Called(senderID, transfer)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.TransferResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) TransferLimits(userID string) (*response.TransferLimitsResponse, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.TransferLimitsResponse), args.Error(1)
+}
+
+func idr(amount string) models.Money {
+	return models.MustParseMoney(amount, models.DefaultCurrency)
+}
+
+func withPrincipal(req *http.Request, userID string, roles ...string) *http.Request {
+	return req.WithContext(security.WithPrincipal(req.Context(), security.Principal{UserID: userID, Roles: roles}))
+}
+
 type TransactionControllerTestSuite struct {
 	suite.Suite
 	transactionService *MockTransactionService
@@ -54,18 +159,18 @@ func (suite *TransactionControllerTestSuite) TestPayment() {
 	paymentReq := request.PaymentRequest{
 		CustomerID: "1",
 		MerchantID: "2",
-		Amount:     500.0,
+		Amount:     idr("500"),
 	}
 
 	paymentResp := &response.PaymentResponse{
 		ID:         "1",
 		CustomerID: "1",
 		MerchantID: "2",
-		Amount:     500.0,
+		Amount:     idr("500"),
 		Timestamp:  time.Now(),
 	}
 
-	suite.transactionService.On("ProcessPayment", mock.MatchedBy(func(req request.PaymentRequest) bool {
+	suite.transactionService.On("ProcessPayment", "1", mock.MatchedBy(func(req request.PaymentRequest) bool {
 		return req.CustomerID == paymentReq.CustomerID &&
 			req.MerchantID == paymentReq.MerchantID &&
 			req.Amount == paymentReq.Amount
@@ -74,6 +179,7 @@ func (suite *TransactionControllerTestSuite) TestPayment() {
 	reqBody, _ := json.Marshal(paymentReq)
 	req, _ := http.NewRequest("POST", "/payment", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	req = withPrincipal(req, "1", "customer")
 
 	rr := httptest.NewRecorder()
 
@@ -96,10 +202,10 @@ func (suite *TransactionControllerTestSuite) TestPaymentError() {
 	paymentReq := request.PaymentRequest{
 		CustomerID: "1",
 		MerchantID: "2",
-		Amount:     500.0,
+		Amount:     idr("500"),
 	}
 
-	suite.transactionService.On("ProcessPayment", mock.MatchedBy(func(req request.PaymentRequest) bool {
+	suite.transactionService.On("ProcessPayment", "1", mock.MatchedBy(func(req request.PaymentRequest) bool {
 		return req.CustomerID == paymentReq.CustomerID &&
 			req.MerchantID == paymentReq.MerchantID &&
 			req.Amount == paymentReq.Amount
@@ -108,6 +214,7 @@ func (suite *TransactionControllerTestSuite) TestPaymentError() {
 	reqBody, _ := json.Marshal(paymentReq)
 	req, _ := http.NewRequest("POST", "/payment", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	req = withPrincipal(req, "1", "customer")
 
 	rr := httptest.NewRecorder()
 
@@ -119,10 +226,37 @@ func (suite *TransactionControllerTestSuite) TestPaymentError() {
 	suite.transactionService.AssertExpectations(suite.T())
 }
 
+func (suite *TransactionControllerTestSuite) TestPaymentBusinessErrors() {
+	cases := map[string]struct {
+		err    error
+		status int
+	}{
+		"2": {services.ErrPaymentAmount, http.StatusBadRequest},
+		"3": {models.ErrUnsupportedCurrency, http.StatusBadRequest},
+		"4": {services.ErrSameParty, http.StatusBadRequest},
+		"5": {services.ErrInsufficientBalance, http.StatusUnprocessableEntity},
+		"6": {services.ErrExchangeRateNotFound, http.StatusUnprocessableEntity},
+	}
+
+	for merchantID, c := range cases {
+		paymentReq := request.PaymentRequest{MerchantID: merchantID, Amount: idr("500")}
+		suite.transactionService.On("ProcessPayment", "1", paymentReq).Return(nil, c.err)
+
+		reqBody, _ := json.Marshal(paymentReq)
+		req, _ := http.NewRequest("POST", "/payment", bytes.NewBuffer(reqBody))
+		req = withPrincipal(req, "1", "customer")
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(suite.controller.Payment).ServeHTTP(rr, req)
+
+		assert.Equal(suite.T(), c.status, rr.Code, merchantID)
+	}
+}
+
 func (suite *TransactionControllerTestSuite) TestPaymentInvalidJSON() {
 	reqBody := []byte(`{"bad json`)
 	req, _ := http.NewRequest("POST", "/payment", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	req = withPrincipal(req, "1", "customer")
 
 	rr := httptest.NewRecorder()
 
@@ -132,13 +266,135 @@ func (suite *TransactionControllerTestSuite) TestPaymentInvalidJSON() {
 	assert.Equal(suite.T(), http.StatusBadRequest, rr.Code)
 }
 
+func (suite *TransactionControllerTestSuite) TestPaymentWithoutPrincipal() {
+	reqBody, _ := json.Marshal(request.PaymentRequest{MerchantID: "2", Amount: idr("500")})
+	req, _ := http.NewRequest("POST", "/payment", bytes.NewBuffer(reqBody))
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(suite.controller.Payment).ServeHTTP(rr, req)
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, rr.Code)
+	suite.transactionService.AssertNotCalled(suite.T(), "ProcessPayment", mock.Anything, mock.Anything)
+}
+
+func (suite *TransactionControllerTestSuite) TestPaymentForAnotherCustomer() {
+	paymentReq := request.PaymentRequest{CustomerID: "3", MerchantID: "2", Amount: idr("500")}
+	suite.transactionService.On("ProcessPayment", "1", paymentReq).Return(nil, services.ErrCustomerMismatch)
+
+	reqBody, _ := json.Marshal(paymentReq)
+	req, _ := http.NewRequest("POST", "/payment", bytes.NewBuffer(reqBody))
+	req = withPrincipal(req, "1", "customer")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(suite.controller.Payment).ServeHTTP(rr, req)
+
+	assert.Equal(suite.T(), http.StatusForbidden, rr.Code)
+	suite.transactionService.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionControllerTestSuite) TestPaymentOnBehalf() {
+	paymentReq := request.PaymentRequest{CustomerID: "3", MerchantID: "2", Amount: idr("500")}
+	paymentResp := &response.PaymentResponse{ID: "1", CustomerID: "3", MerchantID: "2", Amount: idr("500"), InitiatedBy: "9"}
+	suite.transactionService.On("ProcessPaymentOnBehalf", "9", paymentReq).Return(paymentResp, nil)
+
+	reqBody, _ := json.Marshal(paymentReq)
+	req, _ := http.NewRequest("POST", "/trx/create-on-behalf", bytes.NewBuffer(reqBody))
+	req = withPrincipal(req, "9", "admin")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(suite.controller.PaymentOnBehalf).ServeHTTP(rr, req)
+
+	assert.Equal(suite.T(), http.StatusOK, rr.Code)
+	suite.transactionService.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionControllerTestSuite) TestDepositWaitsForBank() {
+	depositReq := request.BankTransferRequest{
+		Amount:      idr("50000"),
+		BankAccount: request.SettlementAccountRequest{BankCode: "BCA", AccountNumber: "1234567890", AccountHolder: "Test User"},
+	}
+	depositResp := &response.BankTransferResponse{ID: "7", CustomerID: "1", ActivityType: string(models.DepositActivity), Status: models.TransactionPending, Amount: idr("50000")}
+	suite.transactionService.On("Deposit", "1", depositReq).Return(depositResp, nil)
+
+	reqBody, _ := json.Marshal(depositReq)
+	req, _ := http.NewRequest("POST", "/trx/deposit", bytes.NewBuffer(reqBody))
+	req = withPrincipal(req, "1", "customer")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(suite.controller.Deposit).ServeHTTP(rr, req)
+
+	assert.Equal(suite.T(), http.StatusAccepted, rr.Code)
+	suite.transactionService.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionControllerTestSuite) TestWithdrawInsufficientBalance() {
+	withdrawReq := request.BankTransferRequest{
+		Amount:      idr("50000"),
+		BankAccount: request.SettlementAccountRequest{BankCode: "BCA", AccountNumber: "1234567890", AccountHolder: "Test User"},
+	}
+	suite.transactionService.On("Withdraw", "1", withdrawReq).Return(nil, services.ErrInsufficientBalance)
+
+	reqBody, _ := json.Marshal(withdrawReq)
+	req, _ := http.NewRequest("POST", "/trx/withdraw", bytes.NewBuffer(reqBody))
+	req = withPrincipal(req, "1", "customer")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(suite.controller.Withdraw).ServeHTTP(rr, req)
+
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, rr.Code)
+	suite.transactionService.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionControllerTestSuite) TestTransfer() {
+	transferReq := request.TransferRequest{Recipient: "friend01", Amount: idr("400"), Note: "Dinner"}
+	transferResp := &response.TransferResponse{ID: "30", SenderID: "1", RecipientID: "3", RecipientUsername: "friend01", Amount: idr("400")}
+	suite.transactionService.On("Transfer", "1", transferReq).Return(transferResp, nil)
+
+	reqBody, _ := json.Marshal(transferReq)
+	req, _ := http.NewRequest("POST", "/trx/transfer", bytes.NewBuffer(reqBody))
+	req = withPrincipal(req, "1", "customer")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(suite.controller.Transfer).ServeHTTP(rr, req)
+
+	assert.Equal(suite.T(), http.StatusOK, rr.Code)
+	suite.transactionService.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionControllerTestSuite) TestTransferOverDailyLimit() {
+	transferReq := request.TransferRequest{Recipient: "friend01", Amount: idr("400")}
+	suite.transactionService.On("Transfer", "1", transferReq).Return(nil, services.ErrDailyTransferLimitExceeded)
+
+	reqBody, _ := json.Marshal(transferReq)
+	req, _ := http.NewRequest("POST", "/trx/transfer", bytes.NewBuffer(reqBody))
+	req = withPrincipal(req, "1", "customer")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(suite.controller.Transfer).ServeHTTP(rr, req)
+
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, rr.Code)
+}
+
+func (suite *TransactionControllerTestSuite) TestFindRecipientNotFound() {
+	suite.transactionService.On("FindRecipient", "1", "nobody").Return(nil, services.ErrRecipientNotFound)
+
+	req, _ := http.NewRequest("GET", "/trx/transfer/recipient?q=nobody", nil)
+	req = withPrincipal(req, "1", "customer")
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(suite.controller.FindRecipient).ServeHTTP(rr, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, rr.Code)
+}
+
 func (suite *TransactionControllerTestSuite) TestTransactionHistory() {
 	userID := "1"
-	user := models.User{
+	balance := idr("1000")
+	user := response.TransactionHistoryUserResponse{
 		ID:       "1",
 		Username: "testuser",
 		Email:    "test@example.com",
-		Balance:  1000.0,
+		Balance:  &balance,
 	}
 
 	transactions := []*models.Transaction{
@@ -149,7 +405,7 @@ func (suite *TransactionControllerTestSuite) TestTransactionHistory() {
 			ActivityType: models.PaymentActivity,
 			Timestamp:    time.Now(),
 			Details:      "Transaction 1",
-			Amount:       100.0,
+			Amount:       idr("100"),
 		},
 		{
 			ID:           "2",
@@ -158,7 +414,7 @@ func (suite *TransactionControllerTestSuite) TestTransactionHistory() {
 			ActivityType: models.PaymentActivity,
 			Timestamp:    time.Now(),
 			Details:      "Transaction 2",
-			Amount:       200.0,
+			Amount:       idr("200"),
 		},
 	}
 
@@ -170,10 +426,12 @@ func (suite *TransactionControllerTestSuite) TestTransactionHistory() {
 		},
 	}
 
-	suite.transactionService.On("TransactionHistoryByUserID", userID).Return(historyResponse, nil)
+	viewer := security.Principal{UserID: "1", Roles: []string{"customer"}}
+	suite.transactionService.On("TransactionHistoryByUserID", viewer, userID).Return(historyResponse, nil)
 
 	// Create a new request
 	req, _ := http.NewRequest("GET", "/transactions/1", nil)
+	req = withPrincipal(req, "1", "customer")
 	// Create a router to use the route variables
 	router := mux.NewRouter()
 	router.HandleFunc("/transactions/{id}", suite.controller.TransactionHistory)
@@ -194,6 +452,7 @@ func (suite *TransactionControllerTestSuite) TestTransactionHistory() {
 
 	assert.Equal(suite.T(), http.StatusOK, apiResp.Status)
 	assert.Equal(suite.T(), "Transaction history retrieved", apiResp.Message)
+	assert.NotContains(suite.T(), rr.Body.String(), "password")
 
 	// Cast the data to the expected type and check values
 	historyData, ok := apiResp.Data.([]interface{})
@@ -206,10 +465,12 @@ func (suite *TransactionControllerTestSuite) TestTransactionHistory() {
 func (suite *TransactionControllerTestSuite) TestTransactionHistoryError() {
 	userID := "999" // Non-existent user
 
-	suite.transactionService.On("TransactionHistoryByUserID", userID).Return(nil, errors.New("user not found"))
+	viewer := security.Principal{UserID: "9", Roles: []string{"admin"}}
+	suite.transactionService.On("TransactionHistoryByUserID", viewer, userID).Return(nil, errors.New("user not found"))
 
 	// Create a new request
 	req, _ := http.NewRequest("GET", "/transactions/999", nil)
+	req = withPrincipal(req, "9", "admin")
 	// Create a router to use the route variables
 	router := mux.NewRouter()
 	router.HandleFunc("/transactions/{id}", suite.controller.TransactionHistory)
@@ -226,6 +487,22 @@ func (suite *TransactionControllerTestSuite) TestTransactionHistoryError() {
 	suite.transactionService.AssertExpectations(suite.T())
 }
 
+func (suite *TransactionControllerTestSuite) TestTransactionHistoryForbidden() {
+	viewer := security.Principal{UserID: "3", Roles: []string{"customer"}}
+	suite.transactionService.On("TransactionHistoryByUserID", viewer, "1").Return(nil, services.ErrHistoryAccessDenied)
+
+	req, _ := http.NewRequest("GET", "/transactions/1", nil)
+	req = withPrincipal(req, "3", "customer")
+	router := mux.NewRouter()
+	router.HandleFunc("/transactions/{id}", suite.controller.TransactionHistory)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(suite.T(), http.StatusForbidden, rr.Code)
+	suite.transactionService.AssertExpectations(suite.T())
+}
+
 func (suite *TransactionControllerTestSuite) TestTransactionHistoryNoID() {
 	// Create a new request with no ID
 	req, _ := http.NewRequest("GET", "/transactions/", nil)
@@ -242,6 +519,105 @@ func (suite *TransactionControllerTestSuite) TestTransactionHistoryNoID() {
 	assert.Equal(suite.T(), http.StatusBadRequest, rr.Code)
 }
 
+func (suite *TransactionControllerTestSuite) TestRefund() {
+	refundReq := request.RefundRequest{Amount: idr("50")}
+	refundResp := &response.RefundResponse{
+		ID:                    "2",
+		OriginalTransactionID: "1",
+		Amount:                idr("50"),
+		RefundedAmount:        idr("50"),
+		RefundableAmount:      idr("50"),
+	}
+
+	suite.transactionService.On("RefundPayment", "1", "2", refundReq).Return(refundResp, nil)
+
+	reqBody, _ := json.Marshal(refundReq)
+	req, _ := http.NewRequest("POST", "/trx/1/refund", bytes.NewBuffer(reqBody))
+	req = withPrincipal(req, "2", "merchant")
+	router := mux.NewRouter()
+	router.HandleFunc("/trx/{id}/refund", suite.controller.Refund)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(suite.T(), http.StatusOK, rr.Code)
+	suite.transactionService.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionControllerTestSuite) TestRefundErrors() {
+	cases := map[string]struct {
+		err    error
+		status int
+	}{
+		"1": {services.ErrTransactionNotFound, http.StatusNotFound},
+		"2": {services.ErrRefundNotAllowed, http.StatusForbidden},
+		"3": {services.ErrRefundExceedsPayment, http.StatusUnprocessableEntity},
+		"4": {models.ErrCurrencyMismatch, http.StatusBadRequest},
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/trx/{id}/refund", suite.controller.Refund)
+
+	for id, c := range cases {
+		suite.transactionService.On("RefundPayment", id, "2", request.RefundRequest{}).Return(nil, c.err)
+
+		req, _ := http.NewRequest("POST", "/trx/"+id+"/refund", nil)
+		req = withPrincipal(req, "2", "merchant")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(suite.T(), c.status, rr.Code, id)
+	}
+}
+
+func (suite *TransactionControllerTestSuite) TestAuthorize() {
+	authorizationReq := request.AuthorizationRequest{MerchantID: "2", Amount: idr("300")}
+	suite.transactionService.On("AuthorizePayment", "1", authorizationReq).Return(&response.AuthorizationResponse{
+		ID:              "5",
+		AuthorizationID: "5",
+		Amount:          idr("300"),
+		HeldAmount:      idr("300"),
+		Status:          models.AuthorizationOpen,
+	}, nil)
+
+	reqBody, _ := json.Marshal(authorizationReq)
+	req, _ := http.NewRequest("POST", "/trx/authorize", bytes.NewBuffer(reqBody))
+	req = withPrincipal(req, "1", "customer")
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(suite.controller.Authorize).ServeHTTP(rr, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, rr.Code)
+	suite.transactionService.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionControllerTestSuite) TestCaptureErrors() {
+	cases := map[string]struct {
+		err    error
+		status int
+	}{
+		"1": {services.ErrAuthorizationNotFound, http.StatusNotFound},
+		"2": {services.ErrCaptureNotAllowed, http.StatusForbidden},
+		"3": {services.ErrAuthorizationClosed, http.StatusConflict},
+		"4": {services.ErrAuthorizationExpired, http.StatusConflict},
+		"5": {services.ErrCaptureExceedsHold, http.StatusUnprocessableEntity},
+		"6": {services.ErrMerchantSuspended, http.StatusForbidden},
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/trx/authorizations/{id}/capture", suite.controller.Capture)
+
+	for id, c := range cases {
+		suite.transactionService.On("CaptureAuthorization", id, "2", request.CaptureRequest{}).Return(nil, c.err)
+
+		req, _ := http.NewRequest("POST", "/trx/authorizations/"+id+"/capture", nil)
+		req = withPrincipal(req, "2", "merchant")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(suite.T(), c.status, rr.Code, id)
+	}
+}
+
 func TestTransactionControllerSuite(t *testing.T) {
 	suite.Run(t, new(TransactionControllerTestSuite))
 }
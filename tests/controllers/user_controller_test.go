@@ -7,8 +7,10 @@ import (
 	"go-json/internal/controllers"
 	"go-json/internal/dtos/request"
 	"go-json/internal/dtos/response"
+	"go-json/internal/services"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -27,14 +29,30 @@ func (m *MockUserService) CreateUser(user request.RegisterRequest) (*response.Re
 	return args.Get(0).(*response.RegisterResponse), args.Error(1)
 }
 
-func (m *MockUserService) Login(login request.LoginRequest) (*response.LoginResponse, error) {
-	args := m.Called(login)
+func (m *MockUserService) Login(login request.LoginRequest, clientIP string) (*response.LoginResponse, error) {
+	args := m.Called(login, clientIP)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*response.LoginResponse), args.Error(1)
 }
 
+func (m *MockUserService) VerifyLogin(verify request.VerifyLoginRequest, clientIP string) (*response.LoginResponse, error) {
+	args := m.Called(verify, clientIP)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.LoginResponse), args.Error(1)
+}
+
+func (m *MockUserService) SetupTwoFactor(setup request.TwoFactorChallengeRequest) (*response.TwoFactorEnrollResponse, error) {
+	args := m.Called(setup)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.TwoFactorEnrollResponse), args.Error(1)
+}
+
 func (m *MockUserService) Logout(token string) (*response.LogoutResponse, error) {
 	args := m.Called(token)
 	if args.Get(0) == nil {
@@ -43,6 +61,14 @@ func (m *MockUserService) Logout(token string) (*response.LogoutResponse, error)
 	return args.Get(0).(*response.LogoutResponse), args.Error(1)
 }
 
+func (m *MockUserService) LogoutAll(token string) (*response.LogoutResponse, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.LogoutResponse), args.Error(1)
+}
+
 func (m *MockUserService) Refresh(token string) (*response.RefreshResponse, error) {
 	args := m.Called(token)
 	if args.Get(0) == nil {
@@ -51,8 +77,8 @@ func (m *MockUserService) Refresh(token string) (*response.RefreshResponse, erro
 	return args.Get(0).(*response.RefreshResponse), args.Error(1)
 }
 
-func (m *MockUserService) FindAllUser() ([]*response.UserResponse, error) {
-	args := m.Called()
+func (m *MockUserService) FindAllUser(includeClosed bool) ([]*response.UserResponse, error) {
+	args := m.Called(includeClosed)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -147,11 +173,12 @@ func (suite *UserControllerTestSuite) TestLogin() {
 
 	suite.userService.On("Login", mock.MatchedBy(func(req request.LoginRequest) bool {
 		return req.Username == loginReq.Username && req.Password == loginReq.Password
-	})).Return(loginResp, nil)
+	}), "192.0.2.1").Return(loginResp, nil)
 
 	reqBody, _ := json.Marshal(loginReq)
 	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "192.0.2.1:1234"
 
 	rr := httptest.NewRecorder()
 
@@ -178,11 +205,12 @@ func (suite *UserControllerTestSuite) TestLoginError() {
 
 	suite.userService.On("Login", mock.MatchedBy(func(req request.LoginRequest) bool {
 		return req.Username == loginReq.Username && req.Password == loginReq.Password
-	})).Return(nil, errors.New("invalid credentials"))
+	}), mock.Anything).Return(nil, errors.New("invalid credentials"))
 
 	reqBody, _ := json.Marshal(loginReq)
 	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "192.0.2.1:1234"
 
 	rr := httptest.NewRecorder()
 
@@ -194,6 +222,63 @@ func (suite *UserControllerTestSuite) TestLoginError() {
 	suite.userService.AssertExpectations(suite.T())
 }
 
+func (suite *UserControllerTestSuite) TestLoginInvalidCredentials() {
+	suite.userService.On("Login", mock.Anything, "192.0.2.1").Return(nil, services.ErrInvalidCredentials)
+
+	reqBody, _ := json.Marshal(request.LoginRequest{Username: "testuser", Password: "wrongpassword"})
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(reqBody))
+	req.RemoteAddr = "192.0.2.1:1234"
+	rr := httptest.NewRecorder()
+
+	http.HandlerFunc(suite.controller.Login).ServeHTTP(rr, req)
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, rr.Code)
+}
+
+func (suite *UserControllerTestSuite) TestLoginThrottled() {
+	suite.userService.On("Login", mock.Anything, "192.0.2.1").Return(nil, &services.LoginThrottledError{RetryAfter: 1500 * time.Millisecond})
+
+	reqBody, _ := json.Marshal(request.LoginRequest{Username: "testuser", Password: "wrongpassword"})
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(reqBody))
+	req.RemoteAddr = "192.0.2.1:1234"
+	rr := httptest.NewRecorder()
+
+	http.HandlerFunc(suite.controller.Login).ServeHTTP(rr, req)
+
+	assert.Equal(suite.T(), http.StatusTooManyRequests, rr.Code)
+	assert.Equal(suite.T(), "2", rr.Header().Get("Retry-After"))
+}
+
+func (suite *UserControllerTestSuite) TestLoginChallenge() {
+	suite.userService.On("Login", mock.Anything, "192.0.2.1").Return(&response.LoginResponse{ChallengeToken: "challenge", ChallengePurpose: "2fa_verify"}, nil)
+
+	reqBody, _ := json.Marshal(request.LoginRequest{Username: "merchant", Password: "password123"})
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(reqBody))
+	req.RemoteAddr = "192.0.2.1:1234"
+	rr := httptest.NewRecorder()
+
+	http.HandlerFunc(suite.controller.Login).ServeHTTP(rr, req)
+
+	var apiResp response.ApiResponse
+	assert.NoError(suite.T(), json.Unmarshal(rr.Body.Bytes(), &apiResp))
+	assert.Equal(suite.T(), http.StatusOK, rr.Code)
+	assert.Equal(suite.T(), "Two-factor authentication required", apiResp.Message)
+}
+
+func (suite *UserControllerTestSuite) TestVerifyLoginWrongCode() {
+	verifyReq := request.VerifyLoginRequest{ChallengeToken: "challenge", Code: "000000"}
+	suite.userService.On("VerifyLogin", verifyReq, "192.0.2.1").Return(nil, services.ErrInvalidTwoFactorCode)
+
+	reqBody, _ := json.Marshal(verifyReq)
+	req, _ := http.NewRequest("POST", "/login/verify", bytes.NewBuffer(reqBody))
+	req.RemoteAddr = "192.0.2.1:1234"
+	rr := httptest.NewRecorder()
+
+	http.HandlerFunc(suite.controller.VerifyLogin).ServeHTTP(rr, req)
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, rr.Code)
+}
+
 func (suite *UserControllerTestSuite) TestLogout() {
 	token := "test-token"
 	logoutResp := &response.LogoutResponse{
@@ -0,0 +1,123 @@
+package middlewares_test
+
+import (
+	"fmt"
+	"go-json/internal/middlewares"
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	"go-json/internal/security"
+	constant_test "go-json/tests/constant"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type IdempotentHandlerTestSuite struct {
+	suite.Suite
+	store   repositories.IdempotencyRepository
+	status  int
+	calls   int
+	handler http.Handler
+}
+
+func (suite *IdempotentHandlerTestSuite) SetupTest() {
+	err := os.MkdirAll(filepath.Dir(constant_test.IDEMPOTENCY_FILE), 0755)
+	assert.NoError(suite.T(), err)
+
+	suite.store = repositories.NewIdempotencyRepository([]models.IdempotencyRecord{})
+	suite.status = http.StatusCreated
+	suite.calls = 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(suite.status)
+		fmt.Fprintf(w, `{"call":%d}`, suite.calls)
+	})
+	suite.handler = middlewares.IdempotentHandler(next, suite.store, time.Hour)
+}
+
+func (suite *IdempotentHandlerTestSuite) TearDownTest() {
+	os.Remove(constant_test.IDEMPOTENCY_FILE)
+	os.Remove(constant_test.IDEMPOTENCY_FILE + ".bak")
+	os.Remove(filepath.Dir(constant_test.IDEMPOTENCY_FILE))
+}
+
+func (suite *IdempotentHandlerTestSuite) serve(handler http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/trx/payment", strings.NewReader(body))
+	req.Header.Set("Idempotency-Key", key)
+	req = req.WithContext(security.WithPrincipal(req.Context(), security.Principal{UserID: "7"}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func (suite *IdempotentHandlerTestSuite) TestReplayReturnsSameResponse() {
+	first := suite.serve(suite.handler, "key-1", `{"amount":"100"}`)
+	second := suite.serve(suite.handler, "key-1", `{"amount":"100"}`)
+
+	assert.Equal(suite.T(), 1, suite.calls)
+	assert.Equal(suite.T(), http.StatusCreated, second.Code)
+	assert.Equal(suite.T(), first.Body.Bytes(), second.Body.Bytes())
+	assert.Equal(suite.T(), "application/json", second.Header().Get("Content-Type"))
+	assert.Equal(suite.T(), "true", second.Header().Get("Idempotent-Replayed"))
+	assert.Empty(suite.T(), first.Header().Get("Idempotent-Replayed"))
+}
+
+func (suite *IdempotentHandlerTestSuite) TestDifferentBodyIsRejected() {
+	suite.serve(suite.handler, "key-1", `{"amount":"100"}`)
+	rr := suite.serve(suite.handler, "key-1", `{"amount":"200"}`)
+
+	assert.Equal(suite.T(), http.StatusUnprocessableEntity, rr.Code)
+	assert.Equal(suite.T(), 1, suite.calls)
+}
+
+func (suite *IdempotentHandlerTestSuite) TestKeyInFlightIsConflict() {
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	blocking := middlewares.IdempotentHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-finish
+		w.WriteHeader(http.StatusCreated)
+	}), suite.store, time.Hour)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- suite.serve(blocking, "key-1", `{"amount":"100"}`)
+	}()
+	<-started
+	rr := suite.serve(blocking, "key-1", `{"amount":"100"}`)
+	close(finish)
+
+	assert.Equal(suite.T(), http.StatusConflict, rr.Code)
+	assert.Equal(suite.T(), http.StatusCreated, (<-done).Code)
+}
+
+func (suite *IdempotentHandlerTestSuite) TestServerErrorIsNotStored() {
+	suite.status = http.StatusInternalServerError
+	first := suite.serve(suite.handler, "key-1", `{"amount":"100"}`)
+	suite.status = http.StatusCreated
+	second := suite.serve(suite.handler, "key-1", `{"amount":"100"}`)
+
+	assert.Equal(suite.T(), http.StatusInternalServerError, first.Code)
+	assert.Equal(suite.T(), http.StatusCreated, second.Code)
+	assert.Equal(suite.T(), 2, suite.calls)
+	assert.Empty(suite.T(), second.Header().Get("Idempotent-Replayed"))
+}
+
+func (suite *IdempotentHandlerTestSuite) TestBodyOverLimitIsRejected() {
+	rr := suite.serve(suite.handler, "key-1", strings.Repeat("a", 1<<20+1))
+
+	assert.Equal(suite.T(), http.StatusRequestEntityTooLarge, rr.Code)
+	assert.Equal(suite.T(), 0, suite.calls)
+}
+
+func TestIdempotentHandlerSuite(t *testing.T) {
+	suite.Run(t, new(IdempotentHandlerTestSuite))
+}
@@ -0,0 +1,172 @@
+package middlewares_test
+
+import (
+	"errors"
+	"go-json/internal/middlewares"
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	"go-json/internal/security"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type noRevocations struct{}
+
+func (noRevocations) IsRevoked(jti, userID, sessionID string, issuedAt time.Time) bool {
+	return false
+}
+
+type stubAPIKeys map[string]security.Principal
+
+func (k stubAPIKeys) AuthenticateAPIKey(key string) (*security.Principal, error) {
+	principal, ok := k[key]
+	if !ok {
+		return nil, errors.New("invalid or revoked API key")
+	}
+	return &principal, nil
+}
+
+type ProtectedHandlerTestSuite struct {
+	suite.Suite
+	token     security.TokenService
+	handler   http.Handler
+	principal security.Principal
+	called    bool
+}
+
+func (suite *ProtectedHandlerTestSuite) SetupTest() {
+	suite.token = security.NewTokenService([]byte("test-secret"), security.TokenConfig{AccessTTL: time.Minute, RefreshTTL: time.Hour, ChallengeTTL: time.Minute}, noRevocations{})
+	suite.called = false
+	suite.principal = security.Principal{}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		suite.called = true
+		suite.principal, _ = security.PrincipalFromContext(r.Context())
+	})
+	roles := repositories.NewRoleRepository([]models.Role{
+		{ID: "1", Name: "merchant", Permissions: []string{models.PermissionRefundCreate, models.PermissionUserList}},
+		{ID: "2", Name: "customer", Permissions: []string{models.PermissionPaymentCreate}},
+	}, nil)
+	apiKeys := stubAPIKeys{
+		"mbk_000000000001_list":   {UserID: "7", Permissions: []string{models.PermissionUserList}, APIKeyID: "1"},
+		"mbk_000000000002_refund": {UserID: "7", Permissions: []string{models.PermissionRefundCreate}, APIKeyID: "2"},
+	}
+	protected := middlewares.ProtectedHandler(next, suite.token, roles, models.PermissionUserList)
+	suite.handler = middlewares.APIKeyAuth(apiKeys)(protected)
+}
+
+func (suite *ProtectedHandlerTestSuite) serve(authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/protected", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	req.Header.Set("email", "spoofed@example.com")
+	rr := httptest.NewRecorder()
+	suite.handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func (suite *ProtectedHandlerTestSuite) tokenFor(roles ...string) string {
+	token, err := suite.token.GenerateToken(security.TokenSubject{UserID: "7", Email: "merchant@example.com", Roles: roles})
+	suite.Require().NoError(err)
+	return token
+}
+
+func (suite *ProtectedHandlerTestSuite) TestValidTokenSetsPrincipal() {
+	rr := suite.serve("Bearer " + suite.tokenFor("merchant"))
+
+	assert.Equal(suite.T(), http.StatusOK, rr.Code)
+	assert.True(suite.T(), suite.called)
+	assert.Equal(suite.T(), "7", suite.principal.UserID)
+	assert.Equal(suite.T(), "merchant@example.com", suite.principal.Email)
+	assert.ElementsMatch(suite.T(), []string{models.PermissionRefundCreate, models.PermissionUserList}, suite.principal.Permissions)
+}
+
+func (suite *ProtectedHandlerTestSuite) TestChallengeTokenIsNotAnAccessToken() {
+	challenge, err := suite.token.GenerateChallengeToken("7", security.ChallengeTwoFactorVerify)
+	suite.Require().NoError(err)
+
+	rr := suite.serve("Bearer " + challenge)
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, rr.Code)
+	assert.False(suite.T(), suite.called)
+}
+
+func (suite *ProtectedHandlerTestSuite) TestSchemeIsCaseInsensitive() {
+	rr := suite.serve("bearer " + suite.tokenFor("merchant"))
+
+	assert.Equal(suite.T(), http.StatusOK, rr.Code)
+}
+
+func (suite *ProtectedHandlerTestSuite) TestMissingHeader() {
+	rr := suite.serve("")
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, rr.Code)
+	assert.Equal(suite.T(), `Bearer realm="merchant-bank"`, rr.Header().Get("WWW-Authenticate"))
+	assert.False(suite.T(), suite.called)
+}
+
+func (suite *ProtectedHandlerTestSuite) TestMalformedHeader() {
+	for _, header := range []string{"Bear", "Bearer", "Bearer ", "Basic dXNlcjpwYXNz", "Bearer a b"} {
+		rr := suite.serve(header)
+
+		assert.Equal(suite.T(), http.StatusBadRequest, rr.Code, header)
+		assert.Contains(suite.T(), rr.Header().Get("WWW-Authenticate"), `error="invalid_request"`, header)
+	}
+	assert.False(suite.T(), suite.called)
+}
+
+func (suite *ProtectedHandlerTestSuite) TestInvalidToken() {
+	rr := suite.serve("Bearer not-a-jwt")
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, rr.Code)
+	assert.Contains(suite.T(), rr.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+	assert.False(suite.T(), suite.called)
+}
+
+func (suite *ProtectedHandlerTestSuite) TestUnknownRoleHasNoPermissions() {
+	rr := suite.serve("Bearer " + suite.tokenFor("ghost"))
+
+	assert.Equal(suite.T(), http.StatusForbidden, rr.Code)
+	assert.False(suite.T(), suite.called)
+}
+
+func (suite *ProtectedHandlerTestSuite) TestMissingPermissionIsForbidden() {
+	rr := suite.serve("Bearer " + suite.tokenFor("customer"))
+
+	assert.Equal(suite.T(), http.StatusForbidden, rr.Code)
+	assert.Contains(suite.T(), rr.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`)
+	assert.False(suite.T(), suite.called)
+}
+
+func (suite *ProtectedHandlerTestSuite) TestAPIKeySetsPrincipal() {
+	rr := suite.serve("Bearer mbk_000000000001_list")
+
+	assert.Equal(suite.T(), http.StatusOK, rr.Code)
+	assert.Equal(suite.T(), "7", suite.principal.UserID)
+	assert.Equal(suite.T(), "1", suite.principal.APIKeyID)
+	assert.Empty(suite.T(), suite.principal.SessionID)
+}
+
+func (suite *ProtectedHandlerTestSuite) TestAPIKeyScopeIsEnforced() {
+	rr := suite.serve("Bearer mbk_000000000002_refund")
+
+	assert.Equal(suite.T(), http.StatusForbidden, rr.Code)
+	assert.False(suite.T(), suite.called)
+}
+
+func (suite *ProtectedHandlerTestSuite) TestInvalidAPIKey() {
+	rr := suite.serve("Bearer mbk_000000000003_unknown")
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, rr.Code)
+	assert.Contains(suite.T(), rr.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+	assert.False(suite.T(), suite.called)
+}
+
+func TestProtectedHandlerSuite(t *testing.T) {
+	suite.Run(t, new(ProtectedHandlerTestSuite))
+}
@@ -0,0 +1,102 @@
+package models_test
+
+import (
+	"encoding/json"
+	"errors"
+	"go-json/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type MoneyTestSuite struct {
+	suite.Suite
+}
+
+func (suite *MoneyTestSuite) TestParseMoney() {
+	money, err := models.ParseMoney("10.5", "IDR")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1050), money.Units())
+	assert.Equal(suite.T(), "10.50 IDR", money.String())
+
+	money, err = models.ParseMoney("1e6", "JPY")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1000000), money.Units())
+
+	_, err = models.ParseMoney("10.505", "IDR")
+	assert.True(suite.T(), errors.Is(err, models.ErrAmountTooPrecise))
+
+	_, err = models.ParseMoney("1.5", "JPY")
+	assert.True(suite.T(), errors.Is(err, models.ErrAmountTooPrecise))
+
+	_, err = models.ParseMoney("1/2", "IDR")
+	assert.True(suite.T(), errors.Is(err, models.ErrInvalidAmount))
+
+	_, err = models.ParseMoney("10", "XYZ")
+	assert.True(suite.T(), errors.Is(err, models.ErrUnsupportedCurrency))
+}
+
+func (suite *MoneyTestSuite) TestRepeatedAdditionDoesNotDrift() {
+	total := models.NewMoney(0, "IDR")
+	step := models.MustParseMoney("0.10", "IDR")
+	for i := 0; i < 1000; i++ {
+		var err error
+		total, err = total.Add(step)
+		assert.NoError(suite.T(), err)
+	}
+	assert.Equal(suite.T(), "100.00", total.Decimal())
+}
+
+func (suite *MoneyTestSuite) TestCurrencyMismatch() {
+	_, err := models.MustParseMoney("1", "IDR").Add(models.MustParseMoney("1", "USD"))
+	assert.True(suite.T(), errors.Is(err, models.ErrCurrencyMismatch))
+
+	_, err = models.MustParseMoney("1", "IDR").Cmp(models.MustParseMoney("1", "USD"))
+	assert.True(suite.T(), errors.Is(err, models.ErrCurrencyMismatch))
+}
+
+func (suite *MoneyTestSuite) TestUnmarshalLegacyNumber() {
+	var user models.User
+	err := json.Unmarshal([]byte(`{"id":"1","balance":1000000}`), &user)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.MustParseMoney("1000000", models.DefaultCurrency), user.Balance)
+
+	err = json.Unmarshal([]byte(`{"id":"1","balance":997999.9999999}`), &user)
+	assert.True(suite.T(), errors.Is(err, models.ErrAmountTooPrecise))
+}
+
+func (suite *MoneyTestSuite) TestJSONRoundTrip() {
+	money := models.MustParseMoney("-0.05", "USD")
+	data, err := json.Marshal(money)
+	assert.NoError(suite.T(), err)
+	assert.JSONEq(suite.T(), `{"amount":"-0.05","currency":"USD"}`, string(data))
+
+	var decoded models.Money
+	err = json.Unmarshal(data, &decoded)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), money, decoded)
+
+	err = json.Unmarshal([]byte(`{"amount":12.345,"currency":"usd"}`), &decoded)
+	assert.True(suite.T(), errors.Is(err, models.ErrAmountTooPrecise))
+}
+
+func (suite *MoneyTestSuite) TestExchangeRateConvert() {
+	rate := models.ExchangeRate{From: "JPY", To: "IDR", Rate: "108.5"}
+
+	idr, err := rate.Convert(models.MustParseMoney("3", "JPY"))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "325.50 IDR", idr.String())
+
+	// 100 / 108.5 = 0.92 JPY, dibulatkan ke 1 karena JPY tidak punya desimal
+	jpy, err := rate.Convert(models.MustParseMoney("100", "IDR"))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1 JPY", jpy.String())
+
+	_, err = rate.Convert(models.MustParseMoney("1", "USD"))
+	assert.True(suite.T(), errors.Is(err, models.ErrCurrencyMismatch))
+}
+
+func TestMoneySuite(t *testing.T) {
+	suite.Run(t, new(MoneyTestSuite))
+}
@@ -0,0 +1,116 @@
+package repositories_test
+
+import (
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	constant_test "go-json/tests/constant"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type IdempotencyRepositoryTestSuite struct {
+	suite.Suite
+	repo repositories.IdempotencyRepository
+}
+
+func (suite *IdempotencyRepositoryTestSuite) SetupTest() {
+	err := os.MkdirAll(filepath.Dir(constant_test.IDEMPOTENCY_FILE), 0755)
+	assert.NoError(suite.T(), err)
+
+	suite.repo = repositories.NewIdempotencyRepository([]models.IdempotencyRecord{
+		{
+			Key:         "expired-key",
+			Scope:       "customer@example.com",
+			Fingerprint: "old",
+			StatusCode:  200,
+			CreatedAt:   time.Now().Add(-48 * time.Hour),
+			ExpiresAt:   time.Now().Add(-24 * time.Hour),
+		},
+	})
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TearDownTest() {
+	os.Remove(constant_test.IDEMPOTENCY_FILE)
+	os.Remove(constant_test.IDEMPOTENCY_FILE + ".bak")
+	os.Remove(filepath.Dir(constant_test.IDEMPOTENCY_FILE))
+}
+
+func newIdempotencyRecord(key, fingerprint string) models.IdempotencyRecord {
+	now := time.Now()
+	return models.IdempotencyRecord{
+		Key:         key,
+		Scope:       "customer@example.com",
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Hour),
+	}
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestReserveAndReplay() {
+	record := newIdempotencyRecord("key-1", "abc")
+
+	existing, reserved := suite.repo.Reserve(record)
+	assert.True(suite.T(), reserved)
+	assert.Nil(suite.T(), existing)
+
+	// Request kedua saat yang pertama belum selesai
+	existing, reserved = suite.repo.Reserve(record)
+	assert.False(suite.T(), reserved)
+	assert.Equal(suite.T(), 0, existing.StatusCode)
+
+	record.StatusCode = 200
+	record.ContentType = "application/json"
+	record.Body = `{"status":200}`
+	err := suite.repo.Complete(record)
+	assert.NoError(suite.T(), err)
+
+	existing, reserved = suite.repo.Reserve(record)
+	assert.False(suite.T(), reserved)
+	assert.Equal(suite.T(), 200, existing.StatusCode)
+	assert.Equal(suite.T(), `{"status":200}`, existing.Body)
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestKeysAreScopedPerUser() {
+	record := newIdempotencyRecord("key-1", "abc")
+	_, reserved := suite.repo.Reserve(record)
+	assert.True(suite.T(), reserved)
+
+	other := record
+	other.Scope = "other@example.com"
+	_, reserved = suite.repo.Reserve(other)
+	assert.True(suite.T(), reserved)
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestReleaseAllowsRetry() {
+	record := newIdempotencyRecord("key-1", "abc")
+	_, reserved := suite.repo.Reserve(record)
+	assert.True(suite.T(), reserved)
+
+	suite.repo.Release(record.Scope, record.Key)
+
+	_, reserved = suite.repo.Reserve(record)
+	assert.True(suite.T(), reserved)
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestExpiredKeyCanBeReused() {
+	_, reserved := suite.repo.Reserve(newIdempotencyRecord("expired-key", "new"))
+	assert.True(suite.T(), reserved)
+}
+
+func (suite *IdempotencyRepositoryTestSuite) TestPruneExpired() {
+	err := suite.repo.PruneExpired()
+	assert.NoError(suite.T(), err)
+
+	data, err := os.ReadFile(constant_test.IDEMPOTENCY_FILE)
+	assert.NoError(suite.T(), err)
+	assert.JSONEq(suite.T(), "[]", string(data))
+}
+
+func TestIdempotencyRepositorySuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyRepositoryTestSuite))
+}
@@ -0,0 +1,77 @@
+package repositories_test
+
+import (
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	constant_test "go-json/tests/constant"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type OneTimeTokenRepositoryTestSuite struct {
+	suite.Suite
+	repo repositories.OneTimeTokenRepository
+}
+
+func (suite *OneTimeTokenRepositoryTestSuite) SetupTest() {
+	err := os.MkdirAll(filepath.Dir(constant_test.ONE_TIME_TOKEN_FILE), 0755)
+	assert.NoError(suite.T(), err)
+
+	now := time.Now()
+	suite.repo = repositories.NewOneTimeTokenRepository([]models.OneTimeToken{
+		{UserID: "1", Purpose: models.PasswordResetToken, TokenHash: "valid", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{UserID: "1", Purpose: models.PasswordResetToken, TokenHash: "expired", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+		{UserID: "2", Purpose: models.PasswordResetToken, TokenHash: "other", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+	})
+}
+
+func (suite *OneTimeTokenRepositoryTestSuite) TearDownTest() {
+	os.Remove(constant_test.ONE_TIME_TOKEN_FILE)
+	os.Remove(filepath.Dir(constant_test.ONE_TIME_TOKEN_FILE))
+}
+
+func (suite *OneTimeTokenRepositoryTestSuite) TestConsumeOnlyOnce() {
+	token, err := suite.repo.Consume(models.PasswordResetToken, "valid")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", token.UserID)
+	assert.NotNil(suite.T(), token.UsedAt)
+
+	_, err = suite.repo.Consume(models.PasswordResetToken, "valid")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *OneTimeTokenRepositoryTestSuite) TestConsumeRejectsExpiredAndUnknown() {
+	_, err := suite.repo.Consume(models.PasswordResetToken, "expired")
+	assert.Error(suite.T(), err)
+
+	_, err = suite.repo.Consume(models.PasswordResetToken, "missing")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *OneTimeTokenRepositoryTestSuite) TestDeleteByUserID() {
+	err := suite.repo.DeleteByUserID("1", models.PasswordResetToken)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.repo.Consume(models.PasswordResetToken, "valid")
+	assert.Error(suite.T(), err)
+	_, err = suite.repo.Consume(models.PasswordResetToken, "other")
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *OneTimeTokenRepositoryTestSuite) TestFindLatestByUserID() {
+	latest, err := suite.repo.FindLatestByUserID("1", models.PasswordResetToken)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "valid", latest.TokenHash)
+
+	_, err = suite.repo.FindLatestByUserID("1", models.EmailVerificationToken)
+	assert.Error(suite.T(), err)
+}
+
+func TestOneTimeTokenRepositorySuite(t *testing.T) {
+	suite.Run(t, new(OneTimeTokenRepositoryTestSuite))
+}
@@ -0,0 +1,141 @@
+package repositories_test
+
+import (
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	constant_test "go-json/tests/constant"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RefreshTokenRepositoryTestSuite struct {
+	suite.Suite
+	repo repositories.RefreshTokenRepository
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) SetupTest() {
+	err := os.MkdirAll(filepath.Dir(constant_test.REFRESH_TOKEN_FILE), 0755)
+	assert.NoError(suite.T(), err)
+
+	suite.repo = repositories.NewRefreshTokenRepository([]models.RefreshToken{})
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TearDownTest() {
+	os.Remove(constant_test.REFRESH_TOKEN_FILE)
+	os.Remove(filepath.Dir(constant_test.REFRESH_TOKEN_FILE))
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestCreateAndFindRefreshToken() {
+	token, err := suite.repo.CreateRefreshToken(models.RefreshToken{
+		UserID:    "1",
+		TokenHash: "hash-1",
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", token.ID)
+	assert.Equal(suite.T(), "1", token.FamilyID)
+
+	found, err := suite.repo.FindByTokenHash("hash-1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", found.UserID)
+
+	_, err = suite.repo.FindByTokenHash("missing")
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), "refresh token not found", err.Error())
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestRevokeFamily() {
+	first, err := suite.repo.CreateRefreshToken(models.RefreshToken{UserID: "1", TokenHash: "hash-1"})
+	assert.NoError(suite.T(), err)
+	_, err = suite.repo.CreateRefreshToken(models.RefreshToken{UserID: "1", TokenHash: "hash-2", FamilyID: first.FamilyID})
+	assert.NoError(suite.T(), err)
+	_, err = suite.repo.CreateRefreshToken(models.RefreshToken{UserID: "2", TokenHash: "hash-3"})
+	assert.NoError(suite.T(), err)
+
+	err = suite.repo.RevokeFamily(first.FamilyID)
+	assert.NoError(suite.T(), err)
+
+	second, _ := suite.repo.FindByTokenHash("hash-2")
+	assert.NotNil(suite.T(), second.RevokedAt)
+	other, _ := suite.repo.FindByTokenHash("hash-3")
+	assert.Nil(suite.T(), other.RevokedAt)
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestRevokeOtherFamilies() {
+	current, err := suite.repo.CreateRefreshToken(models.RefreshToken{UserID: "1", TokenHash: "hash-1"})
+	assert.NoError(suite.T(), err)
+	_, err = suite.repo.CreateRefreshToken(models.RefreshToken{UserID: "1", TokenHash: "hash-2"})
+	assert.NoError(suite.T(), err)
+	_, err = suite.repo.CreateRefreshToken(models.RefreshToken{UserID: "2", TokenHash: "hash-3"})
+	assert.NoError(suite.T(), err)
+
+	err = suite.repo.RevokeOtherFamilies("1", current.FamilyID)
+	assert.NoError(suite.T(), err)
+
+	kept, _ := suite.repo.FindByTokenHash("hash-1")
+	assert.Nil(suite.T(), kept.RevokedAt)
+	revoked, _ := suite.repo.FindByTokenHash("hash-2")
+	assert.NotNil(suite.T(), revoked.RevokedAt)
+	other, _ := suite.repo.FindByTokenHash("hash-3")
+	assert.Nil(suite.T(), other.RevokedAt)
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestConsumeOnlyOnce() {
+	first, err := suite.repo.CreateRefreshToken(models.RefreshToken{UserID: "1", TokenHash: "hash-1", ExpiresAt: time.Now().Add(time.Hour)})
+	assert.NoError(suite.T(), err)
+	_, err = suite.repo.CreateRefreshToken(models.RefreshToken{UserID: "1", TokenHash: "hash-2", FamilyID: first.FamilyID, ExpiresAt: time.Now().Add(time.Hour)})
+	assert.NoError(suite.T(), err)
+
+	consumed, err := suite.repo.Consume("hash-1")
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), consumed.UsedAt)
+
+	// Pemakaian ulang mencabut seluruh family, termasuk token hasil rotasi
+	reused, err := suite.repo.Consume("hash-1")
+	assert.ErrorIs(suite.T(), err, models.ErrRefreshTokenReused)
+	assert.Equal(suite.T(), first.FamilyID, reused.FamilyID)
+	_, err = suite.repo.Consume("hash-2")
+	assert.ErrorIs(suite.T(), err, models.ErrRefreshTokenRevoked)
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestConsumeConcurrentRequestsOnlyOneWins() {
+	_, err := suite.repo.CreateRefreshToken(models.RefreshToken{UserID: "1", TokenHash: "hash-1", ExpiresAt: time.Now().Add(time.Hour)})
+	assert.NoError(suite.T(), err)
+
+	var wg sync.WaitGroup
+	var succeeded atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := suite.repo.Consume("hash-1"); err == nil {
+				succeeded.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(suite.T(), int32(1), succeeded.Load())
+}
+
+func (suite *RefreshTokenRepositoryTestSuite) TestConsumeRejectsExpiredAndUnknown() {
+	_, err := suite.repo.CreateRefreshToken(models.RefreshToken{UserID: "1", TokenHash: "hash-1", ExpiresAt: time.Now().Add(-time.Minute)})
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.repo.Consume("hash-1")
+	assert.ErrorIs(suite.T(), err, models.ErrRefreshTokenExpired)
+	_, err = suite.repo.Consume("missing")
+	assert.ErrorIs(suite.T(), err, models.ErrRefreshTokenNotFound)
+}
+
+func TestRefreshTokenRepositorySuite(t *testing.T) {
+	suite.Run(t, new(RefreshTokenRepositoryTestSuite))
+}
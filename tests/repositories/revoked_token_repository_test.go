@@ -0,0 +1,116 @@
+package repositories_test
+
+import (
+	"go-json/internal/models"
+	"go-json/internal/repositories"
+	constant_test "go-json/tests/constant"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RevokedTokenRepositoryTestSuite struct {
+	suite.Suite
+	repo repositories.RevokedTokenRepository
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) SetupTest() {
+	err := os.MkdirAll(filepath.Dir(constant_test.REVOKED_TOKEN_FILE), 0755)
+	assert.NoError(suite.T(), err)
+
+	suite.repo = repositories.NewRevokedTokenRepository([]models.RevokedToken{
+		{
+			JTI:       "expired-jti",
+			UserID:    "1",
+			RevokedAt: time.Now().Add(-2 * time.Hour),
+			ExpiresAt: time.Now().Add(-time.Hour),
+		},
+	})
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) TearDownTest() {
+	os.Remove(constant_test.REVOKED_TOKEN_FILE)
+	os.Remove(filepath.Dir(constant_test.REVOKED_TOKEN_FILE))
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) TestRevokeByJTI() {
+	now := time.Now()
+	err := suite.repo.Revoke(models.RevokedToken{
+		JTI:       "jti-1",
+		UserID:    "1",
+		RevokedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	})
+	assert.NoError(suite.T(), err)
+
+	assert.True(suite.T(), suite.repo.IsRevoked("jti-1", "1", "", now.Add(-time.Minute)))
+	assert.False(suite.T(), suite.repo.IsRevoked("jti-2", "1", "", now.Add(-time.Minute)))
+	assert.False(suite.T(), suite.repo.IsRevoked("expired-jti", "1", "", now.Add(-3*time.Hour)))
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) TestRevokeAllSessions() {
+	now := time.Now()
+	err := suite.repo.Revoke(models.RevokedToken{
+		UserID:    "1",
+		RevokedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	})
+	assert.NoError(suite.T(), err)
+
+	assert.True(suite.T(), suite.repo.IsRevoked("any-jti", "1", "", now.Add(-time.Minute)))
+	assert.False(suite.T(), suite.repo.IsRevoked("any-jti", "1", "", now.Add(time.Minute)))
+	assert.False(suite.T(), suite.repo.IsRevoked("any-jti", "2", "", now.Add(-time.Minute)))
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) TestTokenIssuedInSameSecondAsRevocationIsValid() {
+	now := time.Now()
+	err := suite.repo.Revoke(models.RevokedToken{
+		UserID:    "1",
+		RevokedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	})
+	assert.NoError(suite.T(), err)
+
+	// iat dari JWT dibulatkan ke bawah ke detik
+	issuedAt := now.Truncate(time.Second)
+	assert.False(suite.T(), suite.repo.IsRevoked("any-jti", "1", "", issuedAt))
+	assert.True(suite.T(), suite.repo.IsRevoked("any-jti", "1", "", issuedAt.Add(-time.Second)))
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) TestRevokeSession() {
+	now := time.Now()
+	err := suite.repo.Revoke(models.RevokedToken{
+		UserID:    "1",
+		SessionID: "10",
+		RevokedAt: now,
+		ExpiresAt: now.Add(time.Hour),
+	})
+	assert.NoError(suite.T(), err)
+
+	assert.True(suite.T(), suite.repo.IsRevoked("any-jti", "1", "10", now.Add(-time.Minute)))
+	assert.True(suite.T(), suite.repo.IsRevoked("any-jti", "1", "10", now.Add(time.Minute)))
+	assert.False(suite.T(), suite.repo.IsRevoked("any-jti", "1", "11", now.Add(-time.Minute)))
+	assert.False(suite.T(), suite.repo.IsRevoked("any-jti", "1", "", now.Add(-time.Minute)))
+}
+
+func (suite *RevokedTokenRepositoryTestSuite) TestRevokeOtherSessions() {
+	now := time.Now()
+	err := suite.repo.Revoke(models.RevokedToken{
+		UserID:          "1",
+		ExceptSessionID: "10",
+		RevokedAt:       now,
+		ExpiresAt:       now.Add(time.Hour),
+	})
+	assert.NoError(suite.T(), err)
+
+	assert.True(suite.T(), suite.repo.IsRevoked("any-jti", "1", "11", now.Add(-time.Minute)))
+	assert.False(suite.T(), suite.repo.IsRevoked("any-jti", "1", "10", now.Add(-time.Minute)))
+}
+
+func TestRevokedTokenRepositorySuite(t *testing.T) {
+	suite.Run(t, new(RevokedTokenRepositoryTestSuite))
+}
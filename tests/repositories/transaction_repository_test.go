@@ -13,6 +13,10 @@ import (
 	"github.com/stretchr/testify/suite"
 )
 
+func idr(amount string) models.Money {
+	return models.MustParseMoney(amount, models.DefaultCurrency)
+}
+
 type TransactionRepositoryTestSuite struct {
 	suite.Suite
 	repo         repositories.TransactionRepository
@@ -28,7 +32,7 @@ func (suite *TransactionRepositoryTestSuite) SetupTest() {
 			ActivityType: models.PaymentActivity,
 			Timestamp:    time.Now(),
 			Details:      "Test transaction",
-			Amount:       100.0,
+			Amount:       idr("100"),
 			MerchantID:   "2",
 		},
 	}
@@ -38,7 +42,7 @@ func (suite *TransactionRepositoryTestSuite) SetupTest() {
 		ActivityType: models.PaymentActivity,
 		Timestamp:    time.Now(),
 		Details:      "New test transaction",
-		Amount:       200.0,
+		Amount:       idr("200"),
 		MerchantID:   "3",
 	}
 
@@ -75,7 +79,7 @@ func (suite *TransactionRepositoryTestSuite) TestFindAllTransaction() {
 	assert.Equal(suite.T(), "1", transactions[0].ID)
 	assert.Equal(suite.T(), "1", transactions[0].CustomerID)
 	assert.Equal(suite.T(), models.PaymentActivity, transactions[0].ActivityType)
-	assert.Equal(suite.T(), 100.0, transactions[0].Amount)
+	assert.Equal(suite.T(), idr("100"), transactions[0].Amount)
 	assert.Equal(suite.T(), "2", transactions[0].MerchantID)
 
 	_, err = suite.repo.CreateTransaction(suite.testTrx)
@@ -87,6 +91,44 @@ func (suite *TransactionRepositoryTestSuite) TestFindAllTransaction() {
 	assert.Len(suite.T(), transactions, 2)
 }
 
+func (suite *TransactionRepositoryTestSuite) TestFindAllTransactionReturnsCopy() {
+	transactions, err := suite.repo.FindAllTransaction()
+	assert.NoError(suite.T(), err)
+	transactions[0].Details = "Changed by caller"
+
+	err = suite.repo.UpdateTransaction(models.Transaction{ID: "1", CustomerID: "1", Details: "Updated"})
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), "Changed by caller", transactions[0].Details)
+	stored, err := suite.repo.FindByID("1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Updated", stored.Details)
+}
+
+func (suite *TransactionRepositoryTestSuite) TestFindByID() {
+	transaction, err := suite.repo.FindByID("1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), idr("100"), transaction.Amount)
+
+	_, err = suite.repo.FindByID("999")
+	assert.Error(suite.T(), err)
+}
+
+func (suite *TransactionRepositoryTestSuite) TestUpdateTransaction() {
+	transaction := suite.transactions[0]
+	transaction.Status = models.TransactionRefunded
+	err := suite.repo.UpdateTransaction(transaction)
+	assert.NoError(suite.T(), err)
+
+	updated, err := suite.repo.FindByID("1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.TransactionRefunded, updated.Status)
+
+	transaction.ID = "999"
+	err = suite.repo.UpdateTransaction(transaction)
+	assert.Error(suite.T(), err)
+}
+
 func TestTransactionRepositorySuite(t *testing.T) {
 	suite.Run(t, new(TransactionRepositoryTestSuite))
 }
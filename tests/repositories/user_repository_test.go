@@ -7,6 +7,7 @@ import (
 	"go-json/utils"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -28,8 +29,8 @@ func (suite *UserRepositoryTestSuite) SetupTest() {
 			Username: "testuser",
 			Email:    "test@example.com",
 			Password: "password123",
-			Balance:  1000.0,
-			IsActive: false,
+			Balance:  idr("1000"),
+			Status:   models.AccountActive,
 		},
 	}
 
@@ -69,7 +70,7 @@ func (suite *UserRepositoryTestSuite) SetupTest() {
 	err = utils.WriteJSONFile(constant_test.USER_ROLE_FILE, suite.userRoles)
 	assert.NoError(suite.T(), err)
 
-	suite.repo = repositories.NewUserRepository(suite.users, suite.roles, suite.userRoles)
+	suite.repo = repositories.NewUserRepository(suite.users, repositories.NewRoleRepository(suite.roles, suite.userRoles))
 }
 
 func (suite *UserRepositoryTestSuite) TearDownTest() {
@@ -121,8 +122,8 @@ func (suite *UserRepositoryTestSuite) TestCreateUser() {
 	assert.Equal(suite.T(), "newuser", user.Username)
 	assert.Equal(suite.T(), "new@example.com", user.Email)
 	assert.Equal(suite.T(), "2", user.ID) // Since it's the second user in the array
-	assert.Equal(suite.T(), 1000000.0, user.Balance)
-	assert.False(suite.T(), user.IsActive)
+	assert.True(suite.T(), user.Balance.IsZero())
+	assert.Equal(suite.T(), models.AccountPending, user.Status)
 
 	duplicateUser := suite.testUser
 	duplicateUser.Email = "another@example.com"
@@ -148,14 +149,27 @@ func (suite *UserRepositoryTestSuite) TestCreateUser() {
 	assert.Equal(suite.T(), "invalid role ID", err.Error())
 }
 
+func (suite *UserRepositoryTestSuite) TestCreateUserSharesRoleRepository() {
+	roleRepo := repositories.NewRoleRepository(suite.roles, suite.userRoles)
+	repo := repositories.NewUserRepository(suite.users, roleRepo)
+
+	user, err := repo.CreateUser(suite.testUser, []string{"1", "2"})
+	assert.NoError(suite.T(), err)
+
+	userRoles, err := roleRepo.FindRoleByUserID(user.ID)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), *userRoles, 2)
+	assert.NotEqual(suite.T(), (*userRoles)[0].ID, (*userRoles)[1].ID)
+}
+
 func (suite *UserRepositoryTestSuite) TestUpdateUser() {
 	user, err := suite.repo.FindByID("1")
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), user)
 
 	user.Username = "updateduser"
-	user.Balance = 2000.0
-	user.IsActive = true
+	user.Balance = idr("2000")
+	user.Status = models.AccountPending
 
 	err = suite.repo.UpdateUser(*user)
 	assert.NoError(suite.T(), err)
@@ -164,8 +178,8 @@ func (suite *UserRepositoryTestSuite) TestUpdateUser() {
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), updatedUser)
 	assert.Equal(suite.T(), "updateduser", updatedUser.Username)
-	assert.Equal(suite.T(), 2000.0, updatedUser.Balance)
-	assert.True(suite.T(), updatedUser.IsActive)
+	assert.Equal(suite.T(), idr("2000"), updatedUser.Balance)
+	assert.Equal(suite.T(), models.AccountPending, updatedUser.Status)
 
 	nonExistingUser := models.User{
 		ID:       "999",
@@ -177,6 +191,40 @@ func (suite *UserRepositoryTestSuite) TestUpdateUser() {
 	assert.Equal(suite.T(), "user not found", err.Error())
 }
 
+func (suite *UserRepositoryTestSuite) TestTargetedUpdatesKeepOtherFields() {
+	// Snapshot dibaca sebelum admin membekukan akun dan saldo berubah
+	stale, err := suite.repo.FindByID("1")
+	assert.NoError(suite.T(), err)
+
+	now := time.Now()
+	assert.NoError(suite.T(), suite.repo.UpdateStatus("1", models.AccountSuspended, "SUSPECTED_FRAUD", now))
+	assert.NoError(suite.T(), suite.repo.UpdateBalances("1", idr("2500"), []models.Money{idr("2500")}, nil))
+	assert.NoError(suite.T(), suite.repo.UpdateLastLogin(stale.ID, now))
+	assert.NoError(suite.T(), suite.repo.UpdatePassword(stale.ID, "new-hash"))
+
+	updated, err := suite.repo.FindByID("1")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.AccountSuspended, updated.Status)
+	assert.Equal(suite.T(), idr("2500"), updated.Balance)
+	assert.Equal(suite.T(), "new-hash", updated.Password)
+	assert.NotNil(suite.T(), updated.LastLoginAt)
+
+	assert.EqualError(suite.T(), suite.repo.UpdateLastLogin("999", now), "user not found")
+}
+
+func (suite *UserRepositoryTestSuite) TestMarkEmailVerifiedOnlyActivatesPending() {
+	assert.NoError(suite.T(), suite.repo.UpdateStatus("1", models.AccountPending, "", time.Now()))
+	assert.NoError(suite.T(), suite.repo.MarkEmailVerified("1", time.Now()))
+	verified, _ := suite.repo.FindByID("1")
+	assert.Equal(suite.T(), models.AccountActive, verified.Status)
+	assert.True(suite.T(), verified.IsEmailVerified())
+
+	assert.NoError(suite.T(), suite.repo.UpdateStatus("1", models.AccountSuspended, "SUSPECTED_FRAUD", time.Now()))
+	assert.NoError(suite.T(), suite.repo.MarkEmailVerified("1", time.Now()))
+	suspended, _ := suite.repo.FindByID("1")
+	assert.Equal(suite.T(), models.AccountSuspended, suspended.Status)
+}
+
 func (suite *UserRepositoryTestSuite) TestFindAll() {
 	users, err := suite.repo.FindAll()
 	assert.NoError(suite.T(), err)
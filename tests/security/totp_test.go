@@ -0,0 +1,58 @@
+package security_test
+
+import (
+	"go-json/internal/security"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Secret ASCII "12345678901234567890" dari test vector RFC 6238 (SHA-1), dipotong ke 6 digit
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, expected := range vectors {
+		code, err := security.TOTPCode(rfcSecret, security.TOTPStep(time.Unix(unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, unix)
+	}
+}
+
+func TestVerifyTOTPAllowsSkewAndRejectsReplay(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	previous, err := security.TOTPCode(rfcSecret, security.TOTPStep(now)-1)
+	assert.NoError(t, err)
+
+	step, ok := security.VerifyTOTP(rfcSecret, previous, now, 0)
+	assert.True(t, ok)
+
+	_, ok = security.VerifyTOTP(rfcSecret, previous, now, step)
+	assert.False(t, ok)
+
+	_, ok = security.VerifyTOTP(rfcSecret, "12345", now, 0)
+	assert.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := security.TOTPURI("Merchant-Bank", "alice", rfcSecret)
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Merchant-Bank:alice?"))
+	assert.Contains(t, uri, "secret="+rfcSecret)
+	assert.Contains(t, uri, "issuer=Merchant-Bank")
+}
+
+func TestHashRecoveryCodeIgnoresFormatting(t *testing.T) {
+	code, err := security.GenerateRecoveryCode()
+	assert.NoError(t, err)
+
+	assert.Equal(t, security.HashRecoveryCode(code), security.HashRecoveryCode(strings.ToLower(strings.ReplaceAll(code, "-", ""))))
+	assert.NotEqual(t, code, security.HashRecoveryCode(code))
+}
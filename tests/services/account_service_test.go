@@ -0,0 +1,214 @@
+package services_test
+
+import (
+	"go-json/internal/dtos/request"
+	"go-json/internal/models"
+	"go-json/internal/security"
+	"go-json/internal/services"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AccountServiceTestSuite struct {
+	suite.Suite
+	userRepo        *MockUserRepository
+	refreshRepo     *MockRefreshTokenRepository
+	revokedRepo     *MockRevokedTokenRepository
+	auditRepo       *MockAuditRepository
+	transactionRepo *MockTransactionRepository
+	ledgerSvc       *MockLedgerService
+	tokenSvc        *MockTokenService
+	accountSvc      services.AccountService
+	admin           security.Principal
+	user            models.User
+}
+
+func (suite *AccountServiceTestSuite) SetupTest() {
+	suite.userRepo = new(MockUserRepository)
+	suite.refreshRepo = new(MockRefreshTokenRepository)
+	suite.revokedRepo = new(MockRevokedTokenRepository)
+	suite.auditRepo = new(MockAuditRepository)
+	suite.transactionRepo = new(MockTransactionRepository)
+	suite.ledgerSvc = new(MockLedgerService)
+	suite.tokenSvc = new(MockTokenService)
+	suite.accountSvc = services.NewAccountService(suite.userRepo, suite.refreshRepo, suite.revokedRepo, suite.auditRepo, suite.transactionRepo, suite.ledgerSvc, suite.tokenSvc, models.TransferLimits{PerTransfer: idr("1000"), Daily: idr("5000")})
+	suite.admin = security.Principal{UserID: "9", Roles: []string{"admin"}}
+	suite.user = models.User{ID: "5", Username: "testuser", Balance: idr("1000"), Status: models.AccountActive}
+
+	suite.tokenSvc.On("AccessTokenTTL").Return(15 * time.Minute).Maybe()
+}
+
+func (suite *AccountServiceTestSuite) TestSuspendRevokesSessionsAndAudits() {
+	suite.userRepo.On("FindByID", "5").Return(&suite.user, nil)
+	suite.userRepo.On("UpdateStatus", "5", models.AccountSuspended, "SUSPECTED_FRAUD", mock.Anything).Return(nil)
+	suite.revokedRepo.On("Revoke", mock.MatchedBy(func(t models.RevokedToken) bool {
+		return t.UserID == "5" && t.JTI == ""
+	})).Return(nil)
+	suite.refreshRepo.On("RevokeByUserID", "5").Return(nil)
+	suite.auditRepo.On("Record", mock.MatchedBy(func(e models.AuditEvent) bool {
+		return e.ActorID == "9" && e.Action == models.AuditChangeAccountStatus && e.Resource == "user:5" &&
+			strings.HasPrefix(e.Reason, "ACTIVE -> SUSPENDED (SUSPECTED_FRAUD)")
+	})).Return(&models.AuditEvent{}, nil)
+
+	status, err := suite.accountSvc.ChangeStatus(suite.admin, "5", request.AccountStatusRequest{
+		Status:     models.AccountSuspended,
+		ReasonCode: models.ReasonSuspectedFraud,
+		Note:       "Chargeback spike",
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.AccountActive, status.PreviousStatus)
+	assert.Equal(suite.T(), models.AccountSuspended, status.Status)
+	suite.revokedRepo.AssertExpectations(suite.T())
+	suite.refreshRepo.AssertExpectations(suite.T())
+	suite.auditRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AccountServiceTestSuite) TestReactivateKeepsSessions() {
+	suspended := suite.user
+	suspended.Status = models.AccountSuspended
+	suite.userRepo.On("FindByID", "5").Return(&suspended, nil)
+	suite.userRepo.On("UpdateStatus", "5", models.AccountActive, "REVIEW_CLEARED", mock.Anything).Return(nil)
+	suite.auditRepo.On("Record", mock.Anything).Return(&models.AuditEvent{}, nil)
+
+	status, err := suite.accountSvc.ChangeStatus(suite.admin, "5", request.AccountStatusRequest{
+		Status:     models.AccountActive,
+		ReasonCode: models.ReasonReviewCleared,
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.AccountActive, status.Status)
+	suite.revokedRepo.AssertNotCalled(suite.T(), "Revoke", mock.Anything)
+}
+
+func (suite *AccountServiceTestSuite) TestCloseRequiresZeroLedgerBalance() {
+	// Salinan saldo di users.json sudah nol, tetapi ledger belum
+	suite.user.Balance = idr("0")
+	suite.userRepo.On("FindByID", "5").Return(&suite.user, nil)
+	suite.ledgerSvc.On("Balances", "5").Return([]models.Money{idr("250")}, nil, nil)
+
+	_, err := suite.accountSvc.ChangeStatus(suite.admin, "5", request.AccountStatusRequest{
+		Status:     models.AccountClosed,
+		ReasonCode: models.ReasonCustomerRequest,
+	})
+
+	assert.ErrorIs(suite.T(), err, services.ErrAccountBalanceNotZero)
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceTestSuite) TestCloseRejectsPendingFunds() {
+	suite.userRepo.On("FindByID", "5").Return(&suite.user, nil)
+	closeRequest := request.AccountStatusRequest{Status: models.AccountClosed, ReasonCode: models.ReasonCustomerRequest}
+
+	// Withdrawal atau otorisasi yang masih terbuka menahan dana di akun HOLD
+	suite.ledgerSvc.On("Balances", "5").Return(nil, []models.Money{idr("100")}, nil).Once()
+	_, err := suite.accountSvc.ChangeStatus(suite.admin, "5", closeRequest)
+	assert.ErrorIs(suite.T(), err, services.ErrAccountHasPendingFunds)
+
+	// Deposit yang belum dikonfirmasi bank belum ada di ledger
+	pending := models.Transaction{ID: "7", CustomerID: "5", ActivityType: models.DepositActivity, Amount: idr("100")}
+	pending.Begin(time.Now())
+	suite.ledgerSvc.On("Balances", "5").Return(nil, nil, nil).Once()
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{pending}, nil).Once()
+	_, err = suite.accountSvc.ChangeStatus(suite.admin, "5", closeRequest)
+	assert.ErrorIs(suite.T(), err, services.ErrAccountHasPendingFunds)
+
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceTestSuite) TestCloseWithEmptyLedger() {
+	// Salinan saldo di users.json masih lama; yang menentukan adalah ledger
+	suite.userRepo.On("FindByID", "5").Return(&suite.user, nil)
+	suite.ledgerSvc.On("Balances", "5").Return(nil, nil, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{}, nil)
+	suite.userRepo.On("UpdateStatus", "5", models.AccountClosed, "CUSTOMER_REQUEST", mock.Anything).Return(nil)
+	suite.revokedRepo.On("Revoke", mock.Anything).Return(nil)
+	suite.refreshRepo.On("RevokeByUserID", "5").Return(nil)
+	suite.auditRepo.On("Record", mock.Anything).Return(&models.AuditEvent{}, nil)
+
+	status, err := suite.accountSvc.ChangeStatus(suite.admin, "5", request.AccountStatusRequest{
+		Status:     models.AccountClosed,
+		ReasonCode: models.ReasonCustomerRequest,
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.AccountClosed, status.Status)
+}
+
+func (suite *AccountServiceTestSuite) TestClosedAccountIsFinal() {
+	closed := suite.user
+	closed.Status = models.AccountClosed
+	suite.userRepo.On("FindByID", "5").Return(&closed, nil)
+
+	_, err := suite.accountSvc.ChangeStatus(suite.admin, "5", request.AccountStatusRequest{
+		Status:     models.AccountActive,
+		ReasonCode: models.ReasonOther,
+	})
+
+	assert.ErrorIs(suite.T(), err, services.ErrInvalidStatusTransition)
+}
+
+func (suite *AccountServiceTestSuite) TestRejectsUnknownReasonAndOwnAccount() {
+	_, err := suite.accountSvc.ChangeStatus(suite.admin, "5", request.AccountStatusRequest{
+		Status:     models.AccountSuspended,
+		ReasonCode: "BORED",
+	})
+	assert.ErrorIs(suite.T(), err, services.ErrUnknownReasonCode)
+
+	_, err = suite.accountSvc.ChangeStatus(suite.admin, "9", request.AccountStatusRequest{
+		Status:     models.AccountSuspended,
+		ReasonCode: models.ReasonOther,
+	})
+	assert.ErrorIs(suite.T(), err, services.ErrOwnAccountStatusChange)
+	suite.userRepo.AssertNotCalled(suite.T(), "FindByID", mock.Anything)
+}
+
+func (suite *AccountServiceTestSuite) TestSetTransferLimitsOverridesDefaults() {
+	suite.userRepo.On("FindByID", "5").Return(&suite.user, nil)
+	suite.userRepo.On("UpdateTransferLimits", "5", &models.TransferLimits{PerTransfer: idr("2000"), Daily: idr("8000")}).Return(nil)
+	suite.auditRepo.On("Record", mock.MatchedBy(func(e models.AuditEvent) bool {
+		return e.ActorID == "9" && e.Action == models.AuditChangeTransferLimits && e.Resource == "user:5"
+	})).Return(&models.AuditEvent{}, nil)
+
+	limits, err := suite.accountSvc.SetTransferLimits(suite.admin, "5", request.TransferLimitsRequest{
+		PerTransfer: idr("2000"),
+		Daily:       idr("8000"),
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), limits.Custom)
+	assert.Equal(suite.T(), idr("2000"), limits.PerTransfer)
+	suite.auditRepo.AssertExpectations(suite.T())
+}
+
+func (suite *AccountServiceTestSuite) TestSetTransferLimitsRejectsPerTransferAboveDaily() {
+	_, err := suite.accountSvc.SetTransferLimits(suite.admin, "5", request.TransferLimitsRequest{
+		PerTransfer: idr("9000"),
+		Daily:       idr("8000"),
+	})
+
+	assert.ErrorIs(suite.T(), err, models.ErrInvalidTransferLimits)
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdateTransferLimits", mock.Anything, mock.Anything)
+}
+
+func (suite *AccountServiceTestSuite) TestResetTransferLimitsFallsBackToDefaults() {
+	suite.user.TransferLimits = &models.TransferLimits{PerTransfer: idr("2000"), Daily: idr("8000")}
+	suite.userRepo.On("FindByID", "5").Return(&suite.user, nil)
+	suite.userRepo.On("UpdateTransferLimits", "5", (*models.TransferLimits)(nil)).Return(nil)
+	suite.auditRepo.On("Record", mock.Anything).Return(&models.AuditEvent{}, nil)
+
+	limits, err := suite.accountSvc.ResetTransferLimits(suite.admin, "5")
+
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), limits.Custom)
+	assert.Equal(suite.T(), idr("5000"), limits.Daily)
+}
+
+func TestAccountServiceSuite(t *testing.T) {
+	suite.Run(t, new(AccountServiceTestSuite))
+}
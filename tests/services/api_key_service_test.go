@@ -0,0 +1,190 @@
+package services_test
+
+import (
+	"errors"
+	"go-json/internal/dtos/request"
+	"go-json/internal/models"
+	"go-json/internal/security"
+	"go-json/internal/services"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(key models.APIKey) (*models.APIKey, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) FindByID(id string) (*models.APIKey, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) FindByPrefix(prefix string) (*models.APIKey, error) {
+	args := m.Called(prefix)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) FindByUserID(userID string) ([]models.APIKey, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Update(key models.APIKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+type APIKeyServiceTestSuite struct {
+	suite.Suite
+	apiKeyRepo *MockAPIKeyRepository
+	userRepo   *MockUserRepository
+	roleRepo   *MockRoleRepository
+	auditRepo  *MockAuditRepository
+	apiKeySvc  services.APIKeyService
+	merchant   security.Principal
+}
+
+func (suite *APIKeyServiceTestSuite) SetupTest() {
+	suite.apiKeyRepo = new(MockAPIKeyRepository)
+	suite.userRepo = new(MockUserRepository)
+	suite.roleRepo = new(MockRoleRepository)
+	suite.auditRepo = new(MockAuditRepository)
+	suite.apiKeySvc = services.NewAPIKeyService(suite.apiKeyRepo, suite.userRepo, suite.roleRepo, suite.auditRepo)
+	suite.merchant = security.Principal{
+		UserID:      "2",
+		Roles:       []string{"merchant"},
+		Permissions: []string{models.PermissionRefundCreate, models.PermissionHistoryReadMerchant, models.PermissionUserList, models.PermissionAPIKeyManage},
+	}
+
+	suite.auditRepo.On("Record", mock.Anything).Return(&models.AuditEvent{}, nil).Maybe()
+}
+
+func (suite *APIKeyServiceTestSuite) TestCreateKeyStoresOnlyHash() {
+	stored := &models.APIKey{}
+	suite.apiKeyRepo.On("FindByUserID", "2").Return([]models.APIKey{}, nil)
+	suite.apiKeyRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		*stored = args.Get(0).(models.APIKey)
+		stored.ID = "1"
+	}).Return(stored, nil)
+
+	created, err := suite.apiKeySvc.CreateKey(suite.merchant, request.CreateAPIKeyRequest{
+		Label:  "checkout backend",
+		Scopes: []string{models.PermissionRefundCreate},
+	})
+
+	suite.Require().NoError(err)
+	assert.True(suite.T(), strings.HasPrefix(created.Key, created.Prefix+"_"))
+	assert.Equal(suite.T(), security.HashAPIKey(created.Key), stored.KeyHash)
+	assert.NotContains(suite.T(), stored.KeyHash, created.Key)
+	assert.Equal(suite.T(), []string{models.PermissionRefundCreate}, created.Scopes)
+}
+
+func (suite *APIKeyServiceTestSuite) TestCreateKeyRejectsScopeOutsideRolesOrAllowlist() {
+	for _, scope := range []string{models.PermissionPaymentCreate, models.PermissionAPIKeyManage, models.PermissionUserList} {
+		_, err := suite.apiKeySvc.CreateKey(suite.merchant, request.CreateAPIKeyRequest{Label: "backend", Scopes: []string{scope}})
+
+		assert.ErrorIs(suite.T(), err, services.ErrInvalidAPIKeyScope, scope)
+	}
+	suite.apiKeyRepo.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *APIKeyServiceTestSuite) TestAuthenticateLimitsScopesToRolePermissions() {
+	key, prefix, err := security.GenerateAPIKey()
+	suite.Require().NoError(err)
+	stored := models.APIKey{
+		ID:      "1",
+		UserID:  "2",
+		Prefix:  prefix,
+		KeyHash: security.HashAPIKey(key),
+		Scopes:  []string{models.PermissionRefundCreate, models.PermissionHistoryReadMerchant, models.PermissionUserList},
+	}
+	suite.apiKeyRepo.On("FindByPrefix", prefix).Return(&stored, nil)
+	suite.apiKeyRepo.On("Update", mock.MatchedBy(func(k models.APIKey) bool {
+		return k.ID == "1" && k.LastUsedAt != nil
+	})).Return(nil)
+	suite.userRepo.On("FindByID", "2").Return(&models.User{ID: "2", Email: "merchant@example.com"}, nil)
+	suite.roleRepo.On("FindRoleByUserID", "2").Return(&[]models.UserRole{{UserID: "2", RoleID: "1"}}, nil)
+	suite.roleRepo.On("FindByRoleID", "1").Return(&models.Role{ID: "1", Name: "merchant"}, nil)
+	// history:read:merchant sudah dicabut dari role merchant setelah key dibuat, dan user:list
+	// bukan lagi scope API key
+	suite.roleRepo.On("PermissionsForRoles", []string{"merchant"}).Return([]string{models.PermissionRefundCreate, models.PermissionUserList})
+
+	principal, err := suite.apiKeySvc.AuthenticateAPIKey(key)
+
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "2", principal.UserID)
+	assert.Equal(suite.T(), "1", principal.APIKeyID)
+	assert.Equal(suite.T(), []string{models.PermissionRefundCreate}, principal.Permissions)
+	suite.apiKeyRepo.AssertExpectations(suite.T())
+}
+
+func (suite *APIKeyServiceTestSuite) TestAuthenticateRejectsRevokedOrWrongSecret() {
+	key, prefix, err := security.GenerateAPIKey()
+	suite.Require().NoError(err)
+	revokedAt := time.Now()
+	suite.apiKeyRepo.On("FindByPrefix", prefix).Return(&models.APIKey{ID: "1", UserID: "2", Prefix: prefix, KeyHash: security.HashAPIKey(key), RevokedAt: &revokedAt}, nil)
+
+	_, err = suite.apiKeySvc.AuthenticateAPIKey(key)
+	assert.ErrorIs(suite.T(), err, services.ErrInvalidAPIKey)
+
+	_, err = suite.apiKeySvc.AuthenticateAPIKey(prefix + "_wrong-secret")
+	assert.ErrorIs(suite.T(), err, services.ErrInvalidAPIKey)
+
+	_, err = suite.apiKeySvc.AuthenticateAPIKey("mbk_short")
+	assert.ErrorIs(suite.T(), err, services.ErrInvalidAPIKey)
+}
+
+func (suite *APIKeyServiceTestSuite) TestAuthenticateRejectsSuspendedOwner() {
+	key, prefix, err := security.GenerateAPIKey()
+	suite.Require().NoError(err)
+	suite.apiKeyRepo.On("FindByPrefix", prefix).Return(&models.APIKey{ID: "1", UserID: "2", Prefix: prefix, KeyHash: security.HashAPIKey(key)}, nil)
+	suite.userRepo.On("FindByID", "2").Return(&models.User{ID: "2", Status: models.AccountSuspended}, nil)
+
+	_, err = suite.apiKeySvc.AuthenticateAPIKey(key)
+
+	assert.ErrorIs(suite.T(), err, services.ErrInvalidAPIKey)
+}
+
+func (suite *APIKeyServiceTestSuite) TestRevokeKeyOfAnotherUser() {
+	suite.apiKeyRepo.On("FindByID", "1").Return(&models.APIKey{ID: "1", UserID: "3"}, nil)
+	suite.apiKeyRepo.On("FindByID", "9").Return(nil, errors.New("api key not found"))
+
+	assert.ErrorIs(suite.T(), suite.apiKeySvc.RevokeKey(suite.merchant, "1"), services.ErrAPIKeyNotFound)
+	assert.ErrorIs(suite.T(), suite.apiKeySvc.RevokeKey(suite.merchant, "9"), services.ErrAPIKeyNotFound)
+	suite.apiKeyRepo.AssertNotCalled(suite.T(), "Update", mock.Anything)
+}
+
+func (suite *APIKeyServiceTestSuite) TestRevokeKey() {
+	suite.apiKeyRepo.On("FindByID", "1").Return(&models.APIKey{ID: "1", UserID: "2"}, nil)
+	suite.apiKeyRepo.On("Update", mock.MatchedBy(func(k models.APIKey) bool {
+		return k.ID == "1" && k.IsRevoked()
+	})).Return(nil)
+
+	err := suite.apiKeySvc.RevokeKey(suite.merchant, "1")
+
+	assert.NoError(suite.T(), err)
+	suite.apiKeyRepo.AssertExpectations(suite.T())
+}
+
+func TestAPIKeyServiceSuite(t *testing.T) {
+	suite.Run(t, new(APIKeyServiceTestSuite))
+}
@@ -0,0 +1,117 @@
+package services_test
+
+import (
+	"errors"
+	"go-json/internal/dtos/request"
+	"go-json/internal/models"
+	"go-json/internal/security"
+	"go-json/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type EmailVerificationServiceTestSuite struct {
+	suite.Suite
+	userRepo        *MockUserRepository
+	oneTimeRepo     *MockOneTimeTokenRepository
+	auditRepo       *MockAuditRepository
+	outbox          *outbox
+	verificationSvc services.EmailVerificationService
+	user            models.User
+}
+
+func (suite *EmailVerificationServiceTestSuite) SetupTest() {
+	suite.userRepo = new(MockUserRepository)
+	suite.oneTimeRepo = new(MockOneTimeTokenRepository)
+	suite.auditRepo = new(MockAuditRepository)
+	suite.outbox = &outbox{}
+	suite.verificationSvc = services.NewEmailVerificationService(suite.userRepo, suite.oneTimeRepo, suite.auditRepo, suite.outbox, services.EmailVerificationConfig{
+		TokenTTL:       24 * time.Hour,
+		ResendCooldown: time.Minute,
+	})
+	suite.user = models.User{ID: "5", Username: "testuser", Email: "test@example.com", Status: models.AccountPending}
+
+	suite.auditRepo.On("Record", mock.Anything).Return(&models.AuditEvent{}, nil).Maybe()
+}
+
+func (suite *EmailVerificationServiceTestSuite) TestSendVerificationReplacesOldToken() {
+	suite.oneTimeRepo.On("DeleteByUserID", "5", models.EmailVerificationToken).Return(nil)
+	suite.oneTimeRepo.On("Create", mock.MatchedBy(func(t models.OneTimeToken) bool {
+		return t.UserID == "5" && t.Purpose == models.EmailVerificationToken && t.ExpiresAt.Sub(t.CreatedAt) == 24*time.Hour
+	})).Return(nil)
+
+	err := suite.verificationSvc.SendVerification(suite.user)
+
+	assert.NoError(suite.T(), err)
+	suite.oneTimeRepo.AssertExpectations(suite.T())
+	suite.Require().Len(suite.outbox.sent, 1)
+	assert.Equal(suite.T(), "test@example.com", suite.outbox.sent[0].To)
+}
+
+func (suite *EmailVerificationServiceTestSuite) TestResendWithinCooldown() {
+	suite.userRepo.On("FindByID", "5").Return(&suite.user, nil)
+	suite.oneTimeRepo.On("FindLatestByUserID", "5", models.EmailVerificationToken).
+		Return(&models.OneTimeToken{UserID: "5", CreatedAt: time.Now().Add(-10 * time.Second)}, nil)
+
+	err := suite.verificationSvc.Resend("5")
+
+	var tooSoon *services.VerificationResendError
+	assert.ErrorIs(suite.T(), err, services.ErrVerificationResendLimit)
+	assert.True(suite.T(), errors.As(err, &tooSoon))
+	assert.InDelta(suite.T(), 50, tooSoon.RetryAfter.Seconds(), 2)
+	assert.Empty(suite.T(), suite.outbox.sent)
+}
+
+func (suite *EmailVerificationServiceTestSuite) TestResendAfterCooldown() {
+	suite.userRepo.On("FindByID", "5").Return(&suite.user, nil)
+	suite.oneTimeRepo.On("FindLatestByUserID", "5", models.EmailVerificationToken).
+		Return(&models.OneTimeToken{UserID: "5", CreatedAt: time.Now().Add(-2 * time.Minute)}, nil)
+	suite.oneTimeRepo.On("DeleteByUserID", "5", models.EmailVerificationToken).Return(nil)
+	suite.oneTimeRepo.On("Create", mock.Anything).Return(nil)
+
+	err := suite.verificationSvc.Resend("5")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), suite.outbox.sent, 1)
+}
+
+func (suite *EmailVerificationServiceTestSuite) TestResendWhenAlreadyVerified() {
+	verifiedAt := time.Now()
+	verified := suite.user
+	verified.EmailVerifiedAt = &verifiedAt
+	suite.userRepo.On("FindByID", "5").Return(&verified, nil)
+
+	err := suite.verificationSvc.Resend("5")
+
+	assert.ErrorIs(suite.T(), err, services.ErrEmailAlreadyVerified)
+	suite.oneTimeRepo.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *EmailVerificationServiceTestSuite) TestVerifyActivatesPendingAccount() {
+	suite.oneTimeRepo.On("Consume", models.EmailVerificationToken, security.HashOneTimeToken("verify-token")).
+		Return(&models.OneTimeToken{UserID: "5", Purpose: models.EmailVerificationToken}, nil)
+	suite.userRepo.On("FindByID", "5").Return(&suite.user, nil)
+	suite.userRepo.On("MarkEmailVerified", "5", mock.Anything).Return(nil)
+
+	err := suite.verificationSvc.Verify(request.VerifyEmailRequest{Token: "verify-token"})
+
+	assert.NoError(suite.T(), err)
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *EmailVerificationServiceTestSuite) TestVerifyInvalidToken() {
+	suite.oneTimeRepo.On("Consume", models.EmailVerificationToken, mock.Anything).Return(nil, errors.New("token has expired or was already used"))
+
+	err := suite.verificationSvc.Verify(request.VerifyEmailRequest{Token: "expired"})
+
+	assert.ErrorIs(suite.T(), err, services.ErrInvalidVerificationToken)
+	suite.userRepo.AssertNotCalled(suite.T(), "MarkEmailVerified", mock.Anything, mock.Anything)
+}
+
+func TestEmailVerificationServiceSuite(t *testing.T) {
+	suite.Run(t, new(EmailVerificationServiceTestSuite))
+}
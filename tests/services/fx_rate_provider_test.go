@@ -0,0 +1,64 @@
+package services_test
+
+import (
+	"errors"
+	"go-json/internal/models"
+	"go-json/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type FXRateProviderTestSuite struct {
+	suite.Suite
+	provider services.FXRateProvider
+	switchAt time.Time
+}
+
+func (suite *FXRateProviderTestSuite) SetupTest() {
+	suite.switchAt = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	provider, err := services.NewFXRateProvider([]models.FXRate{
+		{From: "USD", To: "IDR", Rate: "16000", EffectiveFrom: start, EffectiveTo: &suite.switchAt},
+		{From: "USD", To: "IDR", Rate: "16250", EffectiveFrom: suite.switchAt},
+	})
+	suite.Require().NoError(err)
+	suite.provider = provider
+}
+
+func (suite *FXRateProviderTestSuite) TestQuoteUsesEffectiveRange() {
+	quote, err := suite.provider.Quote("USD", "IDR", suite.switchAt.Add(-time.Second))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "16000", quote.Rate)
+
+	quote, err = suite.provider.Quote("USD", "IDR", suite.switchAt)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "16250", quote.Rate)
+
+	_, err = suite.provider.Quote("USD", "IDR", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(suite.T(), errors.Is(err, services.ErrExchangeRateNotFound))
+}
+
+func (suite *FXRateProviderTestSuite) TestQuoteUsesInversePair() {
+	quote, err := suite.provider.Quote("IDR", "USD", suite.switchAt)
+	assert.NoError(suite.T(), err)
+
+	usd, err := quote.Convert(models.MustParseMoney("32500", "IDR"))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.MustParseMoney("2", "USD"), usd)
+}
+
+func (suite *FXRateProviderTestSuite) TestRejectsInvalidRates() {
+	_, err := services.NewFXRateProvider([]models.FXRate{{From: "USD", To: "IDR", Rate: "-1"}})
+	assert.Error(suite.T(), err)
+
+	_, err = services.NewFXRateProvider([]models.FXRate{{From: "USD", To: "XYZ", Rate: "1"}})
+	assert.Error(suite.T(), err)
+}
+
+func TestFXRateProviderSuite(t *testing.T) {
+	suite.Run(t, new(FXRateProviderTestSuite))
+}
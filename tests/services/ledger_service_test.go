@@ -0,0 +1,339 @@
+package services_test
+
+import (
+	"errors"
+	"go-json/internal/models"
+	"go-json/internal/services"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+func (m *MockLedgerRepository) CreateAccount(account models.LedgerAccount) (*models.LedgerAccount, error) {
+	args := m.Called(account)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LedgerAccount), args.Error(1)
+}
+
+func (m *MockLedgerRepository) FindAccountByID(id string) (*models.LedgerAccount, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LedgerAccount), args.Error(1)
+}
+
+func (m *MockLedgerRepository) FindAccountByUserID(userID string, accountType models.AccountType, currency models.Currency) (*models.LedgerAccount, error) {
+	args := m.Called(userID, accountType, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LedgerAccount), args.Error(1)
+}
+
+func (m *MockLedgerRepository) FindAccountsByUserID(userID string, accountType models.AccountType) ([]models.LedgerAccount, error) {
+	args := m.Called(userID, accountType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LedgerAccount), args.Error(1)
+}
+
+func (m *MockLedgerRepository) FindSystemAccount(accountType models.AccountType, currency models.Currency) (*models.LedgerAccount, error) {
+	args := m.Called(accountType, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LedgerAccount), args.Error(1)
+}
+
+func (m *MockLedgerRepository) CreateJournalEntry(entry models.JournalEntry) (*models.JournalEntry, error) {
+	args := m.Called(entry)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerRepository) FindJournalEntryByReference(reference string) (*models.JournalEntry, error) {
+	args := m.Called(reference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerRepository) FindAllJournalEntries() ([]models.JournalEntry, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerRepository) AccountBalance(accountID string) (models.Money, error) {
+	args := m.Called(accountID)
+	return args.Get(0).(models.Money), args.Error(1)
+}
+
+type LedgerServiceTestSuite struct {
+	suite.Suite
+	ledgerRepo     *MockLedgerRepository
+	userRepo       *MockUserRepository
+	ledgerSvc      services.LedgerService
+	customer       models.User
+	merchant       models.User
+	customerWallet models.LedgerAccount
+	merchantWallet models.LedgerAccount
+}
+
+func (suite *LedgerServiceTestSuite) SetupTest() {
+	suite.ledgerRepo = new(MockLedgerRepository)
+	suite.userRepo = new(MockUserRepository)
+	suite.ledgerSvc = services.NewLedgerService(suite.ledgerRepo, suite.userRepo)
+
+	suite.customer = models.User{ID: "1", Username: "customer1", Balance: idr("1000")}
+	suite.merchant = models.User{ID: "2", Username: "merchant1", Balance: idr("0")}
+	suite.customerWallet = models.LedgerAccount{ID: "10", UserID: "1", Type: models.WalletAccount, Currency: "IDR"}
+	suite.merchantWallet = models.LedgerAccount{ID: "20", UserID: "2", Type: models.WalletAccount, Currency: "IDR"}
+
+	suite.ledgerRepo.On("FindAccountsByUserID", mock.Anything, models.HoldAccount).Return([]models.LedgerAccount{}, nil).Maybe()
+}
+
+func (suite *LedgerServiceTestSuite) expectWallets() {
+	suite.ledgerRepo.On("FindAccountByUserID", "1", models.WalletAccount, models.Currency("IDR")).Return(&suite.customerWallet, nil)
+	suite.ledgerRepo.On("FindAccountByUserID", "2", models.WalletAccount, models.Currency("IDR")).Return(&suite.merchantWallet, nil)
+	suite.ledgerRepo.On("FindAccountByID", "10").Return(&suite.customerWallet, nil)
+	suite.ledgerRepo.On("FindAccountByID", "20").Return(&suite.merchantWallet, nil)
+}
+
+func (suite *LedgerServiceTestSuite) TestTransferCreditsMerchant() {
+	suite.expectWallets()
+	suite.ledgerRepo.On("AccountBalance", "10").Return(idr("1000"), nil).Once()
+	suite.ledgerRepo.On("CreateJournalEntry", mock.MatchedBy(func(e models.JournalEntry) bool {
+		return len(e.Postings) == 2 &&
+			e.Postings[0] == models.Posting{AccountID: "10", Direction: models.Debit, Amount: idr("300")} &&
+			e.Postings[1] == models.Posting{AccountID: "20", Direction: models.Credit, Amount: idr("300")}
+	})).Return(&models.JournalEntry{ID: "1"}, nil)
+	suite.ledgerRepo.On("AccountBalance", "10").Return(idr("700"), nil)
+	suite.ledgerRepo.On("AccountBalance", "20").Return(idr("300"), nil)
+	suite.ledgerRepo.On("FindAccountsByUserID", "1", models.WalletAccount).Return([]models.LedgerAccount{suite.customerWallet}, nil)
+	suite.ledgerRepo.On("FindAccountsByUserID", "2", models.WalletAccount).Return([]models.LedgerAccount{suite.merchantWallet}, nil)
+	suite.userRepo.On("FindByID", "1").Return(&suite.customer, nil)
+	suite.userRepo.On("FindByID", "2").Return(&suite.merchant, nil)
+	suite.userRepo.On("UpdateBalances", "1", idr("700"), mock.Anything, mock.Anything).Return(nil)
+	suite.userRepo.On("UpdateBalances", "2", idr("300"), mock.Anything, mock.Anything).Return(nil)
+
+	entry, err := suite.ledgerSvc.Transfer("1", "2", idr("300"), "Payment")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", entry.ID)
+	suite.ledgerRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *LedgerServiceTestSuite) TestTransferInsufficientBalance() {
+	suite.expectWallets()
+	suite.ledgerRepo.On("AccountBalance", "10").Return(idr("100"), nil)
+
+	entry, err := suite.ledgerSvc.Transfer("1", "2", idr("300"), "Payment")
+
+	assert.Nil(suite.T(), entry)
+	assert.True(suite.T(), errors.Is(err, services.ErrInsufficientBalance))
+	suite.ledgerRepo.AssertNotCalled(suite.T(), "CreateJournalEntry", mock.Anything)
+}
+
+func (suite *LedgerServiceTestSuite) TestPostEntryRejectsUnbalanced() {
+	suite.expectWallets()
+	entry, err := suite.ledgerSvc.PostEntry(models.JournalEntry{
+		Postings: []models.Posting{
+			{AccountID: "10", Direction: models.Debit, Amount: idr("300")},
+			{AccountID: "20", Direction: models.Credit, Amount: idr("200")},
+		},
+	})
+
+	assert.Nil(suite.T(), entry)
+	assert.Error(suite.T(), err)
+	assert.Equal(suite.T(), "journal entry is not balanced", err.Error())
+	suite.ledgerRepo.AssertNotCalled(suite.T(), "CreateJournalEntry", mock.Anything)
+}
+
+func (suite *LedgerServiceTestSuite) TestWalletAccountPostsOpeningBalance() {
+	newUser := models.User{ID: "3", Username: "newuser", Balance: idr("500")}
+	suite.ledgerRepo.On("FindAccountByUserID", "3", models.WalletAccount, models.Currency("IDR")).Return(nil, errors.New("ledger account not found"))
+	suite.userRepo.On("FindByID", "3").Return(&newUser, nil)
+	suite.ledgerRepo.On("CreateAccount", mock.MatchedBy(func(a models.LedgerAccount) bool {
+		return a.UserID == "3" && a.Type == models.WalletAccount && a.Currency == "IDR"
+	})).Return(&models.LedgerAccount{ID: "30", UserID: "3", Type: models.WalletAccount, Currency: "IDR"}, nil)
+	suite.ledgerRepo.On("FindSystemAccount", models.OpeningBalanceAccount, models.Currency("IDR")).Return(&models.LedgerAccount{ID: "1", Type: models.OpeningBalanceAccount, Currency: "IDR"}, nil)
+	suite.ledgerRepo.On("FindAccountByID", "1").Return(&models.LedgerAccount{ID: "1", Type: models.OpeningBalanceAccount, Currency: "IDR"}, nil)
+	suite.ledgerRepo.On("FindAccountByID", "30").Return(&models.LedgerAccount{ID: "30", UserID: "3", Type: models.WalletAccount, Currency: "IDR"}, nil)
+	suite.ledgerRepo.On("CreateJournalEntry", mock.MatchedBy(func(e models.JournalEntry) bool {
+		return e.Postings[0].AccountID == "1" && e.Postings[1].AccountID == "30" && e.Postings[1].Amount == idr("500")
+	})).Return(&models.JournalEntry{ID: "1"}, nil)
+
+	account, err := suite.ledgerSvc.WalletAccount("3", "IDR")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "30", account.ID)
+	suite.ledgerRepo.AssertExpectations(suite.T())
+}
+
+func (suite *LedgerServiceTestSuite) TestTransferWithConversionBalancesEachCurrency() {
+	usdWallet := models.LedgerAccount{ID: "21", UserID: "2", Type: models.WalletAccount, Currency: "USD"}
+	fxIDR := models.LedgerAccount{ID: "2", Type: models.FXConversionAccount, Currency: "IDR"}
+	fxUSD := models.LedgerAccount{ID: "3", Type: models.FXConversionAccount, Currency: "USD"}
+	usd := models.MustParseMoney("0.04", "USD")
+
+	suite.ledgerRepo.On("FindAccountByUserID", "1", models.WalletAccount, models.Currency("IDR")).Return(&suite.customerWallet, nil)
+	suite.ledgerRepo.On("FindAccountByID", "10").Return(&suite.customerWallet, nil)
+	suite.ledgerRepo.On("FindAccountByUserID", "2", models.WalletAccount, models.Currency("USD")).Return(&usdWallet, nil)
+	suite.ledgerRepo.On("FindAccountByID", "21").Return(&usdWallet, nil)
+	suite.ledgerRepo.On("FindSystemAccount", models.FXConversionAccount, models.Currency("IDR")).Return(&fxIDR, nil)
+	suite.ledgerRepo.On("FindSystemAccount", models.FXConversionAccount, models.Currency("USD")).Return(&fxUSD, nil)
+	suite.ledgerRepo.On("FindAccountByID", "2").Return(&fxIDR, nil)
+	suite.ledgerRepo.On("FindAccountByID", "3").Return(&fxUSD, nil)
+	suite.ledgerRepo.On("AccountBalance", "10").Return(idr("1000"), nil)
+	suite.ledgerRepo.On("AccountBalance", "21").Return(usd, nil)
+	suite.ledgerRepo.On("CreateJournalEntry", mock.MatchedBy(func(e models.JournalEntry) bool {
+		return len(e.Postings) == 4 &&
+			e.Postings[0] == models.Posting{AccountID: "10", Direction: models.Debit, Amount: idr("650")} &&
+			e.Postings[1] == models.Posting{AccountID: "21", Direction: models.Credit, Amount: usd} &&
+			e.Postings[2] == models.Posting{AccountID: "2", Direction: models.Credit, Amount: idr("650")} &&
+			e.Postings[3] == models.Posting{AccountID: "3", Direction: models.Debit, Amount: usd}
+	})).Return(&models.JournalEntry{ID: "1"}, nil)
+	suite.ledgerRepo.On("FindAccountsByUserID", "1", models.WalletAccount).Return([]models.LedgerAccount{suite.customerWallet}, nil)
+	suite.ledgerRepo.On("FindAccountsByUserID", "2", models.WalletAccount).Return([]models.LedgerAccount{suite.merchantWallet, usdWallet}, nil)
+	suite.ledgerRepo.On("AccountBalance", "20").Return(idr("0"), nil)
+	suite.userRepo.On("FindByID", "1").Return(&suite.customer, nil)
+	suite.userRepo.On("FindByID", "2").Return(&suite.merchant, nil)
+	suite.userRepo.On("UpdateBalances", "1", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	suite.userRepo.On("UpdateBalances", "2", mock.Anything, mock.MatchedBy(func(wallets []models.Money) bool {
+		return len(wallets) == 2 && slices.Contains(wallets, usd)
+	}), mock.Anything).Return(nil)
+
+	entry, err := suite.ledgerSvc.TransferWithConversion("1", "2", idr("650"), usd, "Payment")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", entry.ID)
+	suite.ledgerRepo.AssertExpectations(suite.T())
+}
+
+func (suite *LedgerServiceTestSuite) TestHoldMovesFundsToHoldAccount() {
+	hold := models.LedgerAccount{ID: "11", UserID: "1", Type: models.HoldAccount, Currency: "IDR"}
+	suite.ledgerRepo.On("FindAccountByUserID", "1", models.WalletAccount, models.Currency("IDR")).Return(&suite.customerWallet, nil)
+	suite.ledgerRepo.On("FindAccountByID", "10").Return(&suite.customerWallet, nil)
+	suite.ledgerRepo.On("FindAccountByUserID", "1", models.HoldAccount, models.Currency("IDR")).Return(&hold, nil)
+	suite.ledgerRepo.On("FindAccountByID", "11").Return(&hold, nil)
+	suite.ledgerRepo.On("AccountBalance", "10").Return(idr("1000"), nil).Once()
+	suite.ledgerRepo.On("CreateJournalEntry", mock.MatchedBy(func(e models.JournalEntry) bool {
+		return len(e.Postings) == 2 &&
+			e.Postings[0] == models.Posting{AccountID: "10", Direction: models.Debit, Amount: idr("300")} &&
+			e.Postings[1] == models.Posting{AccountID: "11", Direction: models.Credit, Amount: idr("300")}
+	})).Return(&models.JournalEntry{ID: "1"}, nil)
+	suite.ledgerRepo.On("AccountBalance", "10").Return(idr("700"), nil)
+	suite.ledgerRepo.On("FindAccountsByUserID", "1", models.WalletAccount).Return([]models.LedgerAccount{suite.customerWallet}, nil)
+	suite.userRepo.On("FindByID", "1").Return(&suite.customer, nil)
+	suite.userRepo.On("UpdateBalances", "1", idr("700"), mock.Anything, mock.Anything).Return(nil)
+
+	entry, err := suite.ledgerSvc.Hold("1", idr("300"), "Authorization")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", entry.ID)
+	suite.ledgerRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *LedgerServiceTestSuite) TestCaptureHoldCannotExceedHeldFunds() {
+	hold := models.LedgerAccount{ID: "11", UserID: "1", Type: models.HoldAccount, Currency: "IDR"}
+	suite.expectWallets()
+	suite.ledgerRepo.On("FindAccountByUserID", "1", models.HoldAccount, models.Currency("IDR")).Return(&hold, nil)
+	suite.ledgerRepo.On("AccountBalance", "11").Return(idr("100"), nil)
+
+	entry, err := suite.ledgerSvc.CaptureHold("1", "2", idr("300"), "Capture")
+
+	assert.Nil(suite.T(), entry)
+	assert.ErrorIs(suite.T(), err, services.ErrInsufficientBalance)
+	suite.ledgerRepo.AssertNotCalled(suite.T(), "CreateJournalEntry", mock.Anything)
+}
+
+func (suite *LedgerServiceTestSuite) TestDepositCreditsWalletFromBankSettlement() {
+	settlement := models.LedgerAccount{ID: "4", Type: models.BankSettlementAccount, Currency: "IDR"}
+	suite.expectWallets()
+	suite.ledgerRepo.On("FindJournalEntryByReference", "20").Return(nil, errors.New("journal entry not found"))
+	suite.ledgerRepo.On("FindSystemAccount", models.BankSettlementAccount, models.Currency("IDR")).Return(&settlement, nil)
+	suite.ledgerRepo.On("FindAccountByID", "4").Return(&settlement, nil)
+	suite.ledgerRepo.On("CreateJournalEntry", mock.MatchedBy(func(e models.JournalEntry) bool {
+		return e.Reference == "20" && len(e.Postings) == 2 &&
+			e.Postings[0] == models.Posting{AccountID: "4", Direction: models.Debit, Amount: idr("500")} &&
+			e.Postings[1] == models.Posting{AccountID: "10", Direction: models.Credit, Amount: idr("500")}
+	})).Return(&models.JournalEntry{ID: "1"}, nil)
+	suite.ledgerRepo.On("AccountBalance", "10").Return(idr("1500"), nil)
+	suite.ledgerRepo.On("FindAccountsByUserID", "1", models.WalletAccount).Return([]models.LedgerAccount{suite.customerWallet}, nil)
+	suite.userRepo.On("FindByID", "1").Return(&suite.customer, nil)
+	suite.userRepo.On("UpdateBalances", "1", idr("1500"), mock.Anything, mock.Anything).Return(nil)
+
+	entry, err := suite.ledgerSvc.Deposit("1", idr("500"), "20", "Deposit")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", entry.ID)
+	suite.userRepo.AssertExpectations(suite.T())
+}
+
+func (suite *LedgerServiceTestSuite) TestDepositWithPostedReferenceDoesNotPostAgain() {
+	suite.expectWallets()
+	suite.ledgerRepo.On("FindJournalEntryByReference", "20").Return(&models.JournalEntry{ID: "1", Reference: "20"}, nil)
+
+	entry, err := suite.ledgerSvc.Deposit("1", idr("500"), "20", "Deposit")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "1", entry.ID)
+	suite.ledgerRepo.AssertNotCalled(suite.T(), "CreateJournalEntry", mock.Anything)
+}
+
+func (suite *LedgerServiceTestSuite) TestPayoutHoldWithPostedReferenceDoesNotPostAgain() {
+	hold := models.LedgerAccount{ID: "12", UserID: "1", Type: models.HoldAccount, Currency: "IDR"}
+	settlement := models.LedgerAccount{ID: "4", Type: models.BankSettlementAccount, Currency: "IDR"}
+	suite.ledgerRepo.On("FindAccountByUserID", "1", models.HoldAccount, models.Currency("IDR")).Return(&hold, nil)
+	suite.ledgerRepo.On("FindSystemAccount", models.BankSettlementAccount, models.Currency("IDR")).Return(&settlement, nil)
+	suite.ledgerRepo.On("FindJournalEntryByReference", "21").Return(&models.JournalEntry{ID: "3", Reference: "21"}, nil)
+
+	entry, err := suite.ledgerSvc.PayoutHold("1", idr("300"), "21", "Withdrawal 21 paid out by bank")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "3", entry.ID)
+	suite.ledgerRepo.AssertNotCalled(suite.T(), "AccountBalance", mock.Anything)
+	suite.ledgerRepo.AssertNotCalled(suite.T(), "CreateJournalEntry", mock.Anything)
+}
+
+func (suite *LedgerServiceTestSuite) TestBalancesReadsLedgerAndSkipsEmptyAccounts() {
+	// Ganti ekspektasi akun HOLD kosong dari SetupTest
+	suite.ledgerRepo.ExpectedCalls = nil
+	usdWallet := models.LedgerAccount{ID: "11", UserID: "1", Type: models.WalletAccount, Currency: "USD"}
+	hold := models.LedgerAccount{ID: "12", UserID: "1", Type: models.HoldAccount, Currency: "IDR"}
+	suite.ledgerRepo.On("FindAccountsByUserID", "1", models.WalletAccount).Return([]models.LedgerAccount{suite.customerWallet, usdWallet}, nil)
+	suite.ledgerRepo.On("FindAccountsByUserID", "1", models.HoldAccount).Return([]models.LedgerAccount{hold}, nil)
+	suite.ledgerRepo.On("AccountBalance", "10").Return(idr("0"), nil)
+	suite.ledgerRepo.On("AccountBalance", "11").Return(models.MustParseMoney("5", "USD"), nil)
+	suite.ledgerRepo.On("AccountBalance", "12").Return(idr("100"), nil)
+
+	wallets, held, err := suite.ledgerSvc.Balances("1")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []models.Money{models.MustParseMoney("5", "USD")}, wallets)
+	assert.Equal(suite.T(), []models.Money{idr("100")}, held)
+	suite.userRepo.AssertNotCalled(suite.T(), "FindByID", mock.Anything)
+}
+
+func TestLedgerServiceSuite(t *testing.T) {
+	suite.Run(t, new(LedgerServiceTestSuite))
+}
@@ -0,0 +1,195 @@
+package services_test
+
+import (
+	"errors"
+	"go-json/internal/models"
+	"go-json/internal/services"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockLoginAttemptRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginAttemptRepository) Find(kind models.LoginAttemptKind, key string) (*models.LoginAttempt, error) {
+	args := m.Called(kind, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LoginAttempt), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepository) FindAll() ([]models.LoginAttempt, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LoginAttempt), args.Error(1)
+}
+
+func (m *MockLoginAttemptRepository) Save(attempt models.LoginAttempt) error {
+	args := m.Called(attempt)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptRepository) Delete(kind models.LoginAttemptKind, key string) error {
+	args := m.Called(kind, key)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptRepository) PruneBefore(cutoff time.Time) error {
+	args := m.Called(cutoff)
+	return args.Error(0)
+}
+
+type LoginThrottleTestSuite struct {
+	suite.Suite
+	attemptRepo *MockLoginAttemptRepository
+	auditRepo   *MockAuditRepository
+	config      services.LoginThrottleConfig
+	throttle    services.LoginThrottle
+}
+
+func (suite *LoginThrottleTestSuite) SetupTest() {
+	suite.attemptRepo = new(MockLoginAttemptRepository)
+	suite.auditRepo = new(MockAuditRepository)
+	suite.config = services.LoginThrottleConfig{
+		MaxAccountFailures: 5,
+		MaxIPFailures:      20,
+		AccountDelayAfter:  3,
+		IPDelayAfter:       10,
+		BaseDelay:          time.Minute,
+		FailureWindow:      15 * time.Minute,
+		LockoutDuration:    15 * time.Minute,
+	}
+	suite.throttle = services.NewLoginThrottle(suite.attemptRepo, suite.auditRepo, suite.config)
+}
+
+func (suite *LoginThrottleTestSuite) TestCheckWithoutFailures() {
+	suite.attemptRepo.On("Find", mock.Anything, mock.Anything).Return(nil, errors.New("login attempt not found"))
+
+	assert.NoError(suite.T(), suite.throttle.Check("testuser", "10.0.0.1"))
+}
+
+func (suite *LoginThrottleTestSuite) TestCheckReservesParallelAttempts() {
+	suite.attemptRepo.On("Find", mock.Anything, mock.Anything).Return(nil, errors.New("login attempt not found"))
+
+	// Tanpa Release, hanya AccountDelayAfter percobaan paralel yang boleh jalan
+	var wg sync.WaitGroup
+	var passed atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if suite.throttle.Check("testuser", "10.0.0.1") == nil {
+				passed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(suite.T(), int32(suite.config.AccountDelayAfter), passed.Load())
+
+	err := suite.throttle.Check("testuser", "10.0.0.1")
+	assert.ErrorIs(suite.T(), err, services.ErrLoginThrottled)
+
+	suite.throttle.Release("testuser", "10.0.0.1")
+	assert.NoError(suite.T(), suite.throttle.Check("testuser", "10.0.0.1"))
+}
+
+func (suite *LoginThrottleTestSuite) TestCheckProgressiveDelay() {
+	suite.attemptRepo.On("Find", models.AccountLoginAttempt, "testuser").Return(&models.LoginAttempt{
+		Kind:          models.AccountLoginAttempt,
+		Key:           "testuser",
+		Failures:      4,
+		LastFailureAt: time.Now(),
+	}, nil)
+	suite.attemptRepo.On("Find", models.IPLoginAttempt, "10.0.0.1").Return(nil, errors.New("login attempt not found"))
+
+	err := suite.throttle.Check("testuser", "10.0.0.1")
+
+	var throttled *services.LoginThrottledError
+	assert.ErrorAs(suite.T(), err, &throttled)
+	assert.ErrorIs(suite.T(), err, services.ErrLoginThrottled)
+	assert.False(suite.T(), throttled.Locked)
+	// Kegagalan ke-4 dengan AccountDelayAfter 3 menunggu BaseDelay << 1
+	assert.InDelta(suite.T(), (2 * time.Minute).Seconds(), throttled.RetryAfter.Seconds(), 1)
+}
+
+func (suite *LoginThrottleTestSuite) TestCheckLockedIP() {
+	lockedUntil := time.Now().Add(10 * time.Minute)
+	suite.attemptRepo.On("Find", models.AccountLoginAttempt, "testuser").Return(nil, errors.New("login attempt not found"))
+	suite.attemptRepo.On("Find", models.IPLoginAttempt, "10.0.0.1").Return(&models.LoginAttempt{
+		Kind:          models.IPLoginAttempt,
+		Key:           "10.0.0.1",
+		Failures:      20,
+		LastFailureAt: time.Now().Add(-5 * time.Minute),
+		LockedUntil:   &lockedUntil,
+	}, nil)
+
+	err := suite.throttle.Check("testuser", "10.0.0.1")
+
+	var throttled *services.LoginThrottledError
+	assert.ErrorAs(suite.T(), err, &throttled)
+	assert.True(suite.T(), throttled.Locked)
+}
+
+func (suite *LoginThrottleTestSuite) TestFailedLocksAccountAtThreshold() {
+	suite.attemptRepo.On("Find", models.AccountLoginAttempt, "testuser").Return(&models.LoginAttempt{
+		Kind:          models.AccountLoginAttempt,
+		Key:           "testuser",
+		Failures:      4,
+		LastFailureAt: time.Now().Add(-time.Minute),
+	}, nil)
+	suite.attemptRepo.On("Find", models.IPLoginAttempt, "10.0.0.1").Return(nil, errors.New("login attempt not found"))
+	suite.attemptRepo.On("Save", mock.MatchedBy(func(a models.LoginAttempt) bool {
+		return a.Kind == models.AccountLoginAttempt && a.Failures == 5 && a.LockedUntil != nil
+	})).Return(nil)
+	suite.attemptRepo.On("Save", mock.MatchedBy(func(a models.LoginAttempt) bool {
+		return a.Kind == models.IPLoginAttempt && a.Failures == 1 && a.LockedUntil == nil
+	})).Return(nil)
+	suite.auditRepo.On("Record", mock.MatchedBy(func(e models.AuditEvent) bool {
+		return e.Action == models.AuditLoginLockout && e.Resource == "username:testuser" && e.Outcome == models.AuditDenied
+	})).Return(&models.AuditEvent{}, nil)
+
+	err := suite.throttle.Failed("testuser", "10.0.0.1")
+
+	assert.NoError(suite.T(), err)
+	suite.attemptRepo.AssertExpectations(suite.T())
+	suite.auditRepo.AssertExpectations(suite.T())
+}
+
+func (suite *LoginThrottleTestSuite) TestFailedResetsStaleCount() {
+	suite.attemptRepo.On("Find", models.AccountLoginAttempt, "testuser").Return(&models.LoginAttempt{
+		Kind:          models.AccountLoginAttempt,
+		Key:           "testuser",
+		Failures:      4,
+		LastFailureAt: time.Now().Add(-time.Hour),
+	}, nil)
+	suite.attemptRepo.On("Save", mock.MatchedBy(func(a models.LoginAttempt) bool {
+		return a.Failures == 1 && a.LockedUntil == nil
+	})).Return(nil)
+
+	err := suite.throttle.Failed("testuser", "")
+
+	assert.NoError(suite.T(), err)
+	suite.attemptRepo.AssertExpectations(suite.T())
+	suite.auditRepo.AssertNotCalled(suite.T(), "Record", mock.Anything)
+}
+
+func (suite *LoginThrottleTestSuite) TestSucceededClearsAccountOnly() {
+	suite.attemptRepo.On("Delete", models.AccountLoginAttempt, "testuser").Return(nil)
+
+	assert.NoError(suite.T(), suite.throttle.Succeeded("testuser"))
+	suite.attemptRepo.AssertNotCalled(suite.T(), "Delete", models.IPLoginAttempt, mock.Anything)
+}
+
+func TestLoginThrottleTestSuite(t *testing.T) {
+	suite.Run(t, new(LoginThrottleTestSuite))
+}
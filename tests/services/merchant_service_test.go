@@ -0,0 +1,192 @@
+package services_test
+
+import (
+	"errors"
+	"go-json/internal/dtos/request"
+	"go-json/internal/models"
+	"go-json/internal/security"
+	"go-json/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockMerchantRepository struct {
+	mock.Mock
+}
+
+func (m *MockMerchantRepository) Create(merchant models.Merchant) (*models.Merchant, error) {
+	args := m.Called(merchant)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Merchant), args.Error(1)
+}
+
+func (m *MockMerchantRepository) FindByID(id string) (*models.Merchant, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Merchant), args.Error(1)
+}
+
+func (m *MockMerchantRepository) FindByOwnerID(userID string) ([]models.Merchant, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Merchant), args.Error(1)
+}
+
+func (m *MockMerchantRepository) FindAll() ([]models.Merchant, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Merchant), args.Error(1)
+}
+
+func (m *MockMerchantRepository) Update(merchant models.Merchant) error {
+	args := m.Called(merchant)
+	return args.Error(0)
+}
+
+type MerchantServiceTestSuite struct {
+	suite.Suite
+	merchantRepo    *MockMerchantRepository
+	userRepo        *MockUserRepository
+	roleRepo        *MockRoleRepository
+	transactionRepo *MockTransactionRepository
+	auditRepo       *MockAuditRepository
+	merchantSvc     services.MerchantService
+	owner           security.Principal
+	admin           security.Principal
+	onboardReq      request.MerchantRequest
+}
+
+func (suite *MerchantServiceTestSuite) SetupTest() {
+	suite.merchantRepo = new(MockMerchantRepository)
+	suite.userRepo = new(MockUserRepository)
+	suite.roleRepo = new(MockRoleRepository)
+	suite.transactionRepo = new(MockTransactionRepository)
+	suite.auditRepo = new(MockAuditRepository)
+	suite.merchantSvc = services.NewMerchantService(suite.merchantRepo, suite.userRepo, suite.roleRepo, suite.transactionRepo, suite.auditRepo)
+	suite.owner = merchantViewer("8")
+	suite.admin = adminViewer("1")
+	suite.onboardReq = request.MerchantRequest{
+		LegalName:    "PT Kopi Senja",
+		DisplayName:  "Kopi Senja",
+		CategoryCode: "5814",
+		SettlementAccount: request.SettlementAccountRequest{
+			BankCode:      "BCA",
+			AccountNumber: "1234567890",
+			AccountHolder: "PT Kopi Senja",
+		},
+	}
+
+	suite.auditRepo.On("Record", mock.Anything).Return(&models.AuditEvent{}, nil).Maybe()
+}
+
+func (suite *MerchantServiceTestSuite) TestOnboardCreatesPendingMerchant() {
+	suite.merchantRepo.On("FindByID", "8").Return(nil, errors.New("merchant not found"))
+	suite.merchantRepo.On("Create", mock.MatchedBy(func(m models.Merchant) bool {
+		return m.ID == "8" && m.Status == models.MerchantPending && m.IsOwnedBy("8") && m.SettlementAccount.AccountNumber == "1234567890"
+	})).Return(&models.Merchant{ID: "8", DisplayName: "Kopi Senja", Status: models.MerchantPending, OwnerIDs: []string{"8"}}, nil)
+
+	merchant, err := suite.merchantSvc.Onboard(suite.owner, suite.onboardReq)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.MerchantPending, merchant.Status)
+	suite.merchantRepo.AssertExpectations(suite.T())
+}
+
+func (suite *MerchantServiceTestSuite) TestOnboardRejectsSecondProfile() {
+	suite.merchantRepo.On("FindByID", "8").Return(&models.Merchant{ID: "8"}, nil)
+
+	_, err := suite.merchantSvc.Onboard(suite.owner, suite.onboardReq)
+
+	assert.ErrorIs(suite.T(), err, services.ErrMerchantExists)
+	suite.merchantRepo.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *MerchantServiceTestSuite) TestOnboardValidatesCategoryCode() {
+	suite.onboardReq.CategoryCode = "food"
+
+	_, err := suite.merchantSvc.Onboard(suite.owner, suite.onboardReq)
+
+	assert.Error(suite.T(), err)
+	suite.merchantRepo.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *MerchantServiceTestSuite) TestUpdateMerchantRequiresOwner() {
+	suite.merchantRepo.On("FindByID", "8").Return(&models.Merchant{ID: "8", OwnerIDs: []string{"8"}}, nil)
+
+	_, err := suite.merchantSvc.UpdateMerchant(merchantViewer("9"), "8", suite.onboardReq)
+
+	assert.ErrorIs(suite.T(), err, services.ErrMerchantNotFound)
+	suite.merchantRepo.AssertNotCalled(suite.T(), "Update", mock.Anything)
+}
+
+func (suite *MerchantServiceTestSuite) TestApproveMerchant() {
+	suite.merchantRepo.On("FindByID", "8").Return(&models.Merchant{ID: "8", Status: models.MerchantPending, OwnerIDs: []string{"8"}}, nil)
+	suite.merchantRepo.On("Update", mock.MatchedBy(func(m models.Merchant) bool {
+		return m.Status == models.MerchantActive && m.StatusReason == "REVIEW_CLEARED"
+	})).Return(nil)
+
+	merchant, err := suite.merchantSvc.ChangeStatus(suite.admin, "8", request.MerchantStatusRequest{
+		Status:     models.MerchantActive,
+		ReasonCode: models.ReasonReviewCleared,
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.MerchantActive, merchant.Status)
+	suite.merchantRepo.AssertExpectations(suite.T())
+}
+
+func (suite *MerchantServiceTestSuite) TestChangeStatusRejectsInvalidTransition() {
+	suite.merchantRepo.On("FindByID", "8").Return(&models.Merchant{ID: "8", Status: models.MerchantActive}, nil)
+
+	_, err := suite.merchantSvc.ChangeStatus(suite.admin, "8", request.MerchantStatusRequest{
+		Status:     models.MerchantActive,
+		ReasonCode: models.ReasonReviewCleared,
+	})
+
+	assert.ErrorIs(suite.T(), err, services.ErrInvalidMerchantTransition)
+	suite.merchantRepo.AssertNotCalled(suite.T(), "Update", mock.Anything)
+}
+
+func (suite *MerchantServiceTestSuite) TestRemoveWalletOwnerNotAllowed() {
+	suite.merchantRepo.On("FindByID", "8").Return(&models.Merchant{ID: "8", OwnerIDs: []string{"8", "9"}}, nil)
+
+	_, err := suite.merchantSvc.RemoveOwner(suite.admin, "8", "8")
+
+	assert.ErrorIs(suite.T(), err, services.ErrWalletOwnerRequired)
+}
+
+func (suite *MerchantServiceTestSuite) TestProvisionLegacyMerchants() {
+	suite.userRepo.On("FindAll").Return([]models.User{{ID: "2", Username: "toko"}, {ID: "5", Username: "budi"}, {ID: "8", Username: "kopi"}}, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{
+		{ID: "1", CustomerID: "5", MerchantID: "2", ActivityType: models.PaymentActivity},
+	}, nil)
+	suite.merchantRepo.On("FindByID", "2").Return(nil, errors.New("merchant not found"))
+	suite.merchantRepo.On("FindByID", "5").Return(nil, errors.New("merchant not found"))
+	suite.merchantRepo.On("FindByID", "8").Return(&models.Merchant{ID: "8"}, nil)
+	suite.roleRepo.On("FindRoleByUserID", "5").Return(&[]models.UserRole{{UserID: "5", RoleID: "2"}}, nil)
+	suite.roleRepo.On("FindByRoleID", "2").Return(&models.Role{ID: "2", Name: "customer"}, nil)
+	suite.merchantRepo.On("Create", mock.MatchedBy(func(m models.Merchant) bool {
+		return m.ID == "2" && m.Status == models.MerchantActive && m.DisplayName == "toko"
+	})).Return(&models.Merchant{ID: "2"}, nil).Once()
+
+	err := suite.merchantSvc.ProvisionLegacyMerchants()
+
+	assert.NoError(suite.T(), err)
+	suite.merchantRepo.AssertExpectations(suite.T())
+	suite.merchantRepo.AssertNumberOfCalls(suite.T(), "Create", 1)
+}
+
+func TestMerchantServiceSuite(t *testing.T) {
+	suite.Run(t, new(MerchantServiceTestSuite))
+}
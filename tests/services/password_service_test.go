@@ -0,0 +1,217 @@
+package services_test
+
+import (
+	"errors"
+	"go-json/internal/dtos/request"
+	"go-json/internal/models"
+	"go-json/internal/notifications"
+	"go-json/internal/security"
+	"go-json/internal/services"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type MockOneTimeTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockOneTimeTokenRepository) Create(token models.OneTimeToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockOneTimeTokenRepository) Consume(purpose models.OneTimeTokenPurpose, tokenHash string) (*models.OneTimeToken, error) {
+	args := m.Called(purpose, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OneTimeToken), args.Error(1)
+}
+
+func (m *MockOneTimeTokenRepository) FindLatestByUserID(userID string, purpose models.OneTimeTokenPurpose) (*models.OneTimeToken, error) {
+	args := m.Called(userID, purpose)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OneTimeToken), args.Error(1)
+}
+
+func (m *MockOneTimeTokenRepository) DeleteByUserID(userID string, purpose models.OneTimeTokenPurpose) error {
+	args := m.Called(userID, purpose)
+	return args.Error(0)
+}
+
+func (m *MockOneTimeTokenRepository) PruneExpired() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// outbox menyimpan notifikasi yang dikirim agar token di dalamnya bisa diperiksa
+type outbox struct {
+	sent []notifications.Notification
+}
+
+func (o *outbox) Send(notification notifications.Notification) error {
+	o.sent = append(o.sent, notification)
+	return nil
+}
+
+type PasswordServiceTestSuite struct {
+	suite.Suite
+	userRepo    *MockUserRepository
+	refreshRepo *MockRefreshTokenRepository
+	revokedRepo *MockRevokedTokenRepository
+	oneTimeRepo *MockOneTimeTokenRepository
+	auditRepo   *MockAuditRepository
+	tokenSvc    *MockTokenService
+	throttle    *MockLoginThrottle
+	hasher      security.PasswordHasher
+	outbox      *outbox
+	passwordSvc services.PasswordService
+	user        models.User
+}
+
+func (suite *PasswordServiceTestSuite) SetupTest() {
+	suite.userRepo = new(MockUserRepository)
+	suite.refreshRepo = new(MockRefreshTokenRepository)
+	suite.revokedRepo = new(MockRevokedTokenRepository)
+	suite.oneTimeRepo = new(MockOneTimeTokenRepository)
+	suite.auditRepo = new(MockAuditRepository)
+	suite.tokenSvc = new(MockTokenService)
+	suite.throttle = new(MockLoginThrottle)
+	suite.hasher = security.NewPasswordHasher(bcrypt.MinCost)
+	suite.outbox = &outbox{}
+	suite.passwordSvc = services.NewPasswordService(suite.userRepo, suite.refreshRepo, suite.revokedRepo, suite.oneTimeRepo, suite.auditRepo, suite.tokenSvc, suite.hasher, suite.throttle, suite.outbox, 30*time.Minute)
+	suite.throttle.On("Release", mock.Anything, mock.Anything).Maybe()
+
+	hashed, err := suite.hasher.Hash("password123")
+	suite.Require().NoError(err)
+	suite.user = models.User{ID: "5", Username: "testuser", Email: "test@example.com", Password: hashed}
+
+	suite.tokenSvc.On("AccessTokenTTL").Return(15 * time.Minute).Maybe()
+	suite.auditRepo.On("Record", mock.Anything).Return(&models.AuditEvent{}, nil).Maybe()
+}
+
+func (suite *PasswordServiceTestSuite) principal() security.Principal {
+	return security.Principal{UserID: "5", Roles: []string{"customer"}, SessionID: "10"}
+}
+
+func (suite *PasswordServiceTestSuite) TestChangePasswordRevokesOtherSessions() {
+	suite.userRepo.On("FindByID", "5").Return(&suite.user, nil)
+	suite.throttle.On("Check", "testuser", "10.0.0.1").Return(nil)
+	var updated string
+	suite.userRepo.On("UpdatePassword", "5", mock.Anything).Run(func(args mock.Arguments) {
+		updated = args.String(1)
+	}).Return(nil)
+	suite.revokedRepo.On("Revoke", mock.MatchedBy(func(t models.RevokedToken) bool {
+		return t.JTI == "" && t.UserID == "5" && t.ExceptSessionID == "10"
+	})).Return(nil)
+	suite.refreshRepo.On("RevokeOtherFamilies", "5", "10").Return(nil)
+
+	err := suite.passwordSvc.ChangePassword(suite.principal(), request.ChangePasswordRequest{
+		CurrentPassword: "password123",
+		NewPassword:     "newpassword456",
+	}, "10.0.0.1")
+
+	assert.NoError(suite.T(), err)
+	match, _ := suite.hasher.Compare(updated, "newpassword456")
+	assert.True(suite.T(), match)
+	suite.revokedRepo.AssertExpectations(suite.T())
+	suite.refreshRepo.AssertExpectations(suite.T())
+	assert.Len(suite.T(), suite.outbox.sent, 1)
+}
+
+func (suite *PasswordServiceTestSuite) TestChangePasswordWrongCurrentPassword() {
+	suite.userRepo.On("FindByID", "5").Return(&suite.user, nil)
+	suite.throttle.On("Check", "testuser", "10.0.0.1").Return(nil)
+	suite.throttle.On("Failed", "testuser", "10.0.0.1").Return(nil)
+
+	err := suite.passwordSvc.ChangePassword(suite.principal(), request.ChangePasswordRequest{
+		CurrentPassword: "wrongpassword1",
+		NewPassword:     "newpassword456",
+	}, "10.0.0.1")
+
+	assert.ErrorIs(suite.T(), err, services.ErrIncorrectPassword)
+	suite.throttle.AssertCalled(suite.T(), "Failed", "testuser", "10.0.0.1")
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdatePassword", mock.Anything, mock.Anything)
+}
+
+func (suite *PasswordServiceTestSuite) TestChangePasswordEnforcesPolicy() {
+	err := suite.passwordSvc.ChangePassword(suite.principal(), request.ChangePasswordRequest{
+		CurrentPassword: "password123",
+		NewPassword:     "onlyletters",
+	}, "10.0.0.1")
+
+	var validationErrors validator.ValidationErrors
+	assert.True(suite.T(), errors.As(err, &validationErrors))
+	suite.userRepo.AssertNotCalled(suite.T(), "FindByID", mock.Anything)
+}
+
+func (suite *PasswordServiceTestSuite) TestRequestResetUnknownEmail() {
+	suite.userRepo.On("FindByEmail", "nobody@example.com").Return(nil, errors.New("user not found"))
+
+	err := suite.passwordSvc.RequestReset(request.ForgotPasswordRequest{Email: "nobody@example.com"})
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), suite.outbox.sent)
+	suite.oneTimeRepo.AssertNotCalled(suite.T(), "Create", mock.Anything)
+}
+
+func (suite *PasswordServiceTestSuite) TestRequestResetSendsToken() {
+	suite.userRepo.On("FindByEmail", "test@example.com").Return(&suite.user, nil)
+	suite.oneTimeRepo.On("DeleteByUserID", "5", models.PasswordResetToken).Return(nil)
+	var created models.OneTimeToken
+	suite.oneTimeRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(0).(models.OneTimeToken)
+	}).Return(nil)
+
+	err := suite.passwordSvc.RequestReset(request.ForgotPasswordRequest{Email: "test@example.com"})
+
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(suite.outbox.sent, 1)
+	sent := suite.outbox.sent[0]
+	assert.Equal(suite.T(), "test@example.com", sent.To)
+	assert.Equal(suite.T(), models.PasswordResetToken, created.Purpose)
+	assert.WithinDuration(suite.T(), time.Now().Add(30*time.Minute), created.ExpiresAt, time.Minute)
+
+	// Body berisi token mentah; yang tersimpan hanya hash-nya
+	token := strings.Fields(strings.SplitN(sent.Body, ": ", 2)[1])[0]
+	assert.Equal(suite.T(), security.HashOneTimeToken(token), created.TokenHash)
+}
+
+func (suite *PasswordServiceTestSuite) TestResetPasswordRevokesAllSessions() {
+	suite.oneTimeRepo.On("Consume", models.PasswordResetToken, security.HashOneTimeToken("reset-token")).
+		Return(&models.OneTimeToken{UserID: "5", Purpose: models.PasswordResetToken}, nil)
+	suite.userRepo.On("FindByID", "5").Return(&suite.user, nil)
+	suite.userRepo.On("UpdatePassword", "5", mock.Anything).Return(nil)
+	suite.revokedRepo.On("Revoke", mock.MatchedBy(func(t models.RevokedToken) bool {
+		return t.UserID == "5" && t.ExceptSessionID == ""
+	})).Return(nil)
+	suite.refreshRepo.On("RevokeByUserID", "5").Return(nil)
+
+	err := suite.passwordSvc.ResetPassword(request.ResetPasswordRequest{Token: "reset-token", NewPassword: "newpassword456"})
+
+	assert.NoError(suite.T(), err)
+	suite.revokedRepo.AssertExpectations(suite.T())
+	suite.refreshRepo.AssertExpectations(suite.T())
+}
+
+func (suite *PasswordServiceTestSuite) TestResetPasswordInvalidToken() {
+	suite.oneTimeRepo.On("Consume", models.PasswordResetToken, mock.Anything).Return(nil, errors.New("token not found"))
+
+	err := suite.passwordSvc.ResetPassword(request.ResetPasswordRequest{Token: "unknown", NewPassword: "newpassword456"})
+
+	assert.ErrorIs(suite.T(), err, services.ErrInvalidResetToken)
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdatePassword", mock.Anything, mock.Anything)
+}
+
+func TestPasswordServiceSuite(t *testing.T) {
+	suite.Run(t, new(PasswordServiceTestSuite))
+}
@@ -0,0 +1,156 @@
+package services_test
+
+import (
+	"errors"
+	"go-json/internal/dtos/request"
+	"go-json/internal/models"
+	"go-json/internal/security"
+	"go-json/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RoleServiceTestSuite struct {
+	suite.Suite
+	roleRepo  *MockRoleRepository
+	userRepo  *MockUserRepository
+	auditRepo *MockAuditRepository
+	roleSvc   services.RoleService
+	admin     security.Principal
+	support   models.Role
+}
+
+func (suite *RoleServiceTestSuite) SetupTest() {
+	suite.roleRepo = new(MockRoleRepository)
+	suite.userRepo = new(MockUserRepository)
+	suite.auditRepo = new(MockAuditRepository)
+	suite.roleSvc = services.NewRoleService(suite.roleRepo, suite.userRepo, suite.auditRepo)
+	suite.admin = adminViewer("9")
+	suite.support = models.Role{ID: "4", Name: "support", Permissions: []string{models.PermissionUserList}}
+}
+
+func (suite *RoleServiceTestSuite) expectAudit(action string) {
+	suite.auditRepo.On("Record", mock.MatchedBy(func(e models.AuditEvent) bool {
+		return e.ActorID == "9" && e.Action == action && e.Outcome == models.AuditAllowed
+	})).Return(&models.AuditEvent{ID: "1"}, nil).Once()
+}
+
+func (suite *RoleServiceTestSuite) TestCreateRole() {
+	suite.roleRepo.On("FindByRoleName", "support").Return(nil, errors.New("role not found"))
+	suite.roleRepo.On("CreateRole", models.Role{Name: "support", Permissions: []string{models.PermissionUserList}}).Return(&suite.support, nil)
+	suite.expectAudit(models.AuditCreateRole)
+
+	response, err := suite.roleSvc.CreateRole(suite.admin, request.CreateRoleRequest{
+		Name:        "Support",
+		Permissions: []string{models.PermissionUserList, models.PermissionUserList},
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "4", response.ID)
+	suite.roleRepo.AssertExpectations(suite.T())
+	suite.auditRepo.AssertExpectations(suite.T())
+}
+
+func (suite *RoleServiceTestSuite) TestCreateRoleUnknownPermission() {
+	response, err := suite.roleSvc.CreateRole(suite.admin, request.CreateRoleRequest{
+		Name:        "support",
+		Permissions: []string{"payment:steal"},
+	})
+
+	assert.ErrorIs(suite.T(), err, services.ErrUnknownPermission)
+	assert.Nil(suite.T(), response)
+	suite.roleRepo.AssertNotCalled(suite.T(), "CreateRole", mock.Anything)
+}
+
+func (suite *RoleServiceTestSuite) TestGrantAndRevokePermission() {
+	granted := suite.support
+	suite.roleRepo.On("FindByRoleID", "4").Return(&granted, nil).Once()
+	suite.roleRepo.On("UpdateRole", models.Role{ID: "4", Name: "support", Permissions: []string{models.PermissionUserList, models.PermissionHistoryReadAny}}).Return(nil)
+	suite.expectAudit(models.AuditGrantPermission)
+
+	response, err := suite.roleSvc.GrantPermissions(suite.admin, "4", request.RolePermissionsRequest{
+		Permissions: []string{models.PermissionUserList, models.PermissionHistoryReadAny},
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{models.PermissionUserList, models.PermissionHistoryReadAny}, response.Permissions)
+
+	revoked := suite.support
+	revoked.Permissions = []string{models.PermissionUserList}
+	suite.roleRepo.On("FindByRoleID", "4").Return(&revoked, nil).Once()
+	suite.roleRepo.On("UpdateRole", models.Role{ID: "4", Name: "support", Permissions: []string{}}).Return(nil)
+	suite.expectAudit(models.AuditRevokePermission)
+
+	response, err = suite.roleSvc.RevokePermission(suite.admin, "4", models.PermissionUserList)
+
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), response.Permissions)
+	suite.roleRepo.AssertExpectations(suite.T())
+	suite.auditRepo.AssertExpectations(suite.T())
+}
+
+func (suite *RoleServiceTestSuite) TestRevokeOwnRoleManagePermission() {
+	admin := models.Role{ID: "3", Name: "admin", Permissions: models.DefaultRolePermissions["admin"]}
+	suite.roleRepo.On("FindByRoleID", "3").Return(&admin, nil)
+
+	response, err := suite.roleSvc.RevokePermission(suite.admin, "3", models.PermissionRoleManage)
+
+	assert.ErrorIs(suite.T(), err, services.ErrSelfLockout)
+	assert.Nil(suite.T(), response)
+	suite.roleRepo.AssertNotCalled(suite.T(), "UpdateRole", mock.Anything)
+}
+
+func (suite *RoleServiceTestSuite) TestSetTwoFactorRequired() {
+	required := true
+	role := suite.support
+	suite.roleRepo.On("FindByRoleID", "4").Return(&role, nil)
+	suite.roleRepo.On("UpdateRole", mock.MatchedBy(func(r models.Role) bool {
+		return r.ID == "4" && r.RequireTwoFactor
+	})).Return(nil)
+	suite.expectAudit(models.AuditSetRoleTwoFactor)
+
+	response, err := suite.roleSvc.SetTwoFactorRequired(suite.admin, "4", request.RoleTwoFactorRequest{Required: &required})
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), response.RequireTwoFactor)
+	suite.roleRepo.AssertExpectations(suite.T())
+	suite.auditRepo.AssertExpectations(suite.T())
+}
+
+func (suite *RoleServiceTestSuite) TestAssignRole() {
+	suite.userRepo.On("FindByID", "5").Return(&models.User{ID: "5"}, nil)
+	suite.roleRepo.On("FindByRoleName", "support").Return(&suite.support, nil)
+	suite.roleRepo.On("FindRoleByUserID", "5").Return(&[]models.UserRole{{ID: "1", UserID: "5", RoleID: "2"}}, nil).Once()
+	suite.roleRepo.On("AssignRoles", "5", []string{"4"}).Return(nil)
+	suite.roleRepo.On("FindRoleByUserID", "5").Return(&[]models.UserRole{{ID: "1", UserID: "5", RoleID: "2"}, {ID: "2", UserID: "5", RoleID: "4"}}, nil)
+	suite.roleRepo.On("FindByRoleID", "2").Return(&models.Role{ID: "2", Name: "customer"}, nil)
+	suite.roleRepo.On("FindByRoleID", "4").Return(&suite.support, nil)
+	suite.expectAudit(models.AuditAssignRole)
+
+	response, err := suite.roleSvc.AssignRole(suite.admin, "5", request.AssignRoleRequest{Role: "support"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"customer", "support"}, response.Roles)
+
+	_, err = suite.roleSvc.AssignRole(suite.admin, "5", request.AssignRoleRequest{Role: "support"})
+	assert.ErrorIs(suite.T(), err, services.ErrRoleAlreadyAssigned)
+	suite.auditRepo.AssertExpectations(suite.T())
+}
+
+func (suite *RoleServiceTestSuite) TestUnassignOwnAdminRole() {
+	admin := models.Role{ID: "3", Name: "admin", Permissions: models.DefaultRolePermissions["admin"]}
+	suite.roleRepo.On("FindByRoleID", "3").Return(&admin, nil)
+
+	response, err := suite.roleSvc.UnassignRole(suite.admin, "9", "3")
+
+	assert.ErrorIs(suite.T(), err, services.ErrSelfLockout)
+	assert.Nil(suite.T(), response)
+	suite.roleRepo.AssertNotCalled(suite.T(), "RemoveRole", mock.Anything, mock.Anything)
+}
+
+func TestRoleServiceSuite(t *testing.T) {
+	suite.Run(t, new(RoleServiceTestSuite))
+}
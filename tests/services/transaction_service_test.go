@@ -2,8 +2,10 @@ package services_test
 
 import (
 	"errors"
+	"go-json/internal/bankrails"
 	"go-json/internal/dtos/request"
 	"go-json/internal/models"
+	"go-json/internal/security"
 	"go-json/internal/services"
 	"testing"
 	"time"
@@ -33,13 +35,186 @@ func (m *MockTransactionRepository) FindAllTransaction() ([]models.Transaction,
 	return args.Get(0).([]models.Transaction), args.Error(1)
 }
 
+func (m *MockTransactionRepository) FindByID(id string) (*models.Transaction, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) UpdateTransaction(transaction models.Transaction) error {
+	args := m.Called(transaction)
+	return args.Error(0)
+}
+
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Record(event models.AuditEvent) (*models.AuditEvent, error) {
+	args := m.Called(event)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AuditEvent), args.Error(1)
+}
+
+func (m *MockAuditRepository) FindAll() ([]models.AuditEvent, error) {
+	args := m.Called()
+	return args.Get(0).([]models.AuditEvent), args.Error(1)
+}
+
+type MockLedgerService struct {
+	mock.Mock
+}
+
+func (m *MockLedgerService) Transfer(fromUserID, toUserID string, amount models.Money, description string) (*models.JournalEntry, error) {
+	args := m.Called(fromUserID, toUserID, amount, description)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerService) TransferWithConversion(fromUserID, toUserID string, debit, credit models.Money, description string) (*models.JournalEntry, error) {
+	args := m.Called(fromUserID, toUserID, debit, credit, description)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerService) Hold(userID string, amount models.Money, description string) (*models.JournalEntry, error) {
+	args := m.Called(userID, amount, description)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerService) ReleaseHold(userID string, amount models.Money, reference, description string) (*models.JournalEntry, error) {
+	args := m.Called(userID, amount, reference, description)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerService) CaptureHold(fromUserID, toUserID string, amount models.Money, description string) (*models.JournalEntry, error) {
+	args := m.Called(fromUserID, toUserID, amount, description)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerService) Deposit(userID string, amount models.Money, reference, description string) (*models.JournalEntry, error) {
+	args := m.Called(userID, amount, reference, description)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerService) PayoutHold(userID string, amount models.Money, reference, description string) (*models.JournalEntry, error) {
+	args := m.Called(userID, amount, reference, description)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerService) FundSandbox(userID string, amount models.Money) (*models.JournalEntry, error) {
+	args := m.Called(userID, amount)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+// MockBankRail langsung mengonfirmasi bila Return diberi *bankrails.Confirmation, selain itu transfernya tetap pending
+type MockBankRail struct {
+	mock.Mock
+}
+
+func (m *MockBankRail) Submit(instruction bankrails.Instruction, confirm func(bankrails.Confirmation)) error {
+	args := m.Called(instruction)
+	if confirmation, ok := args.Get(1).(*bankrails.Confirmation); ok {
+		confirm(*confirmation)
+	}
+	return args.Error(0)
+}
+
+func (m *MockLedgerService) PostEntry(entry models.JournalEntry) (*models.JournalEntry, error) {
+	args := m.Called(entry)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerService) EntryByReference(reference string) (*models.JournalEntry, error) {
+	args := m.Called(reference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.JournalEntry), args.Error(1)
+}
+
+func (m *MockLedgerService) WalletAccount(userID string, currency models.Currency) (*models.LedgerAccount, error) {
+	args := m.Called(userID, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LedgerAccount), args.Error(1)
+}
+
+func (m *MockLedgerService) Balance(userID string, currency models.Currency) (models.Money, error) {
+	args := m.Called(userID, currency)
+	return args.Get(0).(models.Money), args.Error(1)
+}
+
+func (m *MockLedgerService) Balances(userID string) ([]models.Money, []models.Money, error) {
+	args := m.Called(userID)
+	wallets, _ := args.Get(0).([]models.Money)
+	held, _ := args.Get(1).([]models.Money)
+	return wallets, held, args.Error(2)
+}
+
+func (m *MockLedgerService) Reconcile() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func idr(amount string) models.Money {
+	return models.MustParseMoney(amount, models.DefaultCurrency)
+}
+
+func customerViewer(userID string) security.Principal {
+	return security.Principal{UserID: userID, Roles: []string{"customer"}, Permissions: models.DefaultRolePermissions["customer"]}
+}
+
+func merchantViewer(userID string) security.Principal {
+	return security.Principal{UserID: userID, Roles: []string{"merchant"}, Permissions: models.DefaultRolePermissions["merchant"]}
+}
+
+func adminViewer(userID string) security.Principal {
+	return security.Principal{UserID: userID, Roles: []string{"admin"}, Permissions: models.DefaultRolePermissions["admin"]}
+}
+
 type TransactionServiceTestSuite struct {
 	suite.Suite
 	userRepo        *MockUserRepository
 	transactionRepo *MockTransactionRepository
 	roleRepo        *MockRoleRepository
+	merchantRepo    *MockMerchantRepository
+	ledgerSvc       *MockLedgerService
+	auditRepo       *MockAuditRepository
+	bankRail        *MockBankRail
 	transactionSvc  services.TransactionService
 	testUser        models.User
+	testMerchant    models.User
 	testUserRoles   []models.UserRole
 	testTransaction models.Transaction
 }
@@ -48,17 +223,36 @@ func (suite *TransactionServiceTestSuite) SetupTest() {
 	suite.userRepo = new(MockUserRepository)
 	suite.transactionRepo = new(MockTransactionRepository)
 	suite.roleRepo = new(MockRoleRepository)
-	suite.transactionSvc = services.NewTransactionService(suite.userRepo, suite.transactionRepo, suite.roleRepo)
+	suite.merchantRepo = new(MockMerchantRepository)
+	suite.ledgerSvc = new(MockLedgerService)
+	suite.auditRepo = new(MockAuditRepository)
+	suite.bankRail = new(MockBankRail)
+	fx, err := services.NewFXRateProvider([]models.FXRate{
+		{From: "USD", To: "IDR", Rate: "16250", EffectiveFrom: time.Now().Add(-time.Hour)},
+	})
+	suite.Require().NoError(err)
+	suite.transactionSvc = services.NewTransactionService(suite.userRepo, suite.transactionRepo, suite.roleRepo, suite.merchantRepo, suite.ledgerSvc, fx, suite.auditRepo, time.Hour, suite.bankRail, models.TransferLimits{PerTransfer: idr("1000"), Daily: idr("1500")})
 
 	suite.testUser = models.User{
 		ID:       "1",
 		Username: "testuser",
 		Email:    "test@example.com",
 		Password: "password123",
-		Balance:  1000.0,
-		IsActive: true,
+		Balance:  idr("1000"),
+		Status:   models.AccountActive,
 	}
 
+	suite.testMerchant = models.User{
+		ID:       "2",
+		Username: "merchant1",
+		Email:    "merchant@example.com",
+		Balance:  idr("0"),
+		Status:   models.AccountActive,
+	}
+
+	suite.merchantRepo.On("FindByID", "2").Return(&models.Merchant{ID: "2", Status: models.MerchantActive, OwnerIDs: []string{"2"}}, nil).Maybe()
+	suite.merchantRepo.On("FindByOwnerID", mock.Anything).Return([]models.Merchant{}, nil).Maybe()
+
 	suite.testUserRoles = []models.UserRole{
 		{
 			ID:     "1",
@@ -74,7 +268,8 @@ func (suite *TransactionServiceTestSuite) SetupTest() {
 		ActivityType: models.PaymentActivity,
 		Timestamp:    time.Now(),
 		Details:      "Test transaction",
-		Amount:       100.0,
+		Amount:       idr("100"),
+		Status:       models.TransactionSucceeded,
 	}
 }
 
@@ -82,20 +277,17 @@ func (suite *TransactionServiceTestSuite) TestProcessPaymentSuccess() {
 	paymentReq := request.PaymentRequest{
 		CustomerID: "1",
 		MerchantID: "2",
-		Amount:     500.0,
+		Amount:     idr("500"),
 	}
 
-	updatedUser := suite.testUser
-	updatedUser.Balance -= paymentReq.Amount
 	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
-	suite.userRepo.On("UpdateUser", mock.AnythingOfType("models.User")).Return(nil)
-
-	suite.roleRepo.On("FindRoleByUserID", "1").Return(&suite.testUserRoles, nil)
+	suite.userRepo.On("FindByID", "2").Return(&suite.testMerchant, nil)
+	suite.ledgerSvc.On("Transfer", "1", "2", idr("500"), mock.AnythingOfType("string")).Return(&models.JournalEntry{ID: "7"}, nil)
 
 	expectedTransaction := models.Transaction{
 		CustomerID:   "1",
 		MerchantID:   "2",
-		Amount:       500.0,
+		Amount:       idr("500"),
 		ActivityType: models.PaymentActivity,
 		Details:      "Payment processed successfully",
 	}
@@ -104,28 +296,31 @@ func (suite *TransactionServiceTestSuite) TestProcessPaymentSuccess() {
 		return t.CustomerID == expectedTransaction.CustomerID &&
 			t.MerchantID == expectedTransaction.MerchantID &&
 			t.Amount == expectedTransaction.Amount &&
-			t.ActivityType == expectedTransaction.ActivityType
+			t.ActivityType == expectedTransaction.ActivityType &&
+			t.JournalEntryID == "7" &&
+			t.Status == models.TransactionSucceeded &&
+			len(t.StatusHistory) == 2 && t.StatusHistory[0].Status == models.TransactionPending
 	})).Return(&models.Transaction{
 		ID:           "1",
 		CustomerID:   "1",
 		MerchantID:   "2",
-		Amount:       500.0,
+		Amount:       idr("500"),
 		ActivityType: models.PaymentActivity,
 		Details:      "Payment processed successfully",
 		Timestamp:    time.Now(),
 	}, nil)
 
-	response, err := suite.transactionSvc.ProcessPayment(paymentReq)
+	response, err := suite.transactionSvc.ProcessPayment(paymentReq.CustomerID, paymentReq)
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), response)
 	assert.Equal(suite.T(), "1", response.ID)
 	assert.Equal(suite.T(), "1", response.CustomerID)
 	assert.Equal(suite.T(), "2", response.MerchantID)
-	assert.Equal(suite.T(), 500.0, response.Amount)
+	assert.Equal(suite.T(), idr("500"), response.Amount)
 
 	suite.userRepo.AssertExpectations(suite.T())
-	suite.roleRepo.AssertExpectations(suite.T())
+	suite.ledgerSvc.AssertExpectations(suite.T())
 	suite.transactionRepo.AssertExpectations(suite.T())
 }
 
@@ -133,7 +328,7 @@ func (suite *TransactionServiceTestSuite) TestProcessPaymentInsufficientBalance(
 	paymentReq := request.PaymentRequest{
 		CustomerID: "1",
 		MerchantID: "2",
-		Amount:     2000.0, // More than user's balance
+		Amount:     idr("2000"), // More than user's balance
 	}
 
 	// Setup user repo mock
@@ -143,21 +338,21 @@ func (suite *TransactionServiceTestSuite) TestProcessPaymentInsufficientBalance(
 	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
 		return t.CustomerID == "1" &&
 			t.MerchantID == "2" &&
-			t.Amount == 2000.0 &&
+			t.Amount == idr("2000") &&
 			t.ActivityType == models.FailedPayment &&
 			t.Details == "Insufficient balance"
 	})).Return(&models.Transaction{
 		ID:           "1",
 		CustomerID:   "1",
 		MerchantID:   "2",
-		Amount:       2000.0,
+		Amount:       idr("2000"),
 		ActivityType: models.FailedPayment,
 		Details:      "Insufficient balance",
 		Timestamp:    time.Now(),
 	}, nil)
 
 	// Test
-	response, err := suite.transactionSvc.ProcessPayment(paymentReq)
+	response, err := suite.transactionSvc.ProcessPayment(paymentReq.CustomerID, paymentReq)
 
 	// Verify
 	assert.Error(suite.T(), err)
@@ -172,7 +367,7 @@ func (suite *TransactionServiceTestSuite) TestProcessPaymentInvalidCustomer() {
 	paymentReq := request.PaymentRequest{
 		CustomerID: "999", // Non-existent customer
 		MerchantID: "2",
-		Amount:     500.0,
+		Amount:     idr("500"),
 	}
 
 	// Setup user repo mock
@@ -182,21 +377,21 @@ func (suite *TransactionServiceTestSuite) TestProcessPaymentInvalidCustomer() {
 	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
 		return t.CustomerID == "999" &&
 			t.MerchantID == "2" &&
-			t.Amount == 500.0 &&
+			t.Amount == idr("500") &&
 			t.ActivityType == models.FailedPayment &&
 			t.Details == "Invalid customer ID"
 	})).Return(&models.Transaction{
 		ID:           "1",
 		CustomerID:   "999",
 		MerchantID:   "2",
-		Amount:       500.0,
+		Amount:       idr("500"),
 		ActivityType: models.FailedPayment,
 		Details:      "Invalid customer ID",
 		Timestamp:    time.Now(),
 	}, nil)
 
 	// Test
-	response, err := suite.transactionSvc.ProcessPayment(paymentReq)
+	response, err := suite.transactionSvc.ProcessPayment(paymentReq.CustomerID, paymentReq)
 
 	// Verify
 	assert.Error(suite.T(), err)
@@ -207,99 +402,194 @@ func (suite *TransactionServiceTestSuite) TestProcessPaymentInvalidCustomer() {
 	suite.transactionRepo.AssertExpectations(suite.T())
 }
 
-func (suite *TransactionServiceTestSuite) TestProcessPaymentInactiveCustomer() {
+func (suite *TransactionServiceTestSuite) TestProcessPaymentUnverifiedCustomer() {
 	paymentReq := request.PaymentRequest{
 		CustomerID: "1",
 		MerchantID: "2",
-		Amount:     500.0,
+		Amount:     idr("500"),
 	}
 
-	// Create inactive user
-	inactiveUser := suite.testUser
-	inactiveUser.IsActive = false
+	// Create user whose email is not verified yet
+	unverifiedUser := suite.testUser
+	unverifiedUser.Status = models.AccountPending
 
 	// Setup user repo mock
-	suite.userRepo.On("FindByID", "1").Return(&inactiveUser, nil)
+	suite.userRepo.On("FindByID", "1").Return(&unverifiedUser, nil)
 
 	// Setup transaction repo mock - should record a failed payment
 	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
 		return t.CustomerID == "1" &&
 			t.MerchantID == "2" &&
-			t.Amount == 500.0 &&
+			t.Amount == idr("500") &&
 			t.ActivityType == models.FailedPayment &&
-			t.Details == "Customer is not active"
+			t.Details == "Customer email is not verified"
 	})).Return(&models.Transaction{
 		ID:           "1",
 		CustomerID:   "1",
 		MerchantID:   "2",
-		Amount:       500.0,
+		Amount:       idr("500"),
 		ActivityType: models.FailedPayment,
-		Details:      "Customer is not active",
+		Details:      "Customer email is not verified",
 		Timestamp:    time.Now(),
 	}, nil)
 
 	// Test
-	response, err := suite.transactionSvc.ProcessPayment(paymentReq)
+	response, err := suite.transactionSvc.ProcessPayment(paymentReq.CustomerID, paymentReq)
 
 	// Verify
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), response)
-	assert.Equal(suite.T(), "customer is not active", err.Error())
+	assert.ErrorIs(suite.T(), err, services.ErrAccountNotVerified)
 
 	suite.userRepo.AssertExpectations(suite.T())
 	suite.transactionRepo.AssertExpectations(suite.T())
 }
 
-func (suite *TransactionServiceTestSuite) TestProcessPaymentMerchantRole() {
+func (suite *TransactionServiceTestSuite) TestProcessPaymentSuspendedCustomer() {
 	paymentReq := request.PaymentRequest{
 		CustomerID: "1",
 		MerchantID: "2",
-		Amount:     500.0,
+		Amount:     idr("500"),
 	}
 
-	// Setup user with merchant role
-	merchantRoles := []models.UserRole{
-		{
-			ID:     "1",
-			UserID: "1",
-			RoleID: "1", // merchant role
-		},
+	suspendedUser := suite.testUser
+	suspendedUser.Status = models.AccountSuspended
+
+	suite.userRepo.On("FindByID", "1").Return(&suspendedUser, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedPayment && t.Details == "Customer account is suspended"
+	})).Return(&models.Transaction{}, nil)
+
+	response, err := suite.transactionSvc.ProcessPayment(paymentReq.CustomerID, paymentReq)
+
+	assert.Nil(suite.T(), response)
+	assert.ErrorIs(suite.T(), err, services.ErrAccountSuspended)
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestProcessPaymentMerchantPayerIsDebited() {
+	paymentReq := request.PaymentRequest{
+		CustomerID: "1",
+		MerchantID: "2",
+		Amount:     idr("500"),
 	}
 
-	// Setup user repo mock
+	// Payer yang juga merchant tetap didebit; saldo tidak lagi dikembalikan ke payer
 	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
-	suite.userRepo.On("UpdateUser", mock.AnythingOfType("models.User")).Return(nil)
-
-	// Setup role repo mock
-	suite.roleRepo.On("FindRoleByUserID", "1").Return(&merchantRoles, nil)
+	suite.userRepo.On("FindByID", "2").Return(&suite.testMerchant, nil)
+	suite.ledgerSvc.On("Transfer", "1", "2", idr("500"), mock.AnythingOfType("string")).Return(&models.JournalEntry{ID: "1"}, nil)
 
-	// Setup transaction repo mock
 	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
 		return t.CustomerID == "1" &&
 			t.MerchantID == "2" &&
-			t.Amount == 500.0 &&
+			t.Amount == idr("500") &&
 			t.ActivityType == models.PaymentActivity
 	})).Return(&models.Transaction{
 		ID:           "1",
 		CustomerID:   "1",
 		MerchantID:   "2",
-		Amount:       500.0,
+		Amount:       idr("500"),
 		ActivityType: models.PaymentActivity,
 		Details:      "Payment processed successfully",
 		Timestamp:    time.Now(),
 	}, nil)
 
-	// Test
-	response, err := suite.transactionSvc.ProcessPayment(paymentReq)
+	response, err := suite.transactionSvc.ProcessPayment(paymentReq.CustomerID, paymentReq)
 
-	// Verify
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), response)
-	// Merchant should see both IDs
 	assert.Equal(suite.T(), "2", response.MerchantID)
 
-	suite.userRepo.AssertExpectations(suite.T())
-	suite.roleRepo.AssertExpectations(suite.T())
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdateBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.ledgerSvc.AssertExpectations(suite.T())
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestProcessPaymentChargesOnlyAuthenticatedCustomer() {
+	paymentReq := request.PaymentRequest{
+		CustomerID: "3",
+		MerchantID: "2",
+		Amount:     idr("500"),
+	}
+
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.CustomerID == "1" && t.ActivityType == models.FailedPayment
+	})).Return(&models.Transaction{ID: "1"}, nil)
+
+	response, err := suite.transactionSvc.ProcessPayment("1", paymentReq)
+
+	assert.ErrorIs(suite.T(), err, services.ErrCustomerMismatch)
+	assert.Nil(suite.T(), response)
+	suite.userRepo.AssertNotCalled(suite.T(), "FindByID", "3")
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestProcessPaymentOnBehalfRecordsActor() {
+	paymentReq := request.PaymentRequest{
+		CustomerID: "1",
+		MerchantID: "2",
+		Amount:     idr("500"),
+	}
+
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.userRepo.On("FindByID", "2").Return(&suite.testMerchant, nil)
+	suite.ledgerSvc.On("Transfer", "1", "2", idr("500"), mock.AnythingOfType("string")).Return(&models.JournalEntry{ID: "7"}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.CustomerID == "1" && t.InitiatedBy == "9" && t.ActivityType == models.PaymentActivity
+	})).Return(&models.Transaction{ID: "1", CustomerID: "1", MerchantID: "2", InitiatedBy: "9"}, nil)
+
+	response, err := suite.transactionSvc.ProcessPaymentOnBehalf("9", paymentReq)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "9", response.InitiatedBy)
+	suite.transactionRepo.AssertExpectations(suite.T())
+
+	_, err = suite.transactionSvc.ProcessPaymentOnBehalf("9", request.PaymentRequest{MerchantID: "2", Amount: idr("500")})
+	assert.ErrorIs(suite.T(), err, services.ErrCustomerRequired)
+}
+
+func (suite *TransactionServiceTestSuite) TestProcessPaymentInvalidMerchant() {
+	paymentReq := request.PaymentRequest{
+		CustomerID: "1",
+		MerchantID: "999",
+		Amount:     idr("500"),
+	}
+
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.merchantRepo.On("FindByID", "999").Return(nil, errors.New("merchant not found"))
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedPayment && t.Details == "Invalid merchant ID"
+	})).Return(&models.Transaction{ID: "1"}, nil)
+
+	response, err := suite.transactionSvc.ProcessPayment(paymentReq.CustomerID, paymentReq)
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), response)
+	assert.Equal(suite.T(), "invalid merchant ID", err.Error())
+
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestProcessPaymentPendingMerchant() {
+	paymentReq := request.PaymentRequest{
+		CustomerID: "1",
+		MerchantID: "3",
+		Amount:     idr("500"),
+	}
+
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.merchantRepo.On("FindByID", "3").Return(&models.Merchant{ID: "3", Status: models.MerchantPending, OwnerIDs: []string{"3"}}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedPayment && t.Details == "Merchant is pending"
+	})).Return(&models.Transaction{ID: "1"}, nil)
+
+	response, err := suite.transactionSvc.ProcessPayment(paymentReq.CustomerID, paymentReq)
+
+	assert.ErrorIs(suite.T(), err, services.ErrMerchantNotActive)
+	assert.Nil(suite.T(), response)
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	suite.transactionRepo.AssertExpectations(suite.T())
 }
 
@@ -313,7 +603,7 @@ func (suite *TransactionServiceTestSuite) TestTransactionHistoryByUserID() {
 			ActivityType: models.PaymentActivity,
 			Timestamp:    time.Now(),
 			Details:      "Another transaction",
-			Amount:       200.0,
+			Amount:       idr("200"),
 		},
 		{
 			ID:           "3",
@@ -322,7 +612,7 @@ func (suite *TransactionServiceTestSuite) TestTransactionHistoryByUserID() {
 			ActivityType: models.PaymentActivity,
 			Timestamp:    time.Now(),
 			Details:      "Different customer",
-			Amount:       300.0,
+			Amount:       idr("300"),
 		},
 	}
 
@@ -331,13 +621,14 @@ func (suite *TransactionServiceTestSuite) TestTransactionHistoryByUserID() {
 	suite.transactionRepo.On("FindAllTransaction").Return(transactions, nil)
 
 	// Test
-	response, err := suite.transactionSvc.TransactionHistoryByUserID("1")
+	response, err := suite.transactionSvc.TransactionHistoryByUserID(customerViewer("1"), "1")
 
 	// Verify
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), response)
 	assert.Len(suite.T(), response, 1) // One user's history
 	assert.Equal(suite.T(), "testuser", response[0].User.Username)
+	assert.Equal(suite.T(), "test@example.com", response[0].User.Email)
 	assert.Len(suite.T(), response[0].Transactions, 2) // Two transactions for this user
 	assert.Equal(suite.T(), 2, response[0].TotalCount)
 
@@ -345,12 +636,69 @@ func (suite *TransactionServiceTestSuite) TestTransactionHistoryByUserID() {
 	suite.transactionRepo.AssertExpectations(suite.T())
 }
 
+func (suite *TransactionServiceTestSuite) TestTransactionHistoryCustomerCannotReadOthers() {
+	suite.auditRepo.On("Record", mock.MatchedBy(func(e models.AuditEvent) bool {
+		return e.ActorID == "3" && e.Resource == "user:1" && e.Outcome == models.AuditDenied
+	})).Return(&models.AuditEvent{ID: "1"}, nil)
+
+	response, err := suite.transactionSvc.TransactionHistoryByUserID(customerViewer("3"), "1")
+
+	assert.ErrorIs(suite.T(), err, services.ErrHistoryAccessDenied)
+	assert.Nil(suite.T(), response)
+	suite.userRepo.AssertNotCalled(suite.T(), "FindByID", "1")
+	suite.auditRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestTransactionHistoryMerchantSeesOnlyOwnSales() {
+	transactions := []models.Transaction{
+		suite.testTransaction,
+		{ID: "2", CustomerID: "1", MerchantID: "3", ActivityType: models.PaymentActivity, Amount: idr("200")},
+	}
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return(transactions, nil)
+
+	response, err := suite.transactionSvc.TransactionHistoryByUserID(merchantViewer("2"), "1")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), response[0].Transactions, 1)
+	assert.Equal(suite.T(), "1", response[0].Transactions[0].ID)
+	assert.Empty(suite.T(), response[0].User.Email)
+	assert.Nil(suite.T(), response[0].User.Balance)
+
+	// Merchant lain yang tidak punya transaksi dengan user ini ditolak
+	suite.auditRepo.On("Record", mock.MatchedBy(func(e models.AuditEvent) bool {
+		return e.ActorID == "4" && e.Outcome == models.AuditDenied
+	})).Return(&models.AuditEvent{ID: "1"}, nil)
+
+	response, err = suite.transactionSvc.TransactionHistoryByUserID(merchantViewer("4"), "1")
+
+	assert.ErrorIs(suite.T(), err, services.ErrHistoryAccessDenied)
+	assert.Nil(suite.T(), response)
+	suite.auditRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestTransactionHistoryAdminSeesEverything() {
+	transactions := []models.Transaction{
+		suite.testTransaction,
+		{ID: "2", CustomerID: "1", MerchantID: "3", ActivityType: models.PaymentActivity, Amount: idr("200")},
+		{ID: "3", CustomerID: "5", MerchantID: "1", ActivityType: models.PaymentActivity, Amount: idr("300")},
+	}
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return(transactions, nil)
+
+	response, err := suite.transactionSvc.TransactionHistoryByUserID(adminViewer("9"), "1")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 3, response[0].TotalCount)
+	assert.Equal(suite.T(), idr("1000"), *response[0].User.Balance)
+}
+
 func (suite *TransactionServiceTestSuite) TestTransactionHistoryUserNotFound() {
 	// Setup mocks
 	suite.userRepo.On("FindByID", "999").Return(nil, errors.New("user not found"))
 
 	// Test
-	response, err := suite.transactionSvc.TransactionHistoryByUserID("999")
+	response, err := suite.transactionSvc.TransactionHistoryByUserID(adminViewer("9"), "999")
 
 	// Verify
 	assert.Error(suite.T(), err)
@@ -360,6 +708,781 @@ func (suite *TransactionServiceTestSuite) TestTransactionHistoryUserNotFound() {
 	suite.userRepo.AssertExpectations(suite.T())
 }
 
+func (suite *TransactionServiceTestSuite) TestRefundPaymentPartial() {
+	previousRefund := models.Transaction{
+		ID:                    "2",
+		CustomerID:            "1",
+		MerchantID:            "2",
+		ActivityType:          models.RefundActivity,
+		Amount:                idr("30"),
+		OriginalTransactionID: "1",
+	}
+
+	suite.transactionRepo.On("FindByID", "1").Return(&suite.testTransaction, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{suite.testTransaction, previousRefund}, nil)
+	suite.ledgerSvc.On("Transfer", "2", "1", idr("50"), "Refund of transaction 1: damaged item").Return(&models.JournalEntry{ID: "9"}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.RefundActivity && t.OriginalTransactionID == "1" && t.Amount == idr("50") && t.JournalEntryID == "9"
+	})).Return(&models.Transaction{ID: "3", ActivityType: models.RefundActivity, OriginalTransactionID: "1", Amount: idr("50")}, nil)
+	suite.transactionRepo.On("UpdateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ID == "1" && t.Status == models.TransactionPartiallyRefunded
+	})).Return(nil)
+
+	response, err := suite.transactionSvc.RefundPayment("1", "2", request.RefundRequest{Amount: idr("50"), Reason: "damaged item"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "3", response.ID)
+	assert.Equal(suite.T(), idr("80"), response.RefundedAmount)
+	assert.Equal(suite.T(), idr("20"), response.RefundableAmount)
+	suite.ledgerSvc.AssertExpectations(suite.T())
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestRefundPaymentFullByDefault() {
+	suite.transactionRepo.On("FindByID", "1").Return(&suite.testTransaction, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{suite.testTransaction}, nil)
+	suite.ledgerSvc.On("Transfer", "2", "1", idr("100"), "Refund of transaction 1").Return(&models.JournalEntry{ID: "9"}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.RefundActivity && t.Amount == idr("100")
+	})).Return(&models.Transaction{ID: "2", ActivityType: models.RefundActivity, OriginalTransactionID: "1", Amount: idr("100")}, nil)
+	suite.transactionRepo.On("UpdateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ID == "1" && t.Status == models.TransactionRefunded
+	})).Return(nil)
+
+	response, err := suite.transactionSvc.RefundPayment("1", "2", request.RefundRequest{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), idr("100"), response.RefundedAmount)
+	assert.Equal(suite.T(), idr("0"), response.RefundableAmount)
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestRefundPaymentExceedsOriginal() {
+	previousRefund := models.Transaction{
+		ID:                    "2",
+		ActivityType:          models.RefundActivity,
+		Amount:                idr("80"),
+		OriginalTransactionID: "1",
+	}
+
+	suite.transactionRepo.On("FindByID", "1").Return(&suite.testTransaction, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{suite.testTransaction, previousRefund}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedRefund && t.OriginalTransactionID == "1" &&
+			t.Status == models.TransactionFailed && t.FailureReason == models.FailureExceedsRefundable
+	})).Return(&models.Transaction{ID: "3"}, nil)
+
+	response, err := suite.transactionSvc.RefundPayment("1", "2", request.RefundRequest{Amount: idr("30")})
+
+	assert.ErrorIs(suite.T(), err, services.ErrRefundExceedsPayment)
+	assert.Nil(suite.T(), response)
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestRefundPaymentOtherMerchant() {
+	suite.transactionRepo.On("FindByID", "1").Return(&suite.testTransaction, nil)
+
+	response, err := suite.transactionSvc.RefundPayment("1", "3", request.RefundRequest{Amount: idr("10")})
+
+	assert.ErrorIs(suite.T(), err, services.ErrRefundNotAllowed)
+	assert.Nil(suite.T(), response)
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TransactionServiceTestSuite) TestProcessPaymentConvertsCurrency() {
+	usd := models.MustParseMoney("0.05", "USD")
+	paymentReq := request.PaymentRequest{
+		CustomerID:     "1",
+		MerchantID:     "2",
+		Amount:         usd,
+		SourceCurrency: "IDR",
+	}
+
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.userRepo.On("FindByID", "2").Return(&suite.testMerchant, nil)
+	suite.ledgerSvc.On("TransferWithConversion", "1", "2", idr("812.50"), usd, mock.AnythingOfType("string")).Return(&models.JournalEntry{ID: "7"}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.PaymentActivity &&
+			t.Amount == usd &&
+			*t.SourceAmount == idr("812.50") &&
+			*t.ExchangeRate == models.ExchangeRate{From: "USD", To: "IDR", Rate: "16250"}
+	})).Return(&models.Transaction{ID: "1", Amount: usd, SourceAmount: &models.Money{}}, nil)
+
+	_, err := suite.transactionSvc.ProcessPayment(paymentReq.CustomerID, paymentReq)
+
+	assert.NoError(suite.T(), err)
+	suite.ledgerSvc.AssertExpectations(suite.T())
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestProcessPaymentWithoutExchangeRate() {
+	paymentReq := request.PaymentRequest{
+		CustomerID:     "1",
+		MerchantID:     "2",
+		Amount:         models.MustParseMoney("5", "EUR"),
+		SourceCurrency: "IDR",
+	}
+
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedPayment && t.Details == "Exchange rate not available"
+	})).Return(&models.Transaction{ID: "1"}, nil)
+
+	response, err := suite.transactionSvc.ProcessPayment(paymentReq.CustomerID, paymentReq)
+
+	assert.ErrorIs(suite.T(), err, services.ErrExchangeRateNotFound)
+	assert.Nil(suite.T(), response)
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "TransferWithConversion", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TransactionServiceTestSuite) TestRefundConvertedPaymentReturnsExactSourceAmount() {
+	rate := models.ExchangeRate{From: "USD", To: "IDR", Rate: "16250"}
+	source := idr("812.50")
+	original := models.Transaction{
+		ID:           "1",
+		CustomerID:   "1",
+		MerchantID:   "2",
+		ActivityType: models.PaymentActivity,
+		Amount:       models.MustParseMoney("0.05", "USD"),
+		SourceAmount: &source,
+		ExchangeRate: &rate,
+		Status:       models.TransactionSucceeded,
+	}
+	previousSource := idr("162.50")
+	previousRefund := models.Transaction{
+		ID:                    "2",
+		ActivityType:          models.RefundActivity,
+		Amount:                models.MustParseMoney("0.01", "USD"),
+		SourceAmount:          &previousSource,
+		OriginalTransactionID: "1",
+	}
+	remaining := models.MustParseMoney("0.04", "USD")
+
+	suite.transactionRepo.On("FindByID", "1").Return(&original, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{original, previousRefund}, nil)
+	suite.ledgerSvc.On("TransferWithConversion", "2", "1", remaining, idr("650"), "Refund of transaction 1").Return(&models.JournalEntry{ID: "9"}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.RefundActivity && *t.SourceAmount == idr("650") && *t.ExchangeRate == rate
+	})).Return(&models.Transaction{ID: "3", ActivityType: models.RefundActivity, Amount: remaining}, nil)
+	suite.transactionRepo.On("UpdateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ID == "1" && t.Status == models.TransactionRefunded
+	})).Return(nil)
+
+	response, err := suite.transactionSvc.RefundPayment("1", "2", request.RefundRequest{})
+
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), response.RefundableAmount.IsZero())
+	suite.ledgerSvc.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) authorization(amount string, expiresAt time.Time) models.Transaction {
+	return models.Transaction{
+		ID:           "10",
+		CustomerID:   "1",
+		MerchantID:   "2",
+		ActivityType: models.AuthorizationActivity,
+		Amount:       idr(amount),
+		Timestamp:    expiresAt.Add(-time.Hour),
+		ExpiresAt:    &expiresAt,
+		Status:       models.TransactionPending,
+	}
+}
+
+func (suite *TransactionServiceTestSuite) TestAuthorizePaymentHoldsFunds() {
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.userRepo.On("FindByID", "2").Return(&suite.testMerchant, nil)
+	suite.ledgerSvc.On("Hold", "1", idr("300"), "Authorization for merchant 2").Return(&models.JournalEntry{ID: "7"}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.AuthorizationActivity && t.JournalEntryID == "7" &&
+			t.Status == models.TransactionPending && t.ExpiresAt != nil && t.ExpiresAt.Sub(t.Timestamp) == time.Hour
+	})).Return(&models.Transaction{ID: "10", CustomerID: "1", MerchantID: "2", ActivityType: models.AuthorizationActivity, Amount: idr("300")}, nil)
+
+	authorization, err := suite.transactionSvc.AuthorizePayment("1", request.AuthorizationRequest{MerchantID: "2", Amount: idr("300")})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "10", authorization.AuthorizationID)
+	assert.Equal(suite.T(), idr("300"), authorization.HeldAmount)
+	assert.Equal(suite.T(), models.AuthorizationOpen, authorization.Status)
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TransactionServiceTestSuite) TestAuthorizePaymentInsufficientBalance() {
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.userRepo.On("FindByID", "2").Return(&suite.testMerchant, nil)
+	suite.ledgerSvc.On("Hold", "1", idr("5000"), mock.Anything).Return(nil, services.ErrInsufficientBalance)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedAuthorization && t.Details == "Insufficient balance" &&
+			t.FailureReason == models.FailureInsufficientBalance
+	})).Return(&models.Transaction{ID: "1"}, nil)
+
+	authorization, err := suite.transactionSvc.AuthorizePayment("1", request.AuthorizationRequest{MerchantID: "2", Amount: idr("5000")})
+
+	assert.ErrorIs(suite.T(), err, services.ErrInsufficientBalance)
+	assert.Nil(suite.T(), authorization)
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestCapturePartialKeepsAuthorizationOpen() {
+	authorization := suite.authorization("500", time.Now().Add(time.Hour))
+	suite.transactionRepo.On("FindByID", "10").Return(&authorization, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{
+		authorization,
+		{ID: "11", ActivityType: models.CaptureActivity, Amount: idr("100"), OriginalTransactionID: "10"},
+	}, nil)
+	suite.userRepo.On("FindByID", "2").Return(&suite.testMerchant, nil)
+	suite.ledgerSvc.On("CaptureHold", "1", "2", idr("300"), "Capture of authorization 10").Return(&models.JournalEntry{ID: "8"}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.CaptureActivity && t.OriginalTransactionID == "10" && t.Amount == idr("300")
+	})).Return(&models.Transaction{ID: "12", ActivityType: models.CaptureActivity, Amount: idr("300"), OriginalTransactionID: "10"}, nil)
+
+	capture, err := suite.transactionSvc.CaptureAuthorization("10", "2", request.CaptureRequest{Amount: idr("300")})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), idr("400"), capture.CapturedAmount)
+	assert.Equal(suite.T(), idr("100"), capture.HeldAmount)
+	assert.Equal(suite.T(), models.AuthorizationOpen, capture.Status)
+	suite.ledgerSvc.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestCaptureExceedsHeldAmount() {
+	authorization := suite.authorization("500", time.Now().Add(time.Hour))
+	suite.transactionRepo.On("FindByID", "10").Return(&authorization, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{
+		authorization,
+		{ID: "11", ActivityType: models.CaptureActivity, Amount: idr("400"), OriginalTransactionID: "10"},
+	}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedCapture && t.OriginalTransactionID == "10"
+	})).Return(&models.Transaction{ID: "12"}, nil)
+
+	capture, err := suite.transactionSvc.CaptureAuthorization("10", "2", request.CaptureRequest{Amount: idr("200")})
+
+	assert.ErrorIs(suite.T(), err, services.ErrCaptureExceedsHold)
+	assert.Nil(suite.T(), capture)
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "CaptureHold", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TransactionServiceTestSuite) TestCaptureByOtherMerchant() {
+	authorization := suite.authorization("500", time.Now().Add(time.Hour))
+	suite.transactionRepo.On("FindByID", "10").Return(&authorization, nil)
+
+	capture, err := suite.transactionSvc.CaptureAuthorization("10", "3", request.CaptureRequest{})
+
+	assert.ErrorIs(suite.T(), err, services.ErrCaptureNotAllowed)
+	assert.Nil(suite.T(), capture)
+}
+
+func (suite *TransactionServiceTestSuite) TestCaptureExpiredAuthorizationReleasesHold() {
+	authorization := suite.authorization("500", time.Now().Add(-time.Minute))
+	suite.transactionRepo.On("FindByID", "10").Return(&authorization, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{authorization}, nil)
+	suite.ledgerSvc.On("ReleaseHold", "1", idr("500"), "", "Authorization 10 expired").Return(&models.JournalEntry{ID: "9"}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.ExpiryActivity && t.Amount == idr("500")
+	})).Return(&models.Transaction{ID: "11"}, nil).Once()
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedCapture && t.FailureReason == models.FailureAuthorizationExpired
+	})).Return(&models.Transaction{ID: "12"}, nil).Once()
+	suite.transactionRepo.On("UpdateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ID == "10" && t.Status == models.TransactionReversed
+	})).Return(nil).Once()
+
+	capture, err := suite.transactionSvc.CaptureAuthorization("10", "2", request.CaptureRequest{})
+
+	assert.ErrorIs(suite.T(), err, services.ErrAuthorizationExpired)
+	assert.Nil(suite.T(), capture)
+	suite.ledgerSvc.AssertExpectations(suite.T())
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestVoidReleasesRemainingHold() {
+	authorization := suite.authorization("500", time.Now().Add(time.Hour))
+	suite.transactionRepo.On("FindByID", "10").Return(&authorization, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{
+		authorization,
+		{ID: "11", ActivityType: models.CaptureActivity, Amount: idr("200"), OriginalTransactionID: "10"},
+	}, nil)
+	suite.ledgerSvc.On("ReleaseHold", "1", idr("300"), "", "Void of authorization 10: Guest checked out early").Return(&models.JournalEntry{ID: "9"}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.VoidActivity && t.Amount == idr("300") && t.OriginalTransactionID == "10"
+	})).Return(&models.Transaction{ID: "12", ActivityType: models.VoidActivity, Amount: idr("300")}, nil)
+	// Sebagian sudah di-capture, jadi otorisasinya SUCCEEDED, bukan REVERSED
+	suite.transactionRepo.On("UpdateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ID == "10" && t.Status == models.TransactionSucceeded
+	})).Return(nil)
+
+	void, err := suite.transactionSvc.VoidAuthorization("10", "2", request.VoidRequest{Reason: "Guest checked out early"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), idr("200"), void.CapturedAmount)
+	assert.True(suite.T(), void.HeldAmount.IsZero())
+	assert.Equal(suite.T(), models.AuthorizationVoided, void.Status)
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestVoidClosedAuthorization() {
+	authorization := suite.authorization("500", time.Now().Add(time.Hour))
+	suite.transactionRepo.On("FindByID", "10").Return(&authorization, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{
+		authorization,
+		{ID: "11", ActivityType: models.CaptureActivity, Amount: idr("500"), OriginalTransactionID: "10"},
+	}, nil)
+
+	void, err := suite.transactionSvc.VoidAuthorization("10", "2", request.VoidRequest{})
+
+	assert.ErrorIs(suite.T(), err, services.ErrAuthorizationClosed)
+	assert.Nil(suite.T(), void)
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "ReleaseHold", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TransactionServiceTestSuite) TestExpireAuthorizationsReleasesOnlyOpenHolds() {
+	expired := suite.authorization("500", time.Now().Add(-time.Minute))
+	voided := suite.authorization("200", time.Now().Add(-time.Minute))
+	voided.ID = "20"
+	current := suite.authorization("300", time.Now().Add(time.Hour))
+	current.ID = "30"
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{
+		expired,
+		voided,
+		current,
+		{ID: "11", ActivityType: models.CaptureActivity, Amount: idr("100"), OriginalTransactionID: "10"},
+		{ID: "21", ActivityType: models.VoidActivity, Amount: idr("200"), OriginalTransactionID: "20"},
+	}, nil)
+	suite.ledgerSvc.On("ReleaseHold", "1", idr("400"), "", "Authorization 10 expired").Return(&models.JournalEntry{ID: "9"}, nil).Once()
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.ExpiryActivity && t.OriginalTransactionID == "10" && t.Amount == idr("400")
+	})).Return(&models.Transaction{ID: "40"}, nil).Once()
+	suite.transactionRepo.On("UpdateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ID == "10" && t.Status == models.TransactionSucceeded
+	})).Return(nil).Once()
+
+	err := suite.transactionSvc.ExpireAuthorizations()
+
+	assert.NoError(suite.T(), err)
+	suite.ledgerSvc.AssertExpectations(suite.T())
+	suite.ledgerSvc.AssertNumberOfCalls(suite.T(), "ReleaseHold", 1)
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestExpireAuthorizationsContinuesAfterFailure() {
+	failing := suite.authorization("500", time.Now().Add(-time.Minute))
+	next := suite.authorization("300", time.Now().Add(-time.Minute))
+	next.ID = "20"
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{failing, next}, nil)
+	suite.ledgerSvc.On("ReleaseHold", "1", idr("500"), "", "Authorization 10 expired").Return(nil, errors.New("disk full"))
+	suite.ledgerSvc.On("ReleaseHold", "1", idr("300"), "", "Authorization 20 expired").Return(&models.JournalEntry{ID: "9"}, nil).Once()
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.ExpiryActivity && t.OriginalTransactionID == "20"
+	})).Return(&models.Transaction{ID: "40"}, nil).Once()
+	suite.transactionRepo.On("UpdateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ID == "20" && t.Status == models.TransactionReversed
+	})).Return(nil).Once()
+
+	err := suite.transactionSvc.ExpireAuthorizations()
+
+	assert.ErrorContains(suite.T(), err, "authorization 10")
+	suite.ledgerSvc.AssertExpectations(suite.T())
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestRefundCapturedAuthorization() {
+	capture := models.Transaction{ID: "11", CustomerID: "1", MerchantID: "2", ActivityType: models.CaptureActivity, Amount: idr("300"), OriginalTransactionID: "10", Status: models.TransactionSucceeded}
+	suite.transactionRepo.On("FindByID", "11").Return(&capture, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{capture}, nil)
+	suite.ledgerSvc.On("Transfer", "2", "1", idr("300"), "Refund of transaction 11").Return(&models.JournalEntry{ID: "9"}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.RefundActivity && t.OriginalTransactionID == "11"
+	})).Return(&models.Transaction{ID: "12", ActivityType: models.RefundActivity, Amount: idr("300"), OriginalTransactionID: "11"}, nil)
+	suite.transactionRepo.On("UpdateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ID == "11" && t.Status == models.TransactionRefunded
+	})).Return(nil)
+
+	refund, err := suite.transactionSvc.RefundPayment("11", "2", request.RefundRequest{})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), idr("300"), refund.RefundedAmount)
+}
+
+func (suite *TransactionServiceTestSuite) TestBackfillStatuses() {
+	openAuthorization := suite.authorization("500", time.Now().Add(time.Hour))
+	openAuthorization.Status = ""
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{
+		{ID: "1", ActivityType: models.PaymentActivity, Amount: idr("100")},
+		{ID: "2", ActivityType: models.RefundActivity, Amount: idr("40"), OriginalTransactionID: "1"},
+		{ID: "3", ActivityType: models.FailedPayment, Details: "Merchant account is closed"},
+		{ID: "4", ActivityType: models.FailedLogin, Details: "Something unexpected"},
+		{ID: "5", ActivityType: models.PaymentActivity, Amount: idr("100"), Status: models.TransactionRefunded},
+		openAuthorization,
+	}, nil)
+	expected := map[string]models.TransactionStatus{
+		"1":  models.TransactionPartiallyRefunded,
+		"2":  models.TransactionSucceeded,
+		"3":  models.TransactionFailed,
+		"4":  models.TransactionFailed,
+		"10": models.TransactionPending,
+	}
+	reasons := map[string]models.FailureReason{"3": models.FailureMerchantAccountClosed, "4": models.FailureUnknown}
+	suite.transactionRepo.On("UpdateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return expected[t.ID] == t.Status && reasons[t.ID] == t.FailureReason && t.StatusHistory[0].Status == models.TransactionPending
+	})).Return(nil)
+
+	err := suite.transactionSvc.BackfillStatuses()
+
+	assert.NoError(suite.T(), err)
+	suite.transactionRepo.AssertNumberOfCalls(suite.T(), "UpdateTransaction", 5)
+}
+
+func (suite *TransactionServiceTestSuite) bankTransferRequest(amount string) request.BankTransferRequest {
+	return request.BankTransferRequest{
+		Amount:      idr(amount),
+		BankAccount: request.SettlementAccountRequest{BankCode: "BCA", AccountNumber: "1234567890", AccountHolder: "Test User"},
+	}
+}
+
+func (suite *TransactionServiceTestSuite) TestDepositStaysPendingUntilBankConfirms() {
+	pending := models.Transaction{ID: "20", CustomerID: "1", ActivityType: models.DepositActivity, Amount: idr("500"), Status: models.TransactionPending}
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.DepositActivity && t.Status == models.TransactionPending &&
+			t.BankAccount != nil && t.BankAccount.AccountNumber == "1234567890"
+	})).Return(&pending, nil)
+	suite.bankRail.On("Submit", mock.MatchedBy(func(i bankrails.Instruction) bool {
+		return i.ID == "20" && i.Direction == bankrails.Deposit && i.Amount == idr("500")
+	})).Return(nil, nil)
+	suite.transactionRepo.On("FindByID", "20").Return(&pending, nil)
+
+	deposit, err := suite.transactionSvc.Deposit("1", suite.bankTransferRequest("500"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.TransactionPending, deposit.Status)
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "Deposit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.bankRail.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestDepositCreditsWalletWhenBankConfirms() {
+	pending := models.Transaction{ID: "20", CustomerID: "1", ActivityType: models.DepositActivity, Amount: idr("500"), Status: models.TransactionPending}
+	pending.Begin(time.Now())
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.Anything).Return(&pending, nil)
+	suite.bankRail.On("Submit", mock.Anything).Return(nil, &bankrails.Confirmation{InstructionID: "20", Reference: "REF-1", Succeeded: true, ConfirmedAt: time.Now()})
+	suite.transactionRepo.On("FindByID", "20").Return(&pending, nil)
+	suite.ledgerSvc.On("EntryByReference", mock.Anything).Return(nil, errors.New("journal entry not found"))
+	suite.ledgerSvc.On("Deposit", "1", idr("500"), "20", "Deposit 20 received from bank").Return(&models.JournalEntry{ID: "9"}, nil)
+	suite.transactionRepo.On("UpdateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ID == "20" && t.Status == models.TransactionSucceeded && t.BankReference == "REF-1" && t.JournalEntryID == "9"
+	})).Return(nil)
+
+	deposit, err := suite.transactionSvc.Deposit("1", suite.bankTransferRequest("500"))
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.TransactionSucceeded, deposit.Status)
+	suite.ledgerSvc.AssertExpectations(suite.T())
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestResubmittedDepositKeepsLedgerOutcome() {
+	// Deposit sudah diposting ke ledger tetapi update statusnya gagal, lalu bank menolak pengiriman ulangnya
+	pending := models.Transaction{ID: "20", CustomerID: "1", ActivityType: models.DepositActivity, Amount: idr("500")}
+	pending.Begin(time.Now())
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{pending}, nil)
+	suite.bankRail.On("Submit", mock.Anything).Return(nil, &bankrails.Confirmation{InstructionID: "20", Reference: "REF-3", Reason: "timeout"})
+	suite.transactionRepo.On("FindByID", "20").Return(&pending, nil)
+	suite.ledgerSvc.On("EntryByReference", "20").Return(&models.JournalEntry{ID: "9", Reference: "20"}, nil)
+	suite.ledgerSvc.On("Deposit", "1", idr("500"), "20", "Deposit 20 received from bank").Return(&models.JournalEntry{ID: "9", Reference: "20"}, nil).Once()
+	suite.transactionRepo.On("UpdateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ID == "20" && t.Status == models.TransactionSucceeded && t.JournalEntryID == "9"
+	})).Return(nil)
+
+	err := suite.transactionSvc.ResumeBankTransfers()
+
+	assert.NoError(suite.T(), err)
+	suite.ledgerSvc.AssertExpectations(suite.T())
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestResubmittedWithdrawalIsNotPaidOutAfterRelease() {
+	pending := models.Transaction{ID: "21", CustomerID: "1", ActivityType: models.WithdrawalActivity, Amount: idr("300")}
+	pending.Begin(time.Now())
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{pending}, nil)
+	suite.bankRail.On("Submit", mock.Anything).Return(nil, &bankrails.Confirmation{InstructionID: "21", Reference: "REF-4", Succeeded: true, ConfirmedAt: time.Now()})
+	suite.transactionRepo.On("FindByID", "21").Return(&pending, nil)
+	suite.ledgerSvc.On("EntryByReference", "21").Return(nil, errors.New("journal entry not found"))
+	suite.ledgerSvc.On("EntryByReference", "21:returned").Return(&models.JournalEntry{ID: "8", Reference: "21:returned"}, nil)
+	suite.ledgerSvc.On("ReleaseHold", "1", idr("300"), "21:returned", "Withdrawal 21 returned").Return(&models.JournalEntry{ID: "8", Reference: "21:returned"}, nil)
+	suite.transactionRepo.On("UpdateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedWithdrawal && t.Status == models.TransactionFailed
+	})).Return(nil)
+
+	err := suite.transactionSvc.ResumeBankTransfers()
+
+	assert.NoError(suite.T(), err)
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "PayoutHold", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestWithdrawReleasesHoldWhenTransactionIsNotSaved() {
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.ledgerSvc.On("Hold", "1", idr("300"), mock.Anything).Return(&models.JournalEntry{ID: "7"}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.Anything).Return(nil, errors.New("disk full"))
+	suite.ledgerSvc.On("ReleaseHold", "1", idr("300"), "7:released", "Withdrawal hold 7 released").Return(&models.JournalEntry{ID: "8"}, nil)
+
+	withdrawal, err := suite.transactionSvc.Withdraw("1", suite.bankTransferRequest("300"))
+
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), withdrawal)
+	suite.ledgerSvc.AssertExpectations(suite.T())
+	suite.bankRail.AssertNotCalled(suite.T(), "Submit", mock.Anything)
+}
+
+func (suite *TransactionServiceTestSuite) TestWithdrawInsufficientBalance() {
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.ledgerSvc.On("Hold", "1", idr("5000"), mock.Anything).Return(nil, services.ErrInsufficientBalance)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedWithdrawal && t.FailureReason == models.FailureInsufficientBalance
+	})).Return(&models.Transaction{ID: "21"}, nil)
+
+	withdrawal, err := suite.transactionSvc.Withdraw("1", suite.bankTransferRequest("5000"))
+
+	assert.ErrorIs(suite.T(), err, services.ErrInsufficientBalance)
+	assert.Nil(suite.T(), withdrawal)
+	suite.bankRail.AssertNotCalled(suite.T(), "Submit", mock.Anything)
+}
+
+func (suite *TransactionServiceTestSuite) TestWithdrawRejectedByBankReleasesHold() {
+	pending := models.Transaction{ID: "21", CustomerID: "1", ActivityType: models.WithdrawalActivity, Amount: idr("300")}
+	pending.Begin(time.Now())
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.ledgerSvc.On("Hold", "1", idr("300"), "Withdrawal to BCA 1234567890").Return(&models.JournalEntry{ID: "7"}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.WithdrawalActivity && t.JournalEntryID == "7"
+	})).Return(&pending, nil)
+	suite.bankRail.On("Submit", mock.Anything).Return(nil, &bankrails.Confirmation{InstructionID: "21", Reference: "REF-2", Reason: "account is closed"})
+	suite.transactionRepo.On("FindByID", "21").Return(&pending, nil)
+	suite.ledgerSvc.On("EntryByReference", mock.Anything).Return(nil, errors.New("journal entry not found"))
+	suite.ledgerSvc.On("ReleaseHold", "1", idr("300"), "21:returned", "Withdrawal 21 returned").Return(&models.JournalEntry{ID: "8"}, nil)
+	suite.transactionRepo.On("UpdateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedWithdrawal && t.Status == models.TransactionFailed && t.FailureReason == models.FailureBankRejected
+	})).Return(nil)
+
+	_, err := suite.transactionSvc.Withdraw("1", suite.bankTransferRequest("300"))
+
+	assert.NoError(suite.T(), err)
+	suite.ledgerSvc.AssertExpectations(suite.T())
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "PayoutHold", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestResumeBankTransfersResubmitsPendingOnly() {
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{
+		{ID: "20", ActivityType: models.DepositActivity, Amount: idr("500"), Status: models.TransactionPending},
+		{ID: "21", ActivityType: models.WithdrawalActivity, Amount: idr("300"), Status: models.TransactionSucceeded},
+		suite.testTransaction,
+	}, nil)
+	suite.bankRail.On("Submit", mock.MatchedBy(func(i bankrails.Instruction) bool { return i.ID == "20" })).Return(nil, nil).Once()
+
+	err := suite.transactionSvc.ResumeBankTransfers()
+
+	assert.NoError(suite.T(), err)
+	suite.bankRail.AssertNumberOfCalls(suite.T(), "Submit", 1)
+}
+
+func (suite *TransactionServiceTestSuite) recipient() models.User {
+	return models.User{ID: "3", Username: "friend01", Email: "friend@example.com", Balance: idr("0"), Status: models.AccountActive}
+}
+
+// expectUserRoles memberi user 1 dan 3 role customer, user 2 role merchant, dan user 4 role admin
+func (suite *TransactionServiceTestSuite) expectUserRoles() {
+	for userID, roleID := range map[string]string{"1": "2", "2": "1", "3": "2", "4": "3"} {
+		suite.roleRepo.On("FindRoleByUserID", userID).Return(&[]models.UserRole{{UserID: userID, RoleID: roleID}}, nil).Maybe()
+	}
+	suite.roleRepo.On("FindByRoleID", "1").Return(&models.Role{ID: "1", Name: "merchant"}, nil).Maybe()
+	suite.roleRepo.On("FindByRoleID", "2").Return(&models.Role{ID: "2", Name: "customer"}, nil).Maybe()
+	suite.roleRepo.On("FindByRoleID", "3").Return(&models.Role{ID: "3", Name: "admin"}, nil).Maybe()
+}
+
+func (suite *TransactionServiceTestSuite) TestTransferByUsername() {
+	suite.expectUserRoles()
+	recipient := suite.recipient()
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.userRepo.On("FindByUsername", "friend01").Return(&recipient, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{}, nil)
+	suite.ledgerSvc.On("Transfer", "1", "3", idr("400"), "Transfer to friend01").Return(&models.JournalEntry{ID: "7"}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.TransferActivity && t.CustomerID == "1" && t.RecipientID == "3" &&
+			t.Note == "Dinner" && t.JournalEntryID == "7" && t.Status == models.TransactionSucceeded &&
+			t.LimitAmount != nil && *t.LimitAmount == idr("400")
+	})).Return(&models.Transaction{ID: "30", CustomerID: "1", RecipientID: "3", ActivityType: models.TransferActivity, Amount: idr("400"), Note: "Dinner", Status: models.TransactionSucceeded}, nil)
+
+	transfer, err := suite.transactionSvc.Transfer("1", request.TransferRequest{Recipient: "friend01", Amount: idr("400"), Note: "Dinner"})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "3", transfer.RecipientID)
+	assert.Equal(suite.T(), "friend01", transfer.RecipientUsername)
+	suite.ledgerSvc.AssertExpectations(suite.T())
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestTransferToSelf() {
+	suite.expectUserRoles()
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.userRepo.On("FindByUsername", "testuser").Return(&suite.testUser, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedTransfer && t.FailureReason == models.FailureSameParty
+	})).Return(&models.Transaction{ID: "31"}, nil)
+
+	_, err := suite.transactionSvc.Transfer("1", request.TransferRequest{Recipient: "testuser", Amount: idr("400")})
+
+	assert.ErrorIs(suite.T(), err, services.ErrTransferToSelf)
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TransactionServiceTestSuite) TestTransferExceedsDailyLimit() {
+	suite.expectUserRoles()
+	recipient := suite.recipient()
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.userRepo.On("FindByUsername", "3").Return(nil, errors.New("user not found"))
+	suite.userRepo.On("FindByID", "3").Return(&recipient, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{
+		{ID: "28", CustomerID: "1", RecipientID: "3", ActivityType: models.TransferActivity, Amount: idr("1000"), Timestamp: time.Now().Add(-time.Hour)},
+		{ID: "29", CustomerID: "1", RecipientID: "3", ActivityType: models.TransferActivity, Amount: idr("1000"), Timestamp: time.Now().Add(-25 * time.Hour)},
+		{ID: "27", CustomerID: "1", RecipientID: "3", ActivityType: models.FailedTransfer, Amount: idr("1000"), Timestamp: time.Now()},
+	}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedTransfer && t.FailureReason == models.FailureDailyLimitExceeded
+	})).Return(&models.Transaction{ID: "31"}, nil)
+
+	_, err := suite.transactionSvc.Transfer("1", request.TransferRequest{Recipient: "3", Amount: idr("600")})
+
+	assert.ErrorIs(suite.T(), err, services.ErrDailyTransferLimitExceeded)
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestTransferDailyLimitUsesStoredAmounts() {
+	// Kurs USD untuk waktu transfer lama sudah tidak tersedia; nilai tersimpannya yang dipakai
+	suite.expectUserRoles()
+	recipient := suite.recipient()
+	stored := idr("1000")
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.userRepo.On("FindByEmail", "friend@example.com").Return(&recipient, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{
+		{ID: "28", CustomerID: "1", RecipientID: "3", ActivityType: models.TransferActivity, Amount: models.MustParseMoney("0.06", "USD"), LimitAmount: &stored, Timestamp: time.Now().Add(-2 * time.Hour)},
+	}, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedTransfer && t.FailureReason == models.FailureDailyLimitExceeded
+	})).Return(&models.Transaction{ID: "31"}, nil)
+
+	_, err := suite.transactionSvc.Transfer("1", request.TransferRequest{Recipient: "friend@example.com", Amount: idr("600")})
+
+	assert.ErrorIs(suite.T(), err, services.ErrDailyTransferLimitExceeded)
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestTransferUsesPerUserLimit() {
+	suite.expectUserRoles()
+	recipient := suite.recipient()
+	suite.testUser.TransferLimits = &models.TransferLimits{PerTransfer: idr("200"), Daily: idr("1000")}
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.userRepo.On("FindByEmail", "friend@example.com").Return(&recipient, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedTransfer && t.FailureReason == models.FailureTransferLimitExceeded && t.RecipientID == "3"
+	})).Return(&models.Transaction{ID: "31"}, nil)
+
+	_, err := suite.transactionSvc.Transfer("1", request.TransferRequest{Recipient: "friend@example.com", Amount: idr("400")})
+
+	assert.ErrorIs(suite.T(), err, services.ErrTransferLimitExceeded)
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestFindRecipientMasksEmail() {
+	suite.expectUserRoles()
+	recipient := suite.recipient()
+	suite.userRepo.On("FindByEmail", "friend@example.com").Return(&recipient, nil)
+
+	found, err := suite.transactionSvc.FindRecipient("1", " friend@example.com ")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "friend01", found.Username)
+	assert.Equal(suite.T(), "f***@example.com", found.Email)
+}
+
+func (suite *TransactionServiceTestSuite) TestFindRecipientSuspended() {
+	suite.expectUserRoles()
+	recipient := suite.recipient()
+	recipient.Status = models.AccountSuspended
+	suite.userRepo.On("FindByUsername", "friend01").Return(&recipient, nil)
+
+	_, err := suite.transactionSvc.FindRecipient("1", "friend01")
+
+	assert.ErrorIs(suite.T(), err, services.ErrRecipientNotActive)
+}
+
+func (suite *TransactionServiceTestSuite) TestTransferToMerchantOrAdminIsRejected() {
+	suite.expectUserRoles()
+	merchant := models.User{ID: "2", Username: "merchant01", Status: models.AccountActive}
+	admin := models.User{ID: "4", Username: "admin01", Status: models.AccountActive}
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.userRepo.On("FindByUsername", "merchant01").Return(&merchant, nil)
+	suite.userRepo.On("FindByUsername", "admin01").Return(&admin, nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedTransfer && t.FailureReason == models.FailureInvalidRecipient && t.RecipientID == ""
+	})).Return(&models.Transaction{ID: "31"}, nil).Twice()
+
+	for _, recipient := range []string{"merchant01", "admin01"} {
+		_, err := suite.transactionSvc.Transfer("1", request.TransferRequest{Recipient: recipient, Amount: idr("400")})
+
+		assert.ErrorIs(suite.T(), err, services.ErrRecipientNotFound, recipient)
+	}
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TransactionServiceTestSuite) TestFindRecipientHidesMerchantsAndAdmins() {
+	suite.expectUserRoles()
+	merchant := models.User{ID: "2", Username: "merchant01", Status: models.AccountActive}
+	admin := models.User{ID: "4", Username: "admin01", Status: models.AccountActive}
+	suite.userRepo.On("FindByUsername", "merchant01").Return(&merchant, nil)
+	suite.userRepo.On("FindByUsername", "admin01").Return(&admin, nil)
+
+	for _, query := range []string{"merchant01", "admin01"} {
+		found, err := suite.transactionSvc.FindRecipient("1", query)
+
+		assert.ErrorIs(suite.T(), err, services.ErrRecipientNotFound, query)
+		assert.Nil(suite.T(), found)
+	}
+}
+
+func (suite *TransactionServiceTestSuite) TestTransactionHistoryIncludesReceivedTransfers() {
+	recipient := suite.recipient()
+	suite.userRepo.On("FindByID", "3").Return(&recipient, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{
+		suite.testTransaction,
+		{ID: "30", CustomerID: "1", RecipientID: "3", ActivityType: models.TransferActivity, Amount: idr("400")},
+		{ID: "31", CustomerID: "1", RecipientID: "3", ActivityType: models.FailedTransfer, Amount: idr("4000")},
+	}, nil)
+
+	response, err := suite.transactionSvc.TransactionHistoryByUserID(customerViewer("3"), "3")
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), response[0].Transactions, 1)
+	assert.Equal(suite.T(), "30", response[0].Transactions[0].ID)
+}
+
+func (suite *TransactionServiceTestSuite) TestTransferLimitsShowsRemaining() {
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.transactionRepo.On("FindAllTransaction").Return([]models.Transaction{
+		{ID: "28", CustomerID: "1", RecipientID: "3", ActivityType: models.TransferActivity, Amount: idr("600"), Timestamp: time.Now()},
+	}, nil)
+
+	limits, err := suite.transactionSvc.TransferLimits("1")
+
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), limits.Custom)
+	assert.Equal(suite.T(), idr("600"), *limits.DailyUsed)
+	assert.Equal(suite.T(), idr("900"), *limits.DailyRemaining)
+}
+
 func TestTransactionServiceSuite(t *testing.T) {
 	suite.Run(t, new(TransactionServiceTestSuite))
 }
@@ -0,0 +1,163 @@
+package services_test
+
+import (
+	"errors"
+	"go-json/internal/models"
+	"go-json/internal/security"
+	"go-json/internal/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRepository) FindByUserID(userID string) (*models.TwoFactor, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TwoFactor), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) Save(twoFactor models.TwoFactor) error {
+	args := m.Called(twoFactor)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) Delete(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+type TwoFactorServiceTestSuite struct {
+	suite.Suite
+	twoFactorRepo *MockTwoFactorRepository
+	userRepo      *MockUserRepository
+	roleRepo      *MockRoleRepository
+	twoFactorSvc  services.TwoFactorService
+	secret        string
+}
+
+func (suite *TwoFactorServiceTestSuite) SetupTest() {
+	suite.twoFactorRepo = new(MockTwoFactorRepository)
+	suite.userRepo = new(MockUserRepository)
+	suite.roleRepo = new(MockRoleRepository)
+	suite.twoFactorSvc = services.NewTwoFactorService(suite.twoFactorRepo, suite.userRepo, suite.roleRepo)
+
+	secret, err := security.GenerateTOTPSecret()
+	suite.Require().NoError(err)
+	suite.secret = secret
+}
+
+func (suite *TwoFactorServiceTestSuite) currentCode() string {
+	code, err := security.TOTPCode(suite.secret, security.TOTPStep(time.Now()))
+	suite.Require().NoError(err)
+	return code
+}
+
+func (suite *TwoFactorServiceTestSuite) TestEnrollStoresHashedRecoveryCodes() {
+	suite.userRepo.On("FindByID", "8").Return(&models.User{ID: "8", Username: "merchant"}, nil)
+	suite.twoFactorRepo.On("FindByUserID", "8").Return(nil, errors.New("two-factor authentication not set up"))
+	var saved models.TwoFactor
+	suite.twoFactorRepo.On("Save", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(models.TwoFactor)
+	}).Return(nil)
+
+	response, err := suite.twoFactorSvc.Enroll("8")
+
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), response.OTPAuthURI, "otpauth://totp/Merchant-Bank:merchant?")
+	assert.Len(suite.T(), response.RecoveryCodes, 10)
+	assert.False(suite.T(), saved.Enabled)
+	assert.Equal(suite.T(), response.Secret, saved.Secret)
+	assert.Len(suite.T(), saved.RecoveryCodeHashes, 10)
+	for i, code := range response.RecoveryCodes {
+		assert.NotContains(suite.T(), saved.RecoveryCodeHashes, code)
+		assert.Equal(suite.T(), security.HashRecoveryCode(code), saved.RecoveryCodeHashes[i])
+	}
+}
+
+func (suite *TwoFactorServiceTestSuite) TestEnrollWhenAlreadyEnabled() {
+	suite.userRepo.On("FindByID", "8").Return(&models.User{ID: "8"}, nil)
+	suite.twoFactorRepo.On("FindByUserID", "8").Return(&models.TwoFactor{UserID: "8", Enabled: true}, nil)
+
+	response, err := suite.twoFactorSvc.Enroll("8")
+
+	assert.ErrorIs(suite.T(), err, services.ErrTwoFactorAlreadyEnabled)
+	assert.Nil(suite.T(), response)
+	suite.twoFactorRepo.AssertNotCalled(suite.T(), "Save", mock.Anything)
+}
+
+func (suite *TwoFactorServiceTestSuite) TestConfirm() {
+	suite.twoFactorRepo.On("FindByUserID", "8").Return(&models.TwoFactor{UserID: "8", Secret: suite.secret}, nil)
+	suite.twoFactorRepo.On("Save", mock.MatchedBy(func(t models.TwoFactor) bool {
+		return t.Enabled && t.EnabledAt != nil && t.LastUsedStep > 0
+	})).Return(nil)
+
+	err := suite.twoFactorSvc.Confirm("8", suite.currentCode())
+
+	assert.NoError(suite.T(), err)
+	suite.twoFactorRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TwoFactorServiceTestSuite) TestConfirmWrongCode() {
+	suite.twoFactorRepo.On("FindByUserID", "8").Return(&models.TwoFactor{UserID: "8", Secret: suite.secret}, nil)
+
+	err := suite.twoFactorSvc.Confirm("8", "abcdef")
+
+	assert.ErrorIs(suite.T(), err, services.ErrInvalidTwoFactorCode)
+	suite.twoFactorRepo.AssertNotCalled(suite.T(), "Save", mock.Anything)
+}
+
+func (suite *TwoFactorServiceTestSuite) TestVerifyCodeConsumesRecoveryCode() {
+	recoveryCode, err := security.GenerateRecoveryCode()
+	suite.Require().NoError(err)
+	otherHash := security.HashRecoveryCode("AAAAA-BBBBB")
+	suite.twoFactorRepo.On("FindByUserID", "8").Return(&models.TwoFactor{
+		UserID:             "8",
+		Secret:             suite.secret,
+		Enabled:            true,
+		RecoveryCodeHashes: []string{otherHash, security.HashRecoveryCode(recoveryCode)},
+	}, nil)
+	suite.twoFactorRepo.On("Save", mock.MatchedBy(func(t models.TwoFactor) bool {
+		return len(t.RecoveryCodeHashes) == 1 && t.RecoveryCodeHashes[0] == otherHash
+	})).Return(nil)
+
+	err = suite.twoFactorSvc.VerifyCode("8", recoveryCode)
+
+	assert.NoError(suite.T(), err)
+	suite.twoFactorRepo.AssertExpectations(suite.T())
+}
+
+func (suite *TwoFactorServiceTestSuite) TestVerifyCodeRejectsReplayedTOTP() {
+	suite.twoFactorRepo.On("FindByUserID", "8").Return(&models.TwoFactor{
+		UserID:       "8",
+		Secret:       suite.secret,
+		Enabled:      true,
+		LastUsedStep: security.TOTPStep(time.Now()) + security.TOTPSkew,
+	}, nil)
+
+	err := suite.twoFactorSvc.VerifyCode("8", suite.currentCode())
+
+	assert.ErrorIs(suite.T(), err, services.ErrInvalidTwoFactorCode)
+}
+
+func (suite *TwoFactorServiceTestSuite) TestDisableWhenRoleRequiresTwoFactor() {
+	suite.roleRepo.On("FindRoleByUserID", "8").Return(&[]models.UserRole{{ID: "1", UserID: "8", RoleID: "1"}}, nil)
+	suite.roleRepo.On("FindByRoleID", "1").Return(&models.Role{ID: "1", Name: "merchant", RequireTwoFactor: true}, nil)
+
+	err := suite.twoFactorSvc.Disable("8", suite.currentCode())
+
+	assert.ErrorIs(suite.T(), err, services.ErrTwoFactorRequired)
+	suite.twoFactorRepo.AssertNotCalled(suite.T(), "Delete", mock.Anything)
+}
+
+func TestTwoFactorServiceSuite(t *testing.T) {
+	suite.Run(t, new(TwoFactorServiceTestSuite))
+}
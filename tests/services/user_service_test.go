@@ -3,14 +3,19 @@ package services_test
 import (
 	"errors"
 	"go-json/internal/dtos/request"
+	"go-json/internal/dtos/response"
 	"go-json/internal/models"
 	"go-json/internal/security"
 	"go-json/internal/services"
 	"testing"
+	"time"
 
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type MockUserRepository struct {
@@ -54,6 +59,36 @@ func (m *MockUserRepository) UpdateUser(user models.User) error {
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateLastLogin(id string, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePassword(id, hashedPassword string) error {
+	args := m.Called(id, hashedPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateStatus(id string, status models.AccountStatus, reason string, at time.Time) error {
+	args := m.Called(id, status, reason, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkEmailVerified(id string, at time.Time) error {
+	args := m.Called(id, at)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateTransferLimits(id string, limits *models.TransferLimits) error {
+	args := m.Called(id, limits)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateBalances(id string, balance models.Money, wallets, held []models.Money) error {
+	args := m.Called(id, balance, wallets, held)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) FindAll() ([]models.User, error) {
 	args := m.Called()
 	if args.Get(0) == nil {
@@ -90,12 +125,48 @@ func (m *MockRoleRepository) FindRoleByUserID(userID string) (*[]models.UserRole
 	return args.Get(0).(*[]models.UserRole), args.Error(1)
 }
 
+func (m *MockRoleRepository) AssignRoles(userID string, roleIDs []string) error {
+	args := m.Called(userID, roleIDs)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) FindAll() ([]models.Role, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) RemoveRole(userID, roleID string) error {
+	args := m.Called(userID, roleID)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) CreateRole(role models.Role) (*models.Role, error) {
+	args := m.Called(role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) UpdateRole(role models.Role) error {
+	args := m.Called(role)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) PermissionsForRoles(roleNames []string) []string {
+	args := m.Called(roleNames)
+	return args.Get(0).([]string)
+}
+
 type MockTokenService struct {
 	mock.Mock
 }
 
-func (m *MockTokenService) GenerateToken(username, email string, role ...string) (string, error) {
-	args := m.Called(username, email, role)
+func (m *MockTokenService) GenerateToken(subject security.TokenSubject) (string, error) {
+	args := m.Called(subject)
 	return args.String(0), args.Error(1)
 }
 
@@ -117,41 +188,242 @@ func (m *MockTokenService) VerifyToken(tokenString string) (*security.Claims, er
 	return args.Get(0).(*security.Claims), args.Error(1)
 }
 
+func (m *MockTokenService) GenerateRefreshToken() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTokenService) HashRefreshToken(refreshToken string) string {
+	args := m.Called(refreshToken)
+	return args.String(0)
+}
+
+func (m *MockTokenService) AccessTokenTTL() time.Duration {
+	args := m.Called()
+	return args.Get(0).(time.Duration)
+}
+
+func (m *MockTokenService) RefreshTokenTTL() time.Duration {
+	args := m.Called()
+	return args.Get(0).(time.Duration)
+}
+
+func (m *MockTokenService) GenerateChallengeToken(userID, purpose string) (string, error) {
+	args := m.Called(userID, purpose)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTokenService) VerifyChallengeToken(tokenString string) (*security.ChallengeClaims, error) {
+	args := m.Called(tokenString)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*security.ChallengeClaims), args.Error(1)
+}
+
+func (m *MockTokenService) ChallengeTokenTTL() time.Duration {
+	args := m.Called()
+	return args.Get(0).(time.Duration)
+}
+
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) CreateRefreshToken(token models.RefreshToken) (*models.RefreshToken, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) FindByTokenHash(tokenHash string) (*models.RefreshToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) Consume(tokenHash string) (*models.RefreshToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) RevokeFamily(familyID string) error {
+	args := m.Called(familyID)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeByUserID(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeOtherFamilies(userID, keepFamilyID string) error {
+	args := m.Called(userID, keepFamilyID)
+	return args.Error(0)
+}
+
+type MockRevokedTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRevokedTokenRepository) Revoke(token models.RevokedToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockRevokedTokenRepository) IsRevoked(jti, userID, sessionID string, issuedAt time.Time) bool {
+	args := m.Called(jti, userID, sessionID, issuedAt)
+	return args.Bool(0)
+}
+
+func (m *MockRevokedTokenRepository) PruneExpired() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+type MockLoginThrottle struct {
+	mock.Mock
+}
+
+func (m *MockLoginThrottle) Check(username, ip string) error {
+	args := m.Called(username, ip)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottle) Release(username, ip string) {
+	m.Called(username, ip)
+}
+
+func (m *MockLoginThrottle) Failed(username, ip string) error {
+	args := m.Called(username, ip)
+	return args.Error(0)
+}
+
+func (m *MockLoginThrottle) Succeeded(username string) error {
+	args := m.Called(username)
+	return args.Error(0)
+}
+
+type MockTwoFactorService struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorService) Enroll(userID string) (*response.TwoFactorEnrollResponse, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*response.TwoFactorEnrollResponse), args.Error(1)
+}
+
+func (m *MockTwoFactorService) Confirm(userID, code string) error {
+	args := m.Called(userID, code)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorService) Disable(userID, code string) error {
+	args := m.Called(userID, code)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorService) VerifyCode(userID, code string) error {
+	args := m.Called(userID, code)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorService) IsEnabled(userID string) bool {
+	args := m.Called(userID)
+	return args.Bool(0)
+}
+
+func (m *MockTwoFactorService) IsRequired(userID string) bool {
+	args := m.Called(userID)
+	return args.Bool(0)
+}
+
+type MockEmailVerificationService struct {
+	mock.Mock
+}
+
+func (m *MockEmailVerificationService) SendVerification(user models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationService) Resend(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationService) Verify(verify request.VerifyEmailRequest) error {
+	args := m.Called(verify)
+	return args.Error(0)
+}
+
 type UserServiceTestSuite struct {
 	suite.Suite
-	userRepo  *MockUserRepository
-	roleRepo  *MockRoleRepository
-	tokenSvc  *MockTokenService
-	userSvc   services.UserService
-	testUser  models.User
-	testRoles []models.Role
+	userRepo        *MockUserRepository
+	roleRepo        *MockRoleRepository
+	tokenSvc        *MockTokenService
+	refreshRepo     *MockRefreshTokenRepository
+	revokedRepo     *MockRevokedTokenRepository
+	transactionRepo *MockTransactionRepository
+	throttle        *MockLoginThrottle
+	twoFactor       *MockTwoFactorService
+	verification    *MockEmailVerificationService
+	ledgerSvc       *MockLedgerService
+	hasher          security.PasswordHasher
+	userSvc         services.UserService
+	testUser        models.User
+	testRoles       []models.Role
 }
 
 func (suite *UserServiceTestSuite) SetupTest() {
 	suite.userRepo = new(MockUserRepository)
 	suite.roleRepo = new(MockRoleRepository)
 	suite.tokenSvc = new(MockTokenService)
-	suite.userSvc = services.NewUserService(suite.userRepo, suite.roleRepo, suite.tokenSvc)
+	suite.refreshRepo = new(MockRefreshTokenRepository)
+	suite.revokedRepo = new(MockRevokedTokenRepository)
+	suite.transactionRepo = new(MockTransactionRepository)
+	suite.throttle = new(MockLoginThrottle)
+	suite.twoFactor = new(MockTwoFactorService)
+	suite.verification = new(MockEmailVerificationService)
+	suite.ledgerSvc = new(MockLedgerService)
+	suite.hasher = security.NewPasswordHasher(bcrypt.MinCost)
+	suite.userSvc = services.NewUserService(suite.userRepo, suite.roleRepo, suite.refreshRepo, suite.revokedRepo, suite.transactionRepo, suite.tokenSvc, suite.hasher, suite.throttle, suite.twoFactor, suite.verification, suite.ledgerSvc, models.Money{})
+	suite.throttle.On("Release", mock.Anything, mock.Anything).Maybe()
 
 	suite.testUser = models.User{
 		ID:       "1",
 		Username: "testuser",
 		Email:    "test@example.com",
 		Password: "password123",
-		Balance:  1000.0,
-		IsActive: false,
+		Balance:  idr("1000"),
 	}
 
 	suite.testRoles = []models.Role{
 		{
-			ID:        "1",
-			Name:      "merchant",
-			IsDefault: false,
+			ID:          "1",
+			Name:        "merchant",
+			IsDefault:   false,
+			Registrable: true,
+		},
+		{
+			ID:          "2",
+			Name:        "customer",
+			IsDefault:   true,
+			Registrable: true,
 		},
 		{
-			ID:        "2",
-			Name:      "customer",
-			IsDefault: true,
+			ID:   "3",
+			Name: "admin",
 		},
 	}
 }
@@ -166,7 +438,11 @@ func (suite *UserServiceTestSuite) TestCreateUser() {
 
 	suite.roleRepo.On("FindByRoleName", "customer").Return(&suite.testRoles[1], nil)
 
-	suite.userRepo.On("CreateUser", mock.AnythingOfType("models.User"), []string{"2"}).Return(&suite.testUser, nil)
+	suite.userRepo.On("CreateUser", mock.MatchedBy(func(u models.User) bool {
+		match, _ := suite.hasher.Compare(u.Password, "password123")
+		return u.Password != "password123" && suite.hasher.IsHashed(u.Password) && match
+	}), []string{"2"}).Return(&suite.testUser, nil)
+	suite.verification.On("SendVerification", suite.testUser).Return(nil)
 
 	response, err := suite.userSvc.CreateUser(registerReq)
 
@@ -178,6 +454,48 @@ func (suite *UserServiceTestSuite) TestCreateUser() {
 
 	suite.roleRepo.AssertExpectations(suite.T())
 	suite.userRepo.AssertExpectations(suite.T())
+	suite.verification.AssertExpectations(suite.T())
+	suite.ledgerSvc.AssertNotCalled(suite.T(), "FundSandbox", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestCreateUserFundsSandboxBalance() {
+	userSvc := services.NewUserService(suite.userRepo, suite.roleRepo, suite.refreshRepo, suite.revokedRepo, suite.transactionRepo, suite.tokenSvc, suite.hasher, suite.throttle, suite.twoFactor, suite.verification, suite.ledgerSvc, idr("1000000"))
+	created := suite.testUser
+	created.Balance = idr("0")
+	funded := suite.testUser
+	funded.Balance = idr("1000000")
+	suite.roleRepo.On("FindByRoleName", "customer").Return(&suite.testRoles[1], nil)
+	suite.userRepo.On("CreateUser", mock.Anything, []string{"2"}).Return(&created, nil)
+	suite.verification.On("SendVerification", created).Return(nil)
+	suite.ledgerSvc.On("FundSandbox", "1", idr("1000000")).Return(&models.JournalEntry{ID: "1"}, nil)
+	suite.userRepo.On("FindByID", "1").Return(&funded, nil)
+
+	response, err := userSvc.CreateUser(request.RegisterRequest{
+		Username: "testuser",
+		Email:    "test@example.com",
+		Password: "password123",
+		Role:     []string{"customer"},
+	})
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), idr("1000000"), response.Balance)
+	suite.ledgerSvc.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestCreateUserEnforcesPasswordPolicy() {
+	registerReq := request.RegisterRequest{
+		Username: "testuser",
+		Email:    "test@example.com",
+		Password: "onlyletters",
+		Role:     []string{"customer"},
+	}
+
+	response, err := suite.userSvc.CreateUser(registerReq)
+
+	var validationErrors validator.ValidationErrors
+	assert.True(suite.T(), errors.As(err, &validationErrors))
+	assert.Nil(suite.T(), response)
+	suite.userRepo.AssertNotCalled(suite.T(), "CreateUser", mock.Anything, mock.Anything)
 }
 
 func (suite *UserServiceTestSuite) TestCreateUserRoleError() {
@@ -199,6 +517,24 @@ func (suite *UserServiceTestSuite) TestCreateUserRoleError() {
 	suite.roleRepo.AssertExpectations(suite.T())
 }
 
+func (suite *UserServiceTestSuite) TestCreateUserCannotChooseAdmin() {
+	registerReq := request.RegisterRequest{
+		Username: "testuser",
+		Email:    "test@example.com",
+		Password: "password123",
+		Role:     []string{"customer", "admin"},
+	}
+
+	suite.roleRepo.On("FindByRoleName", "customer").Return(&suite.testRoles[1], nil)
+	suite.roleRepo.On("FindByRoleName", "admin").Return(&suite.testRoles[2], nil)
+
+	response, err := suite.userSvc.CreateUser(registerReq)
+
+	assert.ErrorIs(suite.T(), err, services.ErrRoleNotRegistrable)
+	assert.Nil(suite.T(), response)
+	suite.userRepo.AssertNotCalled(suite.T(), "CreateUser", mock.Anything, mock.Anything)
+}
+
 func (suite *UserServiceTestSuite) TestLogin() {
 	loginReq := request.LoginRequest{
 		Username: "testuser",
@@ -214,20 +550,72 @@ func (suite *UserServiceTestSuite) TestLogin() {
 	}
 
 	suite.userRepo.On("FindByUsername", "testuser").Return(&suite.testUser, nil)
-	suite.userRepo.On("UpdateUser", mock.AnythingOfType("models.User")).Return(nil)
+	suite.throttle.On("Check", "testuser", "10.0.0.1").Return(nil)
+	suite.throttle.On("Succeeded", "testuser").Return(nil)
+	suite.twoFactor.On("IsEnabled", "1").Return(false)
+	suite.twoFactor.On("IsRequired", "1").Return(false)
+	suite.userRepo.On("UpdatePassword", "1", mock.MatchedBy(suite.hasher.IsHashed)).Return(nil)
+	suite.userRepo.On("UpdateLastLogin", "1", mock.Anything).Return(nil)
 	suite.roleRepo.On("FindRoleByUserID", "1").Return(&userRoles, nil)
 	suite.roleRepo.On("FindByRoleID", "2").Return(&suite.testRoles[1], nil)
-	suite.tokenSvc.On("GenerateToken", "testuser", "test@example.com", mock.AnythingOfType("[]string")).Return("test-token", nil)
+	suite.tokenSvc.On("GenerateToken", mock.MatchedBy(func(subject security.TokenSubject) bool {
+		return subject.UserID == "1" && subject.Username == "testuser" && subject.Email == "test@example.com" && subject.SessionID == "2"
+	})).Return("test-token", nil)
+	suite.expectRefreshTokenIssued("test-refresh-token", "")
 
-	response, err := suite.userSvc.Login(loginReq)
+	response, err := suite.userSvc.Login(loginReq, "10.0.0.1")
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), response)
 	assert.Equal(suite.T(), "test-token", response.Token)
+	assert.Equal(suite.T(), "test-refresh-token", response.RefreshToken)
+	assert.Equal(suite.T(), int64(900), response.ExpiresIn)
 
 	suite.userRepo.AssertExpectations(suite.T())
 	suite.roleRepo.AssertExpectations(suite.T())
 	suite.tokenSvc.AssertExpectations(suite.T())
+	suite.refreshRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestLoginHashedPassword() {
+	loginReq := request.LoginRequest{
+		Username: "testuser",
+		Password: "password123",
+	}
+
+	hashedPassword, err := suite.hasher.Hash("password123")
+	assert.NoError(suite.T(), err)
+	hashedUser := suite.testUser
+	hashedUser.Password = hashedPassword
+
+	userRoles := []models.UserRole{
+		{
+			ID:     "1",
+			UserID: "1",
+			RoleID: "2",
+		},
+	}
+
+	suite.userRepo.On("FindByUsername", "testuser").Return(&hashedUser, nil)
+	suite.throttle.On("Check", "testuser", "10.0.0.1").Return(nil)
+	suite.throttle.On("Succeeded", "testuser").Return(nil)
+	suite.twoFactor.On("IsEnabled", "1").Return(false)
+	suite.twoFactor.On("IsRequired", "1").Return(false)
+	suite.userRepo.On("UpdateLastLogin", "1", mock.Anything).Return(nil)
+	suite.roleRepo.On("FindRoleByUserID", "1").Return(&userRoles, nil)
+	suite.roleRepo.On("FindByRoleID", "2").Return(&suite.testRoles[1], nil)
+	suite.tokenSvc.On("GenerateToken", mock.MatchedBy(func(subject security.TokenSubject) bool {
+		return subject.UserID == "1" && subject.Username == "testuser" && subject.Email == "test@example.com" && subject.SessionID == "2"
+	})).Return("test-token", nil)
+	suite.expectRefreshTokenIssued("test-refresh-token", "")
+
+	response, err := suite.userSvc.Login(loginReq, "10.0.0.1")
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), response)
+	assert.Equal(suite.T(), "test-token", response.Token)
+
+	suite.userRepo.AssertExpectations(suite.T())
 }
 
 func (suite *UserServiceTestSuite) TestLoginInvalidCredentials() {
@@ -237,26 +625,207 @@ func (suite *UserServiceTestSuite) TestLoginInvalidCredentials() {
 	}
 
 	suite.userRepo.On("FindByUsername", "testuser").Return(&suite.testUser, nil)
+	suite.throttle.On("Check", "testuser", "10.0.0.1").Return(nil)
+	suite.throttle.On("Failed", "testuser", "10.0.0.1").Return(nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedLogin && t.CustomerID == "1"
+	})).Return(&models.Transaction{}, nil)
 
-	response, err := suite.userSvc.Login(loginReq)
+	response, err := suite.userSvc.Login(loginReq, "10.0.0.1")
 
-	assert.Error(suite.T(), err)
+	assert.ErrorIs(suite.T(), err, services.ErrInvalidCredentials)
 	assert.Nil(suite.T(), response)
 	assert.Equal(suite.T(), "invalid credentials", err.Error())
 
 	suite.userRepo.AssertExpectations(suite.T())
+	suite.throttle.AssertExpectations(suite.T())
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestLoginSuspendedAccount() {
+	loginReq := request.LoginRequest{
+		Username: "testuser",
+		Password: "password123",
+	}
+	suspended := suite.testUser
+	suspended.Status = models.AccountSuspended
+
+	suite.userRepo.On("FindByUsername", "testuser").Return(&suspended, nil)
+	suite.throttle.On("Check", "testuser", "10.0.0.1").Return(nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedLogin && t.CustomerID == "1"
+	})).Return(&models.Transaction{}, nil)
+
+	response, err := suite.userSvc.Login(loginReq, "10.0.0.1")
+
+	assert.ErrorIs(suite.T(), err, services.ErrAccountSuspended)
+	assert.Nil(suite.T(), response)
+	suite.tokenSvc.AssertNotCalled(suite.T(), "GenerateToken", mock.Anything)
+	suite.throttle.AssertNotCalled(suite.T(), "Failed", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestLoginUnknownUsername() {
+	loginReq := request.LoginRequest{
+		Username: "nobody",
+		Password: "password123",
+	}
+
+	suite.userRepo.On("FindByUsername", "nobody").Return(nil, errors.New("user not found"))
+	suite.throttle.On("Check", "nobody", "10.0.0.1").Return(nil)
+	suite.throttle.On("Failed", "nobody", "10.0.0.1").Return(nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedLogin && t.CustomerID == ""
+	})).Return(&models.Transaction{}, nil)
+
+	response, err := suite.userSvc.Login(loginReq, "10.0.0.1")
+
+	assert.ErrorIs(suite.T(), err, services.ErrInvalidCredentials)
+	assert.Nil(suite.T(), response)
+	suite.throttle.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestLoginThrottledSkipsPasswordCheck() {
+	hashedPassword, err := suite.hasher.Hash("password123")
+	assert.NoError(suite.T(), err)
+	hashedUser := suite.testUser
+	hashedUser.Password = hashedPassword
+
+	loginReq := request.LoginRequest{
+		Username: "testuser",
+		Password: "password123",
+	}
+
+	suite.userRepo.On("FindByUsername", "testuser").Return(&hashedUser, nil)
+	suite.throttle.On("Check", "testuser", "10.0.0.1").Return(&services.LoginThrottledError{RetryAfter: time.Minute, Locked: true})
+
+	response, err := suite.userSvc.Login(loginReq, "10.0.0.1")
+
+	assert.ErrorIs(suite.T(), err, services.ErrLoginThrottled)
+	assert.Nil(suite.T(), response)
+	// Penolakan throttle tidak menambah FAILED_LOGIN di riwayat transaksi
+	suite.transactionRepo.AssertNotCalled(suite.T(), "CreateTransaction", mock.Anything)
+	suite.throttle.AssertNotCalled(suite.T(), "Release", mock.Anything, mock.Anything)
+	suite.throttle.AssertNotCalled(suite.T(), "Failed", mock.Anything, mock.Anything)
+	suite.throttle.AssertNotCalled(suite.T(), "Succeeded", mock.Anything)
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdatePassword", mock.Anything, mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestLoginWithTwoFactorReturnsChallenge() {
+	hashedPassword, err := suite.hasher.Hash("password123")
+	assert.NoError(suite.T(), err)
+	hashedUser := suite.testUser
+	hashedUser.Password = hashedPassword
+
+	suite.userRepo.On("FindByUsername", "testuser").Return(&hashedUser, nil)
+	suite.throttle.On("Check", "testuser", "10.0.0.1").Return(nil)
+	suite.twoFactor.On("IsEnabled", "1").Return(true)
+	suite.tokenSvc.On("GenerateChallengeToken", "1", security.ChallengeTwoFactorVerify).Return("challenge", nil)
+	suite.tokenSvc.On("ChallengeTokenTTL").Return(5 * time.Minute)
+
+	response, err := suite.userSvc.Login(request.LoginRequest{Username: "testuser", Password: "password123"}, "10.0.0.1")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "challenge", response.ChallengeToken)
+	assert.Equal(suite.T(), security.ChallengeTwoFactorVerify, response.ChallengePurpose)
+	assert.Empty(suite.T(), response.Token)
+	// Hitungan gagal baru direset setelah langkah kedua berhasil
+	suite.throttle.AssertNotCalled(suite.T(), "Succeeded", mock.Anything)
+	suite.userRepo.AssertNotCalled(suite.T(), "UpdateLastLogin", mock.Anything, mock.Anything)
+	suite.tokenSvc.AssertNotCalled(suite.T(), "GenerateToken", mock.Anything)
+}
+
+func (suite *UserServiceTestSuite) TestLoginRequiresTwoFactorSetup() {
+	hashedPassword, err := suite.hasher.Hash("password123")
+	assert.NoError(suite.T(), err)
+	hashedUser := suite.testUser
+	hashedUser.Password = hashedPassword
+
+	suite.userRepo.On("FindByUsername", "testuser").Return(&hashedUser, nil)
+	suite.throttle.On("Check", "testuser", "10.0.0.1").Return(nil)
+	suite.twoFactor.On("IsEnabled", "1").Return(false)
+	suite.twoFactor.On("IsRequired", "1").Return(true)
+	suite.tokenSvc.On("GenerateChallengeToken", "1", security.ChallengeTwoFactorSetup).Return("setup-challenge", nil)
+	suite.tokenSvc.On("ChallengeTokenTTL").Return(5 * time.Minute)
+
+	response, err := suite.userSvc.Login(request.LoginRequest{Username: "testuser", Password: "password123"}, "10.0.0.1")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), security.ChallengeTwoFactorSetup, response.ChallengePurpose)
+}
+
+func (suite *UserServiceTestSuite) TestVerifyLogin() {
+	verifyReq := request.VerifyLoginRequest{ChallengeToken: "challenge", Code: "123456"}
+	userRoles := []models.UserRole{{ID: "1", UserID: "1", RoleID: "1"}}
+
+	suite.tokenSvc.On("VerifyChallengeToken", "challenge").Return(&security.ChallengeClaims{UserID: "1", Purpose: security.ChallengeTwoFactorVerify}, nil)
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.throttle.On("Check", "testuser", "10.0.0.1").Return(nil)
+	suite.twoFactor.On("VerifyCode", "1", "123456").Return(nil)
+	suite.throttle.On("Succeeded", "testuser").Return(nil)
+	suite.userRepo.On("UpdateLastLogin", "1", mock.Anything).Return(nil)
+	suite.roleRepo.On("FindRoleByUserID", "1").Return(&userRoles, nil)
+	suite.roleRepo.On("FindByRoleID", "1").Return(&suite.testRoles[0], nil)
+	suite.tokenSvc.On("GenerateToken", mock.Anything).Return("test-token", nil)
+	suite.expectRefreshTokenIssued("test-refresh-token", "")
+
+	response, err := suite.userSvc.VerifyLogin(verifyReq, "10.0.0.1")
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "test-token", response.Token)
+	suite.twoFactor.AssertExpectations(suite.T())
+	suite.throttle.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestVerifyLoginWrongCode() {
+	verifyReq := request.VerifyLoginRequest{ChallengeToken: "challenge", Code: "000000"}
+
+	suite.tokenSvc.On("VerifyChallengeToken", "challenge").Return(&security.ChallengeClaims{UserID: "1", Purpose: security.ChallengeTwoFactorVerify}, nil)
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.throttle.On("Check", "testuser", "10.0.0.1").Return(nil)
+	suite.twoFactor.On("VerifyCode", "1", "000000").Return(services.ErrInvalidTwoFactorCode)
+	suite.throttle.On("Failed", "testuser", "10.0.0.1").Return(nil)
+	suite.transactionRepo.On("CreateTransaction", mock.MatchedBy(func(t models.Transaction) bool {
+		return t.ActivityType == models.FailedLogin && t.Details == "Invalid two-factor code from 10.0.0.1" &&
+			t.FailureReason == models.FailureInvalidTwoFactorCode
+	})).Return(&models.Transaction{}, nil)
+
+	response, err := suite.userSvc.VerifyLogin(verifyReq, "10.0.0.1")
+
+	assert.ErrorIs(suite.T(), err, services.ErrInvalidTwoFactorCode)
+	assert.Nil(suite.T(), response)
+	suite.throttle.AssertExpectations(suite.T())
+	suite.transactionRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestSetupTwoFactorRejectsVerifyChallenge() {
+	suite.tokenSvc.On("VerifyChallengeToken", "challenge").Return(&security.ChallengeClaims{UserID: "1", Purpose: security.ChallengeTwoFactorVerify}, nil)
+
+	response, err := suite.userSvc.SetupTwoFactor(request.TwoFactorChallengeRequest{ChallengeToken: "challenge"})
+
+	assert.ErrorIs(suite.T(), err, services.ErrInvalidChallenge)
+	assert.Nil(suite.T(), response)
+	suite.twoFactor.AssertNotCalled(suite.T(), "Enroll", mock.Anything)
 }
 
 func (suite *UserServiceTestSuite) TestLogout() {
 	token := "test-token"
 	claims := &security.Claims{
-		Email: "test@example.com",
-		Role:  []string{"customer"},
+		UserID:    "1",
+		Email:     "test@example.com",
+		Role:      []string{"customer"},
+		SessionID: "3",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "test-jti",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
+		},
 	}
 
 	suite.tokenSvc.On("VerifyToken", token).Return(claims, nil)
-	suite.userRepo.On("FindByEmail", "test@example.com").Return(&suite.testUser, nil)
-	suite.userRepo.On("UpdateUser", mock.AnythingOfType("models.User")).Return(nil)
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.revokedRepo.On("Revoke", mock.MatchedBy(func(t models.RevokedToken) bool {
+		return t.JTI == "test-jti" && t.UserID == "1" && t.ExpiresAt.Equal(claims.ExpiresAt.Time)
+	})).Return(nil)
+	suite.refreshRepo.On("RevokeFamily", "3").Return(nil)
 
 	response, err := suite.userSvc.Logout(token)
 	assert.NoError(suite.T(), err)
@@ -265,16 +834,114 @@ func (suite *UserServiceTestSuite) TestLogout() {
 
 	suite.tokenSvc.AssertExpectations(suite.T())
 	suite.userRepo.AssertExpectations(suite.T())
+	suite.revokedRepo.AssertExpectations(suite.T())
+	suite.refreshRepo.AssertExpectations(suite.T())
 }
 
-func (suite *UserServiceTestSuite) TestRefresh() {
+func (suite *UserServiceTestSuite) TestLogoutAll() {
 	token := "test-token"
+	claims := &security.Claims{
+		UserID: "1",
+		Email:  "test@example.com",
+		Role:   []string{"customer"},
+	}
 
-	response, err := suite.userSvc.Refresh(token)
+	suite.tokenSvc.On("VerifyToken", token).Return(claims, nil)
+	suite.tokenSvc.On("AccessTokenTTL").Return(15 * time.Minute)
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.revokedRepo.On("Revoke", mock.MatchedBy(func(t models.RevokedToken) bool {
+		return t.JTI == "" && t.UserID == "1" && t.ExpiresAt.After(t.RevokedAt)
+	})).Return(nil)
+	suite.refreshRepo.On("RevokeByUserID", "1").Return(nil)
+
+	response, err := suite.userSvc.LogoutAll(token)
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), response)
+	assert.Equal(suite.T(), "Logout from all sessions successful", response.Message)
+
+	suite.revokedRepo.AssertExpectations(suite.T())
+	suite.refreshRepo.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) expectRefreshTokenIssued(refreshToken, familyID string) {
+	stored := &models.RefreshToken{ID: "2", FamilyID: familyID}
+	if familyID == "" {
+		stored.FamilyID = stored.ID
+	}
+	suite.tokenSvc.On("GenerateRefreshToken").Return(refreshToken, nil).Once()
+	suite.tokenSvc.On("HashRefreshToken", refreshToken).Return(refreshToken + "-hash")
+	suite.tokenSvc.On("AccessTokenTTL").Return(15 * time.Minute)
+	suite.tokenSvc.On("RefreshTokenTTL").Return(24 * time.Hour)
+	suite.refreshRepo.On("CreateRefreshToken", mock.MatchedBy(func(t models.RefreshToken) bool {
+		return t.TokenHash == refreshToken+"-hash" && t.FamilyID == familyID && t.UserID == "1"
+	})).Return(stored, nil)
+}
+
+func (suite *UserServiceTestSuite) TestRefresh() {
+	stored := &models.RefreshToken{
+		ID:        "1",
+		UserID:    "1",
+		FamilyID:  "1",
+		TokenHash: "old-refresh-token-hash",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	userRoles := []models.UserRole{
+		{
+			ID:     "1",
+			UserID: "1",
+			RoleID: "2",
+		},
+	}
+
+	suite.tokenSvc.On("HashRefreshToken", "old-refresh-token").Return("old-refresh-token-hash")
+	suite.refreshRepo.On("Consume", "old-refresh-token-hash").Return(stored, nil)
+	suite.userRepo.On("FindByID", "1").Return(&suite.testUser, nil)
+	suite.roleRepo.On("FindRoleByUserID", "1").Return(&userRoles, nil)
+	suite.roleRepo.On("FindByRoleID", "2").Return(&suite.testRoles[1], nil)
+	suite.tokenSvc.On("GenerateToken", mock.MatchedBy(func(subject security.TokenSubject) bool {
+		return subject.UserID == "1" && subject.SessionID == "1"
+	})).Return("new-token", nil)
+	suite.expectRefreshTokenIssued("new-refresh-token", "1")
+
+	response, err := suite.userSvc.Refresh("old-refresh-token")
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), response)
-	assert.Equal(suite.T(), token, response.Token)
+	assert.Equal(suite.T(), "new-token", response.Token)
+	assert.Equal(suite.T(), "new-refresh-token", response.RefreshToken)
+
+	suite.refreshRepo.AssertExpectations(suite.T())
+	suite.tokenSvc.AssertExpectations(suite.T())
+}
+
+func (suite *UserServiceTestSuite) TestRefreshRejectsTokenThatCannotBeConsumed() {
+	for _, consumeErr := range []error{models.ErrRefreshTokenReused, models.ErrRefreshTokenExpired, models.ErrRefreshTokenRevoked} {
+		suite.SetupTest()
+		suite.tokenSvc.On("HashRefreshToken", "old-refresh-token").Return("old-refresh-token-hash")
+		suite.refreshRepo.On("Consume", "old-refresh-token-hash").Return(nil, consumeErr)
+
+		response, err := suite.userSvc.Refresh("old-refresh-token")
+
+		assert.ErrorIs(suite.T(), err, consumeErr)
+		assert.Nil(suite.T(), response)
+		suite.tokenSvc.AssertNotCalled(suite.T(), "GenerateToken", mock.Anything)
+	}
+}
+
+func (suite *UserServiceTestSuite) TestRefreshReuseRevokesSessionAccessTokens() {
+	reused := &models.RefreshToken{ID: "1", UserID: "1", FamilyID: "3"}
+	suite.tokenSvc.On("HashRefreshToken", "old-refresh-token").Return("old-refresh-token-hash")
+	suite.tokenSvc.On("AccessTokenTTL").Return(15 * time.Minute)
+	suite.refreshRepo.On("Consume", "old-refresh-token-hash").Return(reused, models.ErrRefreshTokenReused)
+	suite.revokedRepo.On("Revoke", mock.MatchedBy(func(t models.RevokedToken) bool {
+		return t.JTI == "" && t.UserID == "1" && t.SessionID == "3" && t.ExpiresAt.After(t.RevokedAt)
+	})).Return(nil)
+
+	response, err := suite.userSvc.Refresh("old-refresh-token")
+
+	assert.ErrorIs(suite.T(), err, models.ErrRefreshTokenReused)
+	assert.Nil(suite.T(), response)
+	suite.revokedRepo.AssertExpectations(suite.T())
 }
 
 func (suite *UserServiceTestSuite) TestFindAllUser() {
@@ -291,7 +958,7 @@ func (suite *UserServiceTestSuite) TestFindAllUser() {
 	suite.roleRepo.On("FindRoleByUserID", "1").Return(&userRoles, nil)
 	suite.roleRepo.On("FindByRoleID", "2").Return(&suite.testRoles[1], nil)
 
-	response, err := suite.userSvc.FindAllUser()
+	response, err := suite.userSvc.FindAllUser(false)
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), response)
@@ -303,6 +970,25 @@ func (suite *UserServiceTestSuite) TestFindAllUser() {
 	suite.roleRepo.AssertExpectations(suite.T())
 }
 
+func (suite *UserServiceTestSuite) TestFindAllUserHidesClosedAndToleratesMissingRoles() {
+	closed := models.User{ID: "2", Username: "closeduser", Status: models.AccountClosed}
+	suite.userRepo.On("FindAll").Return([]models.User{suite.testUser, closed}, nil)
+	suite.roleRepo.On("FindRoleByUserID", "1").Return(nil, errors.New("user has no roles"))
+	suite.roleRepo.On("FindRoleByUserID", "2").Return(nil, errors.New("user has no roles"))
+
+	response, err := suite.userSvc.FindAllUser(false)
+
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(response, 1)
+	assert.Equal(suite.T(), "1", response[0].ID)
+	assert.Empty(suite.T(), response[0].Roles)
+
+	response, err = suite.userSvc.FindAllUser(true)
+
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), response, 2)
+}
+
 func TestUserServiceSuite(t *testing.T) {
 	suite.Run(t, new(UserServiceTestSuite))
 }
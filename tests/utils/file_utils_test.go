@@ -0,0 +1,77 @@
+package utils_test
+
+import (
+	"go-json/utils"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type record struct {
+	ID string `json:"id"`
+}
+
+type FileUtilsTestSuite struct {
+	suite.Suite
+	dir  string
+	file string
+}
+
+func (suite *FileUtilsTestSuite) SetupTest() {
+	suite.dir = suite.T().TempDir()
+	suite.file = filepath.Join(suite.dir, "data", "records.json")
+}
+
+func (suite *FileUtilsTestSuite) TestWriteJSONFileKeepsBackup() {
+	err := utils.WriteJSONFile(suite.file, []record{{ID: "1"}})
+	assert.NoError(suite.T(), err)
+	assert.NoFileExists(suite.T(), suite.file+".bak")
+
+	err = utils.WriteJSONFile(suite.file, []record{{ID: "1"}, {ID: "2"}})
+	assert.NoError(suite.T(), err)
+
+	var current []record
+	assert.NoError(suite.T(), utils.ReadJSONFile(suite.file, &current))
+	assert.Len(suite.T(), current, 2)
+
+	var backup []record
+	assert.NoError(suite.T(), utils.ReadJSONFile(suite.file+".bak", &backup))
+	assert.Len(suite.T(), backup, 1)
+
+	entries, err := os.ReadDir(filepath.Dir(suite.file))
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), entries, 2)
+}
+
+func (suite *FileUtilsTestSuite) TestLoadJSONFileMissing() {
+	records := []record{}
+	err := utils.LoadJSONFile(suite.file, &records)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), records)
+}
+
+func (suite *FileUtilsTestSuite) TestLoadJSONFileCorrupt() {
+	assert.NoError(suite.T(), os.MkdirAll(filepath.Dir(suite.file), 0755))
+	assert.NoError(suite.T(), os.WriteFile(suite.file, []byte(`[{"id": "1"`), 0644))
+
+	records := []record{}
+	err := utils.LoadJSONFile(suite.file, &records)
+	assert.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "is corrupt")
+}
+
+func (suite *FileUtilsTestSuite) TestLoadJSONFileEmpty() {
+	assert.NoError(suite.T(), os.MkdirAll(filepath.Dir(suite.file), 0755))
+	assert.NoError(suite.T(), os.WriteFile(suite.file, []byte{}, 0644))
+
+	records := []record{}
+	err := utils.LoadJSONFile(suite.file, &records)
+	assert.Error(suite.T(), err)
+}
+
+func TestFileUtilsSuite(t *testing.T) {
+	suite.Run(t, new(FileUtilsTestSuite))
+}
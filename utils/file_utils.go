@@ -1,15 +1,21 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"go-json/constant"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 )
 
+const backupSuffix = ".bak"
+
 func EnsureJSONFiles() {
-	files := []string{constant.USER_FILE, constant.MERCHANT_FILE, constant.TRANSACTION_FILE}
+	files := []string{constant.USER_FILE, constant.MERCHANT_FILE, constant.TRANSACTION_FILE, constant.REFRESH_TOKEN_FILE, constant.REVOKED_TOKEN_FILE, constant.LEDGER_ACCOUNT_FILE, constant.JOURNAL_ENTRY_FILE, constant.IDEMPOTENCY_FILE, constant.FX_RATE_FILE, constant.AUDIT_FILE, constant.LOGIN_ATTEMPT_FILE, constant.TWO_FACTOR_FILE, constant.ONE_TIME_TOKEN_FILE, constant.API_KEY_FILE}
 
 	for _, filepath := range files {
 		if !fileExists(filepath) {
@@ -27,52 +33,114 @@ func fileExists(filepath string) bool {
 }
 
 func createEmptyJSONFile(filePath string) error {
-	dir := filepath.Dir(filePath)
-	err := os.MkdirAll(dir, 0755)
+	return WriteJSONFile(filePath, []map[string]interface{}{})
+}
+
+func ReadJSONFile(filepath string, v interface{}) error {
+	file, err := os.Open(filepath)
 	if err != nil {
 		return err
 	}
+	defer file.Close()
 
-	var initialData interface{}
-	switch filePath {
-	case constant.USER_FILE:
-		initialData = []map[string]interface{}{}
-	case constant.MERCHANT_FILE:
-		initialData = []map[string]interface{}{}
-	case constant.TRANSACTION_FILE:
-		initialData = []map[string]interface{}{}
-	}
+	decoder := json.NewDecoder(file)
+	return decoder.Decode(v)
+}
 
-	file, err := os.Create(filePath)
+// LoadJSONFile dipakai saat startup. File yang belum ada dianggap kosong dan v
+// dibiarkan apa adanya, tetapi file yang rusak menghasilkan error agar server
+// tidak jalan dengan data kosong.
+func LoadJSONFile(filepath string, v interface{}) error {
+	data, err := os.ReadFile(filepath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	encoder := json.NewEncoder(file)
+	if err := json.Unmarshal(data, v); err != nil {
+		if fileExists(filepath + backupSuffix) {
+			return fmt.Errorf("%s is corrupt (%v); previous version is available at %s", filepath, err, filepath+backupSuffix)
+		}
+		return fmt.Errorf("%s is corrupt: %v", filepath, err)
+	}
+	return nil
+}
+
+// WriteJSONFile menulis ke file sementara di direktori yang sama, fsync, lalu
+// rename ke file tujuan sehingga file lama tidak pernah terpotong di tengah jalan.
+// Versi sebelumnya disimpan sebagai <file>.bak.
+func WriteJSONFile(filepath string, v interface{}) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(initialData)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+
+	if fileExists(filepath) {
+		if err := copyFileAtomic(filepath, filepath+backupSuffix); err != nil {
+			return err
+		}
+	}
+
+	return writeFileAtomic(filepath, buf.Bytes())
 }
 
-func ReadJSONFile(filepath string, v interface{}) error {
-	file, err := os.Open(filepath)
+func writeFileAtomic(filePath string, data []byte) error {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filePath)+".tmp-*")
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
 
-	decoder := json.NewDecoder(file)
-	return decoder.Decode(v)
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpName, filePath); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
 }
 
-func WriteJSONFile(filepath string, v interface{}) error {
-	file, err := os.Create(filepath)
+func copyFileAtomic(src, dst string) error {
+	file, err := os.Open(src)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(v)
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(dst, data)
+}
+
+// syncDir memastikan rename tercatat di disk. Tidak semua OS (mis. Windows)
+// mendukung fsync pada direktori, jadi error diabaikan.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	_ = d.Sync()
 }